@@ -3,42 +3,51 @@ package main
 import (
 	"TODO/internal/model"
 	"context"
+	"crypto/tls"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"os"
 	"os/signal"
-	"strconv"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
 	"TODO/internal/api/v1"
+	"TODO/internal/api/v2"
+	"TODO/internal/auth"
 	"TODO/internal/cache"
 	"TODO/internal/client"
 	"TODO/internal/config"
 	"TODO/internal/dao"
+	"TODO/internal/events"
 	"TODO/internal/gateway"
+	"TODO/internal/graphql"
+	"TODO/internal/health"
+	"TODO/internal/interceptor"
 	"TODO/internal/kafka"
+	"TODO/internal/logger"
 	"TODO/internal/metrics"
+	"TODO/internal/migrate"
 	"TODO/internal/pool"
+	"TODO/internal/ratelimit"
 	"TODO/internal/server"
 	"TODO/internal/service"
+	"TODO/internal/tlsconfig"
 	"TODO/internal/tracing"
+	"TODO/internal/trash"
 	"TODO/internal/view"
+	"TODO/internal/webhook"
 )
 
 func main() {
-	err := addServersSectionToOpenAPI()
-	if err != nil {
-		log.Fatalf("Ошибка при добавлении секции servers в OpenAPI: %v\n", err)
-	}
-
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -46,54 +55,227 @@ func main() {
 
 	cfg := config.LoadConfig()
 
+	log, err := logger.New(os.Stderr, logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка настройки журнала: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(log)
+	log.Info("Конфигурация загружена", "config", cfg)
+
+	// todo migrate up|down|redo|reset|status выполняет встроенные миграции и завершает работу
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(ctx, cfg, os.Args[2:])
+		return
+	}
+
 	shutdownTracer := tracing.InitTracer(cfg.ServiceName, cfg.TracingURL)
 	defer func() {
 		if err := shutdownTracer(ctx); err != nil {
-			log.Fatalf("Ошибка завершения трейсинга: %v", err)
+			fatal("Ошибка завершения трейсинга", "error", err)
 		}
 	}()
-	log.Println("Трейсинг инициализирован:", cfg.TracingURL)
+	log.Info("Трейсинг инициализирован", "url", cfg.TracingURL)
 
-	initDatabase(cfg)
-	defer dao.Closedb()
+	store := initStorage(cfg)
+	defer store.close()
 
-	dbPool := dao.GetPool()
+	if cfg.DBAutoMigrate {
+		if err := applyMigrations(ctx, cfg, store.pool, "up"); err != nil {
+			fatal("Ошибка применения миграций", "error", err)
+		}
+	}
 
 	redisClient := initRedis(cfg)
-	defer redisClient.Close()
+	if redisClient != nil {
+		defer redisClient.Close()
+	}
 
 	go metrics.StartMetricsServer(cfg.MetricsAddr)
-	log.Println("Prometheus сервер запущен. Адрес метрик:", cfg.MetricsAddr)
+	log.Info("Prometheus сервер запущен", "addr", cfg.MetricsAddr)
 
-	kafkaProducer, err := kafka.NewProducer(cfg.KafkaBrokers, cfg.KafkaTopic)
-	if err != nil {
-		log.Fatalf("Ошибка при инициализации Kafka Producer: %v", err)
+	kafkaProducer := initKafkaProducer(cfg, log)
+	if kafkaProducer != nil {
+		defer kafkaProducer.Close()
 	}
-	defer kafkaProducer.Close()
 
 	wp := pool.NewWorkerPool(2)
 
+	eventBus := events.NewBus()
+	wsEvents := initWebSocketEvents(ctx, cfg, eventBus, log)
+	authenticator := auth.NewAuthenticator(cfg.ApiTokens)
+	rateLimiter := initRateLimiter(cfg, redisClient)
+
 	// Инициализация сервисов
-	userService, taskService := initServices(dbPool, wp, kafkaProducer, redisClient)
+	userService, taskService := initServices(store, wp, kafkaProducer, redisClient, eventBus, log)
+
+	// Webhooks и журнал аудита хранятся только в PostgreSQL
+	var webhookService *service.WebhookService
+	var auditService *service.AuditService
+	if store.pool != nil {
+		webhookService = service.NewWebhookService(store.pool)
+		auditService = service.NewAuditService(store.pool)
+
+		// Доставка событий задач подписчикам webhooks
+		dispatcher := webhook.NewDispatcher(store.pool, eventBus, webhook.Config{
+			Workers:        cfg.WebhookWorkers,
+			MaxAttempts:    cfg.WebhookMaxAttempts,
+			InitialBackoff: cfg.WebhookInitialBackoff,
+			MaxBackoff:     cfg.WebhookMaxBackoff,
+			Timeout:        cfg.WebhookTimeout,
+			MaxFailures:    cfg.WebhookMaxFailures,
+		}, log)
+		go dispatcher.Run(ctx)
+	} else {
+		log.Info("Webhooks и журнал аудита отключены: они доступны только с PostgreSQL", "driver", cfg.DBDriver)
+	}
+
+	// Окончательное удаление пользователей и задач из корзины по истечении срока хранения
+	purger := trash.NewPurger(taskService, userService, cfg.TrashRetention, log)
+	go purger.Run(ctx, cfg.TrashPurgeInterval)
+
+	// Проверки состояния зависимостей
+	checker := initHealthChecker(cfg, store, redisClient, kafkaProducer)
+	go checker.Run(ctx, cfg.HealthCheckInterval)
 
 	// Запуск серверов
-	startServers(ctx, cfg, userService, taskService)
+	startServers(ctx, cfg, userService, taskService, webhookService, auditService, checker, wsEvents, authenticator, rateLimiter)
 
 	// Запуск интерактивного режима
-	grpcClients := setupGRPCClients(cfg.GrpcPort)
+	grpcClients := setupGRPCClients(cfg)
 	view.RunInteractiveMode(ctx, grpcClients, wp)
 }
 
-// Функция для инициализации Redis клиента
+// storage хранилища пользователей и задач, выбранные драйвером базы данных
+type storage struct {
+	users dao.UserRepository
+	tasks dao.TaskRepository
+	tx    dao.Transactor
+	pool  *pgxpool.Pool // Пул соединений PostgreSQL, nil для SQLite
+	ping  func(ctx context.Context) error
+	close func()
+}
+
+// Функция для подключения к базе данных драйвера cfg.DBDriver
+func initStorage(cfg *config.Config) *storage {
+	switch cfg.DBDriver {
+	case config.DBDriverPostgres:
+		initDatabase(cfg)
+		dbPool := dao.GetPool()
+		registerPoolStats("primary", dbPool)
+		if cfg.DBReplicaDSN != "" {
+			dao.InitReplica(cfg.DBReplicaDSN, dbPoolSettings(cfg), slog.Default())
+			registerPoolStats("replica", dao.GetReplicaPool())
+		}
+		reads := dao.NewReadRouter(dbPool, dao.GetReplicaPool(), cfg.DBReplicaPinWindow, slog.Default())
+		return &storage{
+			users: dao.NewPgUserRepository(dbPool, reads, cfg.UserDeleteCascade, slog.Default()),
+			tasks: dao.NewPgTaskRepository(dbPool, reads, slog.Default()),
+			tx:    dao.NewTransactionManager(dbPool, slog.Default()),
+			pool:  dbPool,
+			ping:  dbPool.Ping,
+			close: func() { dao.Closedb(slog.Default()) },
+		}
+	case config.DBDriverSQLite:
+		db, err := dao.OpenSQLite(cfg.SQLitePath)
+		if err != nil {
+			fatal("Ошибка подключения к базе SQLite", "error", err)
+		}
+		slog.Info("Успешное подключение к базе SQLite", "path", cfg.SQLitePath)
+		return &storage{
+			users: dao.NewSQLiteUserRepository(db, cfg.UserDeleteCascade, slog.Default()),
+			tasks: dao.NewSQLiteTaskRepository(db, slog.Default()),
+			tx:    dao.NewSQLiteTransactor(db, slog.Default()),
+			ping:  db.PingContext,
+			close: func() {
+				if err := db.Close(); err != nil {
+					slog.Warn("Ошибка закрытия базы SQLite", "error", err)
+				}
+			},
+		}
+	default:
+		fatal("Неизвестный драйвер базы данных", "driver", cfg.DBDriver)
+		return nil
+	}
+}
+
+// registerPoolStats экспортирует статистику пула соединений PostgreSQL в метрики Prometheus
+func registerPoolStats(name string, dbPool *pgxpool.Pool) {
+	if err := metrics.RegisterPoolStats(name, dbPool); err != nil {
+		slog.Warn("Ошибка регистрации метрик пула соединений", "pool", name, "error", err)
+	}
+}
+
+// Функция для инициализации Redis клиента. Без адреса Redis кэш отключен.
 func initRedis(cfg *config.Config) *redis.Client {
+	if cfg.RedisAddr == "" {
+		slog.Info("Redis отключен: адрес не задан")
+		return nil
+	}
 	return redis.NewClient(&redis.Options{
 		Addr: cfg.RedisAddr,
 		DB:   cfg.RedisDB,
 	})
 }
 
+// Функция для инициализации ограничения частоты вызовов.
+// Счетчики хранятся в Redis, при его недоступности — в памяти процесса.
+func initRateLimiter(cfg *config.Config, redisClient *redis.Client) *ratelimit.Limiter {
+	if !cfg.RateLimitEnabled {
+		return nil
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimitRedis && redisClient != nil {
+		store = ratelimit.NewRedisStore(redisClient, store, slog.Default())
+	}
+
+	return ratelimit.New(store, ratelimit.Config{
+		Default: cfg.RateLimitDefault,
+		Methods: cfg.RateLimitMethods,
+		Callers: cfg.RateLimitCallers,
+	})
+}
+
+// Функция для инициализации Kafka Producer. Без брокеров сообщения о задачах не отправляются.
+func initKafkaProducer(cfg *config.Config, log *slog.Logger) *kafka.Producer {
+	if len(cfg.KafkaBrokers) == 0 {
+		log.Info("Kafka отключена: брокеры не заданы")
+		return nil
+	}
+
+	kafkaProducer, err := kafka.NewProducer(cfg.KafkaBrokers, cfg.KafkaTopic, log)
+	if err != nil {
+		fatal("Ошибка при инициализации Kafka Producer", "error", err)
+	}
+	return kafkaProducer
+}
+
+// initWebSocketEvents возвращает шину событий для WebSocket клиентов. С Kafka события читаются
+// из топика задач, чтобы клиенты любого экземпляра получали изменения, сделанные на всех экземплярах.
+// Без Kafka используется шина процесса: клиенты видят только изменения этого экземпляра,
+// поэтому такой режим подходит только для запуска в одном экземпляре.
+func initWebSocketEvents(ctx context.Context, cfg *config.Config, local *events.Bus, log *slog.Logger) *events.Bus {
+	if len(cfg.KafkaBrokers) == 0 {
+		log.Warn("WebSocket получает события только этого экземпляра: Kafka отключена")
+		return local
+	}
+
+	bus := events.NewBus()
+	relay, err := kafka.NewEventRelay(cfg.KafkaBrokers, cfg.KafkaTopic, bus, log)
+	if err != nil {
+		fatal("Ошибка при инициализации трансляции событий из Kafka", "error", err)
+	}
+	go func() {
+		if err := relay.Run(ctx); err != nil {
+			fatal("Ошибка трансляции событий из Kafka", "error", err)
+		}
+	}()
+	return bus
+}
+
 // Функция для инициализации сервисов с Redis-кэшем и Kafka
-func initServices(dbPool *pgxpool.Pool, wp *pool.WorkerPool, kafkaProducer *kafka.Producer, redisClient *redis.Client) (
+func initServices(store *storage, wp *pool.WorkerPool, kafkaProducer *kafka.Producer, redisClient *redis.Client, eventBus *events.Bus, log *slog.Logger) (
 	*service.UserService, *service.TaskService) {
 
 	cacheConfig := cache.CacheConfig{
@@ -103,78 +285,241 @@ func initServices(dbPool *pgxpool.Pool, wp *pool.WorkerPool, kafkaProducer *kafk
 	userCache := cache.NewRedisCache[string, model.User](redisClient, cacheConfig)
 	taskCache := cache.NewRedisCache[string, model.Task](redisClient, cacheConfig)
 
-	userService := service.NewUserService(dbPool, wp, userCache)
-	taskService := service.NewTaskService(dbPool, wp, taskCache, kafkaProducer)
+	taskService := service.NewTaskService(store.tasks, store.tx, wp, taskCache, kafkaProducer, eventBus, log)
+	userService := service.NewUserService(store.users, taskService, wp, userCache, log)
 
 	return userService, taskService
 }
 
+// Функция для инициализации проверок состояния базы данных, Redis и Kafka.
+// Отключенные Redis и Kafka не проверяются.
+func initHealthChecker(cfg *config.Config, store *storage, redisClient *redis.Client, kafkaProducer *kafka.Producer) *health.Checker {
+	checker := health.NewChecker(cfg.HealthCheckTimeout)
+
+	checker.Register(cfg.DBDriver, store.ping)
+	if redisClient != nil {
+		checker.Register("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
+	if kafkaProducer != nil {
+		checker.Register("kafka", kafkaProducer.Ping)
+	}
+
+	return checker
+}
+
 // Запуск gRPC и HTTP Gateway серверов
 func startServers(
 	ctx context.Context, cfg *config.Config,
-	userService *service.UserService, taskService *service.TaskService) {
+	userService *service.UserService, taskService *service.TaskService, webhookService *service.WebhookService,
+	auditService *service.AuditService, checker *health.Checker, eventBus *events.Bus, authenticator *auth.Authenticator, rateLimiter *ratelimit.Limiter) {
 
 	go func() {
-		if err := startGRPCServer(cfg.GrpcPort, userService, taskService); err != nil {
-			log.Fatalf("Ошибка при запуске gRPC сервера: %v", err)
+		if err := startGRPCServer(cfg, userService, taskService, webhookService, auditService, checker, authenticator, rateLimiter); err != nil {
+			fatal("Ошибка при запуске gRPC сервера", "error", err)
 		}
-		log.Println("gRPC сервер завершил работу")
+		slog.Info("gRPC сервер завершил работу")
 	}()
 
 	go func() {
-		log.Printf("Запуск HTTP Gateway на порту %s", cfg.HttpPort)
-		if err := gateway.RunGateway(ctx, "localhost:"+cfg.GrpcPort, "localhost:"+cfg.HttpPort); err != nil {
-			log.Fatalf("Ошибка при запуске HTTP Gateway: %v", err)
+		slog.Info("Запуск HTTP Gateway", "port", cfg.HttpPort)
+		grpcTLS, err := grpcClientTLS(cfg)
+		if err != nil {
+			fatal("Ошибка настройки TLS для gRPC клиента HTTP Gateway", "error", err)
+		}
+		gatewayConfig := gateway.Config{
+			GrpcEndpoint: "localhost:" + cfg.GrpcPort,
+			HttpEndpoint: "localhost:" + cfg.HttpPort,
+			PublicURL:    cfg.PublicURL,
+			CORS: gateway.CORSConfig{
+				AllowedOrigins: cfg.CorsAllowedOrigins,
+				AllowedMethods: cfg.CorsAllowedMethods,
+				AllowedHeaders: cfg.CorsAllowedHeaders,
+				ExposedHeaders: cfg.CorsExposedHeaders,
+			},
+			TLSCertFile: cfg.HttpTLSCertFile,
+			TLSKeyFile:  cfg.HttpTLSKeyFile,
+			GrpcTLS:     grpcTLS,
+			Gzip:        cfg.HttpGzip,
+
+			Events:        eventBus,
+			Authenticator: authenticator,
+			WsClients:     cfg.WsAllowedClients,
+			GraphQL:       graphql.NewHandler(userService, taskService, authenticator),
+			RateLimiter:   rateLimiter,
+
+			Logger: slog.Default(),
+		}
+		if err := gateway.RunGateway(ctx, gatewayConfig, checker); err != nil {
+			fatal("Ошибка при запуске HTTP Gateway", "error", err)
 		}
 	}()
 }
 
 // Инициализация базы данных
 func initDatabase(cfg *config.Config) {
-	port, err := strconv.Atoi(cfg.DBPort)
+	dao.Initdb(cfg.PostgresDSN(), dbPoolSettings(cfg), slog.Default())
+}
+
+// dbPoolSettings параметры пулов соединений PostgreSQL из конфигурации
+func dbPoolSettings(cfg *config.Config) dao.PoolSettings {
+	return dao.PoolSettings{
+		MinConns:         int32(cfg.DBMinConns),
+		MaxConns:         int32(cfg.DBMaxConns),
+		MaxConnLifetime:  cfg.DBMaxConnLifetime,
+		MaxConnIdleTime:  cfg.DBMaxConnIdleTime,
+		ConnectTimeout:   cfg.DBConnectTimeout,
+		StatementTimeout: cfg.DBStatementTimeout,
+		ApplicationName:  cfg.DBApplicationName,
+	}
+}
+
+// runMigrate выполняет подкоманду migrate с настройками базы данных из конфигурации
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) {
+	if len(args) != 1 {
+		fatal("Использование: todo migrate " + migrate.Commands)
+	}
+
+	var dbPool *pgxpool.Pool
+	if cfg.DBDriver == config.DBDriverPostgres {
+		initDatabase(cfg)
+		defer dao.Closedb(slog.Default())
+		dbPool = dao.GetPool()
+	}
+
+	if err := applyMigrations(ctx, cfg, dbPool, args[0]); err != nil {
+		fatal("Ошибка выполнения миграций", "command", args[0], "error", err)
+	}
+}
+
+// applyMigrations выполняет команду встроенных миграций драйвера cfg.DBDriver.
+// Миграции PostgreSQL выполняются под advisory lock, после up к базе применяется SEARCH_LANGUAGE.
+func applyMigrations(ctx context.Context, cfg *config.Config, dbPool *pgxpool.Pool, command string) error {
+	var migrator *migrate.Migrator
+	var err error
+	switch cfg.DBDriver {
+	case config.DBDriverPostgres:
+		migrator, err = migrate.New(dbPool, slog.Default())
+	case config.DBDriverSQLite:
+		migrator, err = migrate.NewSQLite(cfg.SQLitePath, slog.Default())
+	default:
+		err = fmt.Errorf("неизвестный драйвер базы данных %q", cfg.DBDriver)
+	}
 	if err != nil {
-		log.Fatalf("Некорректный формат порта: %v", err)
+		return err
+	}
+	defer func() {
+		if err := migrator.Close(); err != nil {
+			slog.Warn("Ошибка закрытия соединения миграций", "error", err)
+		}
+	}()
+
+	if err := migrator.Run(ctx, command, os.Stdout); err != nil {
+		return err
 	}
-	dao.Initdb(cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBHost, port)
+
+	// Смена SEARCH_LANGUAGE применяется вместе с миграциями, а не при каждом запуске сервиса:
+	// перестроение блокирует изменение задач, а реплики с разными языками перестраивали бы векторы по очереди
+	if dbPool != nil && command == "up" {
+		reindexed, err := dao.ReindexSearch(ctx, cfg.SearchLanguage, dao.NewTransactionManager(dbPool, slog.Default()))
+		if err != nil {
+			return fmt.Errorf("ошибка перестроения поискового индекса: %w", err)
+		}
+		if reindexed > 0 {
+			slog.Info("Поисковый индекс задач перестроен", "language", cfg.SearchLanguage, "tasks", reindexed)
+		}
+	}
+	return nil
 }
 
 // Запуск gRPC сервера
-func startGRPCServer(grpcPort string, userService *service.UserService, taskService *service.TaskService) error {
-	lis, err := net.Listen("tcp", ":"+grpcPort)
+func startGRPCServer(cfg *config.Config, userService *service.UserService, taskService *service.TaskService,
+	webhookService *service.WebhookService, auditService *service.AuditService, checker *health.Checker,
+	authenticator *auth.Authenticator, rateLimiter *ratelimit.Limiter) error {
+	lis, err := net.Listen("tcp", ":"+cfg.GrpcPort)
 	if err != nil {
-		return fmt.Errorf("не удалось начать слушать порт %s: %w", grpcPort, err)
+		return fmt.Errorf("не удалось начать слушать порт %s: %w", cfg.GrpcPort, err)
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(50 * 1024 * 1024),
+		grpc.MaxSendMsgSize(50 * 1024 * 1024),
+	}
+	if cfg.GrpcTLSCertFile != "" {
+		tlsConfig, err := tlsconfig.Server(cfg.GrpcTLSCertFile, cfg.GrpcTLSKeyFile, cfg.GrpcTLSCAFile)
+		if err != nil {
+			return fmt.Errorf("ошибка настройки TLS gRPC сервера: %w", err)
+		}
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
+	serverOptions = append(serverOptions, interceptor.ServerOptions(interceptor.Config{
+		DefaultTimeout: cfg.GrpcDefaultTimeout,
+		MethodTimeouts: cfg.GrpcMethodTimeouts,
+		Authenticator:  authenticator,
+		RateLimiter:    rateLimiter,
+		TrustedProxies: cfg.GrpcTrustedProxies,
+		Logger:         slog.Default(),
+	})...)
 
-	grpcServer := grpc.NewServer(
-		grpc.MaxRecvMsgSize(50*1024*1024),
-		grpc.MaxSendMsgSize(50*1024*1024),
-	)
+	grpcServer := grpc.NewServer(serverOptions...)
 
 	// Убираем WorkerPool из параметров
-	v1.RegisterAPIServiceServer(grpcServer, server.NewAPIServiceServer(userService, taskService))
+	v1.RegisterAPIServiceServer(grpcServer, server.NewAPIServiceServer(userService, taskService, auditService))
+	v2.RegisterAPIServiceServer(grpcServer, server.NewAPIServiceV2Server(userService, taskService, auditService))
+	if webhookService != nil {
+		v2.RegisterWebhookServiceServer(grpcServer, server.NewWebhookServiceV2Server(webhookService))
+	}
+	healthpb.RegisterHealthServer(grpcServer, checker.Server())
 
-	log.Printf("gRPC сервер запущен на порту %s", grpcPort)
+	if cfg.GrpcReflection {
+		reflection.Register(grpcServer)
+		slog.Info("gRPC server reflection включен")
+	}
+
+	slog.Info("gRPC сервер запущен", "port", cfg.GrpcPort)
 	return grpcServer.Serve(lis)
 }
 
+// grpcClientTLS возвращает TLS-конфигурацию для подключения к gRPC серверу
+// или nil, если сервер работает без TLS
+func grpcClientTLS(cfg *config.Config) (*tls.Config, error) {
+	if cfg.GrpcTLSCertFile == "" {
+		return nil, nil
+	}
+	return tlsconfig.Client(cfg.GrpcTLSClientCertFile, cfg.GrpcTLSClientKeyFile, cfg.GrpcTLSCAFile, cfg.GrpcTLSServerName)
+}
+
 // Подключение к gRPC клиентам
-func setupGRPCClients(grpcPort string) *client.APIServiceClientWrapper {
+func setupGRPCClients(cfg *config.Config) *client.APIServiceClientWrapper {
+	grpcTLS, err := grpcClientTLS(cfg)
+	if err != nil {
+		fatal("Ошибка настройки TLS для gRPC клиента", "error", err)
+	}
+	transportCredentials := insecure.NewCredentials()
+	if grpcTLS != nil {
+		transportCredentials = credentials.NewTLS(grpcTLS)
+	}
+
 	clientOptions := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(transportCredentials),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(50*1024*1024),
 			grpc.MaxCallSendMsgSize(50*1024*1024),
 		),
 	}
+	if cfg.ClientApiToken != "" {
+		clientOptions = append(clientOptions, grpc.WithUnaryInterceptor(client.TokenInterceptor(cfg.ClientApiToken)))
+	}
 
-	grpcConn, err := grpc.NewClient("localhost:"+grpcPort, clientOptions...)
+	grpcConn, err := grpc.NewClient("localhost:"+cfg.GrpcPort, clientOptions...)
 	if err != nil {
-		log.Fatalf("Не удалось подключиться к gRPC серверу: %v", err)
+		fatal("Не удалось подключиться к gRPC серверу", "error", err)
 	}
 
 	grpcClientWrapper, err := client.NewAPIServiceClientWrapper(grpcConn)
 	if err != nil {
-		log.Fatalf("Ошибка при создании обертки gRPC клиента: %v", err)
+		fatal("Ошибка при создании обертки gRPC клиента", "error", err)
 	}
 
 	return grpcClientWrapper
@@ -186,49 +531,14 @@ func gracefulShutdown(cancelFunc context.CancelFunc) {
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-signals
-		log.Println("Получен сигнал завершения. Завершаем работу...")
+		slog.Info("Получен сигнал завершения, завершаем работу")
 		cancelFunc()
 		os.Exit(0)
 	}()
 }
 
-func addServersSectionToOpenAPI() error {
-	openAPIFile := "./internal/api/v1/openapi.yaml"
-
-	data, err := os.ReadFile(openAPIFile)
-	if err != nil {
-		return fmt.Errorf("ошибка при чтении файла: %w", err)
-	}
-
-	content := string(data)
-
-	if strings.Contains(content, "info:") {
-		content = strings.Replace(content, `title: ""`, `title: "Order Management API"`, 1)
-		content = strings.Replace(content, `version: 0.0.1`, `version: 1.0.0`, 1)
-		content = strings.Replace(content, `description: ""`, `description: "API для управления заказами, упаковками, возвратами, статусами и пользователями"`, 1)
-	} else {
-		infoSection := `
-info:
-  title: "Order Management API"
-  description: "API для управления заказами, упаковками, возвратами, статусами и пользователями"
-  version: "1.0.0"
-`
-		content = infoSection + content
-	}
-
-	if !strings.Contains(content, "servers:") {
-		serversSection := `
-servers:
-  - url: http://localhost:8080
-    description: "Local HTTP Gateway"
-`
-		content += serversSection
-	}
-
-	err = os.WriteFile(openAPIFile, []byte(content), 0644)
-	if err != nil {
-		return fmt.Errorf("ошибка при записи в файл: %w", err)
-	}
-
-	return nil
+// fatal пишет ошибку в журнал и завершает процесс
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
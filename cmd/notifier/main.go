@@ -3,7 +3,9 @@ package main
 import (
 	"TODO/internal/config"
 	"TODO/internal/kafka"
-	"log"
+	"TODO/internal/logger"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,20 +14,28 @@ import (
 func main() {
 	cfg := config.LoadConfig()
 
+	log, err := logger.New(os.Stderr, logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка настройки журнала: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(log)
+
 	consumerGroup, err := kafka.NewConsumerGroup(cfg.KafkaBrokers, cfg.KafkaGroupID)
 	if err != nil {
-		log.Fatalf("Ошибка создания consumer группы: %v", err)
+		log.Error("Ошибка создания consumer группы", "error", err)
+		os.Exit(1)
 	}
 
 	go func() {
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 		<-signals
-		log.Println("Получен сигнал завершения. Завершаем работу...")
+		log.Info("Получен сигнал завершения, завершаем работу")
 		os.Exit(0)
 	}()
 
-	handler := kafka.NotifierHandler{}
+	handler := kafka.NewNotifierHandler(log)
 
 	kafka.StartConsumer(consumerGroup, []string{cfg.KafkaTopic}, handler)
 
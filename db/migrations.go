@@ -0,0 +1,14 @@
+// Package db содержит SQL миграции схемы, встроенные в бинарный файл
+package db
+
+import "embed"
+
+// Migrations миграции goose из каталога migrations
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// SQLiteMigrations миграции goose для хранилища SQLite из каталога sqlite
+//
+//go:embed sqlite/*.sql
+var SQLiteMigrations embed.FS
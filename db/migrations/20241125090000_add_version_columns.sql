@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN version BIGINT NOT NULL DEFAULT 1; -- Версия записи для оптимистичной блокировки
+ALTER TABLE tasks ADD COLUMN version BIGINT NOT NULL DEFAULT 1; -- Версия записи для оптимистичной блокировки
+
+-- +goose Down
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
+ALTER TABLE users DROP COLUMN IF EXISTS version;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE webhook_subscriptions (
+                       id BIGSERIAL PRIMARY KEY,                      -- Уникальный идентификатор подписки
+                       url TEXT NOT NULL,                             -- Адрес, на который отправляются уведомления
+                       secret TEXT NOT NULL,                          -- Секрет подписи HMAC-SHA256
+                       event_types TEXT[] NOT NULL,                   -- Типы событий (task.created, task.updated, task.deleted)
+                       user_id BIGINT,                                -- Только задачи пользователя, NULL — все задачи
+                       active BOOLEAN NOT NULL DEFAULT TRUE,          -- Подписка получает уведомления
+                       failure_count INT NOT NULL DEFAULT 0,          -- Число неудачных доставок подряд
+                       created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Дата создания
+                       updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Дата обновления
+                       disabled_at TIMESTAMP                          -- Дата автоматического отключения
+);
+
+CREATE TABLE webhook_deliveries (
+                       id BIGSERIAL PRIMARY KEY,                      -- Уникальный идентификатор попытки доставки
+                       subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE, -- Подписка
+                       event_id TEXT NOT NULL,                        -- Идентификатор события, общий для всех попыток
+                       event_type TEXT NOT NULL,                      -- Тип события
+                       attempt INT NOT NULL,                          -- Номер попытки
+                       status_code INT NOT NULL DEFAULT 0,            -- HTTP статус ответа, 0 — ответ не получен
+                       success BOOLEAN NOT NULL,                      -- Доставка успешна
+                       error TEXT NOT NULL DEFAULT '',                -- Описание ошибки
+                       duration_ms BIGINT NOT NULL DEFAULT 0,         -- Длительность запроса
+                       created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP -- Время попытки
+);
+
+CREATE INDEX webhook_deliveries_subscription_id_idx ON webhook_deliveries (subscription_id, id DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,58 @@
+-- +goose Up
+-- Миграция не удаляет данные: если есть задачи без пользователя или повторяющиеся имена пользователей,
+-- она прерывается до изменения схемы, и их нужно исправить вручную перед повторным запуском
+-- +goose StatementBegin
+DO $$
+DECLARE
+    orphans    BIGINT;
+    duplicates TEXT;
+BEGIN
+    SELECT count(*) INTO orphans FROM tasks t WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = t.user_id);
+    IF orphans > 0 THEN
+        RAISE EXCEPTION 'найдено % задач без пользователя, внешний ключ tasks.user_id не может быть создан', orphans
+            USING HINT = 'Найдите задачи запросом SELECT * FROM tasks t WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = t.user_id), '
+                      || 'перенесите их существующему пользователю или удалите и повторите миграцию';
+    END IF;
+
+    SELECT string_agg(format('%s (%s)', username, cnt), ', ' ORDER BY username) INTO duplicates
+    FROM (SELECT username, count(*) AS cnt FROM users GROUP BY username HAVING count(*) > 1) d;
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'повторяющиеся имена пользователей: %, уникальный индекс users_username_key не может быть создан', duplicates
+            USING HINT = 'Переименуйте повторяющихся пользователей, например UPDATE users SET username = username || ''-'' || id WHERE id = ..., '
+                      || 'и повторите миграцию';
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+UPDATE users SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+ALTER TABLE users ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE tasks SET note = '' WHERE note IS NULL;
+UPDATE tasks SET done = FALSE WHERE done IS NULL;
+UPDATE tasks SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE tasks SET updated_at = created_at WHERE updated_at IS NULL;
+ALTER TABLE tasks ALTER COLUMN note SET NOT NULL;
+ALTER TABLE tasks ALTER COLUMN done SET NOT NULL;
+ALTER TABLE tasks ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE tasks ALTER COLUMN updated_at SET NOT NULL;
+
+-- Пользователь с задачами удаляется только вместе с ними, каскад выполняет приложение (USER_DELETE_CASCADE)
+ALTER TABLE tasks ADD CONSTRAINT tasks_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+
+CREATE UNIQUE INDEX users_username_key ON users (username);
+CREATE INDEX tasks_user_id_done_idx ON tasks (user_id, done, id); -- Задачи пользователя с фильтром по статусу
+CREATE INDEX tasks_done_idx ON tasks (done, id);                  -- Все задачи с фильтром по статусу
+
+-- +goose Down
+DROP INDEX IF EXISTS tasks_done_idx;
+DROP INDEX IF EXISTS tasks_user_id_done_idx;
+DROP INDEX IF EXISTS users_username_key;
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_user_id_fkey;
+
+ALTER TABLE tasks ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE tasks ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE tasks ALTER COLUMN done DROP NOT NULL;
+ALTER TABLE tasks ALTER COLUMN note DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN created_at DROP NOT NULL;
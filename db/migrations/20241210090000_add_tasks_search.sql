@@ -0,0 +1,14 @@
+-- +goose Up
+-- Поисковый вектор задачи: совпадения в названии весят больше, чем в заметке.
+-- Приложение пересчитывает его при создании и изменении задачи, а при смене SEARCH_LANGUAGE
+-- перестраивает векторы всех задач (см. 20241225090000_create_search_settings.sql)
+ALTER TABLE tasks ADD COLUMN search_vector TSVECTOR;
+
+UPDATE tasks SET search_vector = setweight(to_tsvector('russian', title), 'A') || setweight(to_tsvector('russian', note), 'B');
+ALTER TABLE tasks ALTER COLUMN search_vector SET NOT NULL;
+
+CREATE INDEX tasks_search_vector_idx ON tasks USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX IF EXISTS tasks_search_vector_idx;
+ALTER TABLE tasks DROP COLUMN IF EXISTS search_vector;
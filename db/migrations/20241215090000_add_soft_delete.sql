@@ -0,0 +1,26 @@
+-- +goose Up
+-- Удаленные пользователи и задачи попадают в корзину и окончательно удаляются
+-- фоновой очисткой по истечении TRASH_RETENTION
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Имя удаленного пользователя можно занять, восстановление такого пользователя отклоняется
+DROP INDEX IF EXISTS users_username_key;
+CREATE UNIQUE INDEX users_username_key ON users (username) WHERE deleted_at IS NULL;
+
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX tasks_deleted_at_idx ON tasks (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+-- Содержимое корзины удаляется окончательно
+DELETE FROM tasks WHERE deleted_at IS NOT NULL OR user_id IN (SELECT id FROM users WHERE deleted_at IS NOT NULL);
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS tasks_deleted_at_idx;
+DROP INDEX IF EXISTS users_deleted_at_idx;
+
+DROP INDEX IF EXISTS users_username_key;
+CREATE UNIQUE INDEX users_username_key ON users (username);
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
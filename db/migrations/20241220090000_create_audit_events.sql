@@ -0,0 +1,94 @@
+-- +goose Up
+-- Журнал изменений пользователей и задач. Записи добавляются триггерами в той же транзакции,
+-- что и изменение, вызывающая сторона и ID запроса передаются через set_config('audit.*')
+CREATE TABLE audit_events (
+    id          BIGSERIAL PRIMARY KEY,
+    entity_type TEXT        NOT NULL,           -- task или user
+    entity_id   BIGINT      NOT NULL,
+    operation   TEXT        NOT NULL,           -- create, update, delete, restore, purge
+    actor       TEXT        NOT NULL,
+    request_id  TEXT        NOT NULL DEFAULT '',
+    before      JSONB,                          -- Состояние до изменения, NULL при создании
+    after       JSONB,                          -- Состояние после изменения, NULL при окончательном удалении
+    diff        JSONB       NOT NULL,           -- Измененные поля: {"поле": {"old": ..., "new": ...}}
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_events_entity_idx ON audit_events (entity_type, entity_id, id); -- История записи
+CREATE INDEX audit_events_actor_idx ON audit_events (actor, id);
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_record() RETURNS TRIGGER AS $$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+    changes JSONB := '{}';
+    field   TEXT;
+    op      TEXT;
+BEGIN
+    -- Поисковый вектор вычисляется из названия и заметки и в журнал не попадает
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD) - 'search_vector';
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW) - 'search_vector';
+    END IF;
+
+    FOR field IN SELECT jsonb_object_keys(COALESCE(old_row, '{}') || COALESCE(new_row, '{}')) LOOP
+        IF old_row -> field IS DISTINCT FROM new_row -> field THEN
+            changes := changes || jsonb_build_object(field, jsonb_build_object('old', old_row -> field, 'new', new_row -> field));
+        END IF;
+    END LOOP;
+
+    IF TG_OP = 'INSERT' THEN
+        op := 'create';
+    ELSIF TG_OP = 'DELETE' THEN
+        op := 'purge';
+    ELSIF changes = '{}' THEN
+        RETURN NULL;
+    ELSIF old_row ->> 'deleted_at' IS NULL AND new_row ->> 'deleted_at' IS NOT NULL THEN
+        op := 'delete';
+    ELSIF old_row ->> 'deleted_at' IS NOT NULL AND new_row ->> 'deleted_at' IS NULL THEN
+        op := 'restore';
+    ELSE
+        op := 'update';
+    END IF;
+
+    INSERT INTO audit_events (entity_type, entity_id, operation, actor, request_id, before, after, diff)
+    VALUES (
+        TG_ARGV[0],
+        (COALESCE(new_row, old_row) ->> 'id')::BIGINT,
+        op,
+        COALESCE(NULLIF(current_setting('audit.actor', true), ''), 'db:' || current_user),
+        COALESCE(current_setting('audit.request_id', true), ''),
+        old_row,
+        new_row,
+        changes
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events: записи журнала аудита нельзя изменять или удалять';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER tasks_audit AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION audit_record('task');
+CREATE TRIGGER users_audit AFTER INSERT OR UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION audit_record('user');
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS users_audit ON users;
+DROP TRIGGER IF EXISTS tasks_audit ON tasks;
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+DROP FUNCTION IF EXISTS audit_record();
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Язык, с которым построены поисковые векторы задач. Векторы и поисковые запросы строятся
+-- с этим языком, а подкоманда migrate up с другим SEARCH_LANGUAGE перестраивает векторы всех задач
+-- и сохраняет новый язык. NULL — язык неизвестен: до этой миграции векторы строились с SEARCH_LANGUAGE,
+-- который мог отличаться от языка заполнения в 20241210090000, поэтому первый migrate up перестраивает их все.
+CREATE TABLE search_settings (
+    singleton BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton), -- Таблица всегда состоит из одной строки
+    language  REGCONFIG
+);
+
+INSERT INTO search_settings DEFAULT VALUES;
+
+-- +goose Down
+DROP TABLE IF EXISTS search_settings;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- Схема SQLite повторяет итоговую схему PostgreSQL из db/migrations.
+-- Время хранится текстом в UTC в формате с фиксированной длиной, поэтому его можно сравнивать как строки.
+CREATE TABLE users (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,      -- Уникальный идентификатор пользователя
+                       username TEXT NOT NULL,                    -- Имя пользователя
+                       created_at TIMESTAMP NOT NULL,             -- Дата регистрации
+                       version INTEGER NOT NULL DEFAULT 1,        -- Версия для оптимистичной блокировки
+                       deleted_at TIMESTAMP                       -- Время перемещения в корзину
+);
+
+CREATE TABLE tasks (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,      -- Уникальный идентификатор задачи
+                       user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE RESTRICT, -- ID пользователя
+                       title TEXT NOT NULL,                       -- Название задачи
+                       note TEXT NOT NULL DEFAULT '',             -- Заметка
+                       done BOOLEAN NOT NULL DEFAULT FALSE,       -- Статус выполнения задачи
+                       created_at TIMESTAMP NOT NULL,             -- Дата создания
+                       updated_at TIMESTAMP NOT NULL,             -- Дата обновления
+                       version INTEGER NOT NULL DEFAULT 1,        -- Версия для оптимистичной блокировки
+                       deleted_at TIMESTAMP                       -- Время перемещения в корзину
+);
+
+-- Имя удаленного пользователя можно занять, восстановление такого пользователя отклоняется
+CREATE UNIQUE INDEX users_username_key ON users (username) WHERE deleted_at IS NULL;
+CREATE INDEX tasks_user_id_done_idx ON tasks (user_id, done, id); -- Задачи пользователя с фильтром по статусу
+CREATE INDEX tasks_done_idx ON tasks (done, id);                  -- Все задачи с фильтром по статусу
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX tasks_deleted_at_idx ON tasks (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS users;
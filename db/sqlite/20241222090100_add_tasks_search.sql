@@ -0,0 +1,38 @@
+-- +goose Up
+-- Полнотекстовый индекс FTS5 по названию и заметке задачи. Индекс хранит только лексемы,
+-- текст берется из tasks, а триггеры поддерживают индекс в актуальном состоянии.
+-- Токенизатор unicode61 не приводит слова к основе, поэтому поиск ищет словоформы целиком.
+CREATE VIRTUAL TABLE tasks_fts USING fts5(
+    title,
+    note,
+    content = 'tasks',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO tasks_fts (rowid, title, note) SELECT id, title, note FROM tasks;
+
+-- +goose StatementBegin
+CREATE TRIGGER tasks_fts_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO tasks_fts (rowid, title, note) VALUES (new.id, new.title, new.note);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER tasks_fts_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, note) VALUES ('delete', old.id, old.title, old.note);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER tasks_fts_update AFTER UPDATE OF title, note ON tasks BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, note) VALUES ('delete', old.id, old.title, old.note);
+    INSERT INTO tasks_fts (rowid, title, note) VALUES (new.id, new.title, new.note);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS tasks_fts_update;
+DROP TRIGGER IF EXISTS tasks_fts_delete;
+DROP TRIGGER IF EXISTS tasks_fts_insert;
+DROP TABLE IF EXISTS tasks_fts;
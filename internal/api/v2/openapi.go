@@ -0,0 +1,8 @@
+package v2
+
+import "embed"
+
+// OpenAPI содержит спецификацию openapi.yaml, сгенерированную protoc-gen-openapi
+//
+//go:embed openapi.yaml
+var OpenAPI embed.FS
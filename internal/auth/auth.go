@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthenticated возвращается, если токен отсутствует или неизвестен
+var ErrUnauthenticated = errors.New("неверный или отсутствующий API токен")
+
+// Principal описывает вызывающую сторону, прошедшую аутентификацию
+type Principal struct {
+	Name  string // Имя клиента, которому выдан токен
+	Token string // API токен клиента
+}
+
+// Authenticator проверяет API токены из статического списка.
+// Пустой список отключает проверку: все вызовы считаются анонимными.
+type Authenticator struct {
+	tokens map[string]string
+}
+
+// NewAuthenticator создает Authenticator из записей вида "token" или "name:token"
+func NewAuthenticator(entries []string) *Authenticator {
+	tokens := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(entry, ":")
+		if !ok {
+			name, token = "", entry
+		}
+		tokens[token] = name
+	}
+	return &Authenticator{tokens: tokens}
+}
+
+// Enabled сообщает, настроены ли токены
+func (a *Authenticator) Enabled() bool {
+	return a != nil && len(a.tokens) > 0
+}
+
+// Authenticate проверяет токен и возвращает вызывающую сторону
+func (a *Authenticator) Authenticate(token string) (Principal, error) {
+	if !a.Enabled() {
+		return Principal{Name: "anonymous"}, nil
+	}
+	for known, name := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			return Principal{Name: name, Token: token}, nil
+		}
+	}
+	return Principal{}, ErrUnauthenticated
+}
+
+type principalKey struct{}
+
+// NewContext сохраняет вызывающую сторону в контексте
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext возвращает вызывающую сторону из контекста
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// BearerToken извлекает токен из значения заголовка Authorization вида "Bearer <token>"
+func BearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// RequestToken извлекает API токен из заголовков X-Api-Token, Authorization или параметра token.
+// Браузеры не позволяют задать заголовки при открытии WebSocket, поэтому поддерживается параметр запроса.
+func RequestToken(r *http.Request) string {
+	if token := r.Header.Get("X-Api-Token"); token != "" {
+		return token
+	}
+	if token := BearerToken(r.Header.Get("Authorization")); token != "" {
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
@@ -20,7 +20,8 @@ type RedisCache[K comparable, V any] struct {
 	config CacheConfig
 }
 
-// NewRedisCache создает новый экземпляр кэша с Redis-клиентом
+// NewRedisCache создает новый экземпляр кэша с Redis-клиентом.
+// Если client равен nil, кэш отключен: запись и удаление ничего не делают, а чтение всегда промахивается.
 func NewRedisCache[K comparable, V any](client *redis.Client, config CacheConfig) *RedisCache[K, V] {
 	return &RedisCache[K, V]{
 		client: client,
@@ -30,6 +31,10 @@ func NewRedisCache[K comparable, V any](client *redis.Client, config CacheConfig
 
 // Set сохраняет объект в Redis с временем жизни (TTL)
 func (c *RedisCache[K, V]) Set(ctx context.Context, key K, value V, ttl ...time.Duration) error {
+	if c.client == nil {
+		return nil
+	}
+
 	data, err := json.Marshal(value)
 	if err != nil {
 		return fmt.Errorf("ошибка сериализации данных для кэша: %w", err)
@@ -50,6 +55,10 @@ func (c *RedisCache[K, V]) Set(ctx context.Context, key K, value V, ttl ...time.
 
 // Get возвращает объект из Redis и десериализует его
 func (c *RedisCache[K, V]) Get(ctx context.Context, key K, dest interface{}) error {
+	if c.client == nil {
+		return fmt.Errorf("данные не найдены в кэше по ключу: %v", key)
+	}
+
 	val, err := c.client.Get(ctx, fmt.Sprintf("%v", key)).Result()
 	if err == redis.Nil {
 		return fmt.Errorf("данные не найдены в кэше по ключу: %v", key)
@@ -67,6 +76,10 @@ func (c *RedisCache[K, V]) Get(ctx context.Context, key K, dest interface{}) err
 
 // Delete удаляет объект из Redis по ключу
 func (c *RedisCache[K, V]) Delete(ctx context.Context, key K) error {
+	if c.client == nil {
+		return nil
+	}
+
 	err := c.client.Del(ctx, fmt.Sprintf("%v", key)).Err()
 	if err != nil {
 		return fmt.Errorf("ошибка удаления данных из Redis: %w", err)
@@ -77,6 +90,10 @@ func (c *RedisCache[K, V]) Delete(ctx context.Context, key K) error {
 
 // Exists проверяет наличие объекта в Redis по ключу
 func (c *RedisCache[K, V]) Exists(ctx context.Context, key K) (bool, error) {
+	if c.client == nil {
+		return false, nil
+	}
+
 	count, err := c.client.Exists(ctx, fmt.Sprintf("%v", key)).Result()
 	if err != nil {
 		return false, fmt.Errorf("ошибка при проверке существования ключа в Redis: %w", err)
@@ -87,6 +104,10 @@ func (c *RedisCache[K, V]) Exists(ctx context.Context, key K) (bool, error) {
 
 // Keys возвращает все ключи по шаблону
 func (c *RedisCache[K, V]) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if c.client == nil {
+		return nil, nil
+	}
+
 	keys, err := c.client.Keys(ctx, pattern).Result()
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении ключей из Redis: %w", err)
@@ -96,6 +117,10 @@ func (c *RedisCache[K, V]) Keys(ctx context.Context, pattern string) ([]string,
 
 // SetSlice сохраняет срез объектов в Redis с временем жизни (TTL)
 func (c *RedisCache[K, V]) SetSlice(ctx context.Context, key K, values []V, ttl ...time.Duration) error {
+	if c.client == nil {
+		return nil
+	}
+
 	data, err := json.Marshal(values)
 	if err != nil {
 		return fmt.Errorf("ошибка сериализации данных для кэша: %w", err)
@@ -116,6 +141,10 @@ func (c *RedisCache[K, V]) SetSlice(ctx context.Context, key K, values []V, ttl
 
 // GetSlice возвращает срез объектов из Redis и десериализует его
 func (c *RedisCache[K, V]) GetSlice(ctx context.Context, key K, dest *[]V) error {
+	if c.client == nil {
+		return fmt.Errorf("данные не найдены в кэше по ключу: %v", key)
+	}
+
 	val, err := c.client.Get(ctx, fmt.Sprintf("%v", key)).Result()
 	if err == redis.Nil {
 		return fmt.Errorf("данные не найдены в кэше по ключу: %v", key)
@@ -133,6 +162,10 @@ func (c *RedisCache[K, V]) GetSlice(ctx context.Context, key K, dest *[]V) error
 
 // SetString сохраняет строку в Redis с временем жизни (TTL)
 func (c *RedisCache[K, V]) SetString(ctx context.Context, key K, value string, ttl ...time.Duration) error {
+	if c.client == nil {
+		return nil
+	}
+
 	expiration := c.config.DefaultTTL
 	if len(ttl) > 0 {
 		expiration = ttl[0]
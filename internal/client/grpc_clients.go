@@ -2,7 +2,7 @@ package client
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	v1 "TODO/internal/api/v1"
 	"google.golang.org/grpc"
@@ -20,7 +20,7 @@ type APIServiceClientWrapper struct {
 // NewAPIServiceClientWrapper создает новый экземпляр обертки APIServiceClientWrapper
 func NewAPIServiceClientWrapper(conn *grpc.ClientConn) (*APIServiceClientWrapper, error) {
 	if conn == nil {
-		log.Println("gRPC подключение не может быть nil")
+		slog.Error("gRPC подключение не может быть nil")
 		return nil, status.Error(codes.InvalidArgument, "gRPC подключение отсутствует")
 	}
 
@@ -32,7 +32,7 @@ func NewAPIServiceClientWrapper(conn *grpc.ClientConn) (*APIServiceClientWrapper
 func (w *APIServiceClientWrapper) CreateUser(ctx context.Context, req *v1.CreateUserRequest) (*v1.CreateUserResponse, error) {
 	resp, err := w.client.CreateUser(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова CreateUser: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "CreateUser", "error", err)
 		return nil, err
 	}
 	return resp, nil
@@ -42,7 +42,7 @@ func (w *APIServiceClientWrapper) CreateUser(ctx context.Context, req *v1.Create
 func (w *APIServiceClientWrapper) GetUser(ctx context.Context, req *v1.GetUserRequest) (*v1.GetUserResponse, error) {
 	resp, err := w.client.GetUser(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова GetUser: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "GetUser", "error", err)
 		return nil, err
 	}
 	return resp, nil
@@ -53,7 +53,7 @@ func (w *APIServiceClientWrapper) GetAllUsers(ctx context.Context) (*v1.GetAllUs
 	req := &emptypb.Empty{}
 	resp, err := w.client.GetAllUsers(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова GetAllUsers: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "GetAllUsers", "error", err)
 		return nil, err
 	}
 	return resp, nil
@@ -63,10 +63,10 @@ func (w *APIServiceClientWrapper) GetAllUsers(ctx context.Context) (*v1.GetAllUs
 func (w *APIServiceClientWrapper) UpdateUser(ctx context.Context, req *v1.UpdateUserRequest) error {
 	resp, err := w.client.UpdateUser(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова UpdateUser: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "UpdateUser", "error", err)
 		return err
 	}
-	log.Println("Сообщение от сервера:", resp.Message)
+	slog.InfoContext(ctx, "Сообщение от сервера", "message", resp.Message)
 	return nil
 }
 
@@ -74,7 +74,7 @@ func (w *APIServiceClientWrapper) UpdateUser(ctx context.Context, req *v1.Update
 func (w *APIServiceClientWrapper) DeleteUser(ctx context.Context, req *v1.DeleteUserRequest) error {
 	_, err := w.client.DeleteUser(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова DeleteUser: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "DeleteUser", "error", err)
 		return err
 	}
 	return nil
@@ -84,7 +84,7 @@ func (w *APIServiceClientWrapper) DeleteUser(ctx context.Context, req *v1.Delete
 func (w *APIServiceClientWrapper) CreateTask(ctx context.Context, req *v1.CreateTaskRequest) (*v1.CreateTaskResponse, error) {
 	resp, err := w.client.CreateTask(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова CreateTask: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "CreateTask", "error", err)
 		return nil, err
 	}
 	return resp, nil
@@ -94,7 +94,7 @@ func (w *APIServiceClientWrapper) CreateTask(ctx context.Context, req *v1.Create
 func (w *APIServiceClientWrapper) GetTask(ctx context.Context, req *v1.GetTaskRequest) (*v1.GetTaskResponse, error) {
 	resp, err := w.client.GetTask(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова GetTask: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "GetTask", "error", err)
 		return nil, err
 	}
 	return resp, nil
@@ -105,7 +105,7 @@ func (w *APIServiceClientWrapper) GetAllTasks(ctx context.Context) (*v1.GetAllTa
 	req := &emptypb.Empty{} // Пустой запрос для получения всех задач
 	resp, err := w.client.GetAllTasks(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова GetAllTasks: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "GetAllTasks", "error", err)
 		return nil, err
 	}
 	return resp, nil
@@ -115,7 +115,7 @@ func (w *APIServiceClientWrapper) GetAllTasks(ctx context.Context) (*v1.GetAllTa
 func (w *APIServiceClientWrapper) UpdateTask(ctx context.Context, req *v1.UpdateTaskRequest) (*v1.UpdateTaskResponse, error) {
 	resp, err := w.client.UpdateTask(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова UpdateTask: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "UpdateTask", "error", err)
 		return nil, err
 	}
 	return resp, nil
@@ -125,22 +125,82 @@ func (w *APIServiceClientWrapper) UpdateTask(ctx context.Context, req *v1.Update
 func (w *APIServiceClientWrapper) DeleteTask(ctx context.Context, req *v1.DeleteTaskRequest) error {
 	_, err := w.client.DeleteTask(ctx, req)
 	if err != nil {
-		log.Printf("Ошибка вызова DeleteTask: %v", err)
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "DeleteTask", "error", err)
 		return err
 	}
 	return nil
 }
 
+// SearchTasks проксирует запрос к SearchTasks gRPC методу
+func (w *APIServiceClientWrapper) SearchTasks(ctx context.Context, req *v1.SearchTasksRequest) (*v1.SearchTasksResponse, error) {
+	resp, err := w.client.SearchTasks(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "SearchTasks", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RestoreUser проксирует запрос к RestoreUser gRPC методу
+func (w *APIServiceClientWrapper) RestoreUser(ctx context.Context, req *v1.RestoreUserRequest) (*v1.RestoreUserResponse, error) {
+	resp, err := w.client.RestoreUser(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "RestoreUser", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RestoreTask проксирует запрос к RestoreTask gRPC методу
+func (w *APIServiceClientWrapper) RestoreTask(ctx context.Context, req *v1.RestoreTaskRequest) (*v1.RestoreTaskResponse, error) {
+	resp, err := w.client.RestoreTask(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "RestoreTask", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListTrash проксирует запрос к ListTrash gRPC методу
+func (w *APIServiceClientWrapper) ListTrash(ctx context.Context, req *v1.ListTrashRequest) (*v1.ListTrashResponse, error) {
+	resp, err := w.client.ListTrash(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "ListTrash", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetTaskHistory проксирует запрос к GetTaskHistory gRPC методу
+func (w *APIServiceClientWrapper) GetTaskHistory(ctx context.Context, req *v1.GetTaskHistoryRequest) (*v1.GetTaskHistoryResponse, error) {
+	resp, err := w.client.GetTaskHistory(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "GetTaskHistory", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListAuditEvents проксирует запрос к ListAuditEvents gRPC методу
+func (w *APIServiceClientWrapper) ListAuditEvents(ctx context.Context, req *v1.ListAuditEventsRequest) (*v1.ListAuditEventsResponse, error) {
+	resp, err := w.client.ListAuditEvents(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка вызова gRPC", "method", "ListAuditEvents", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
 // Close закрывает соединение gRPC
 func (w *APIServiceClientWrapper) Close() error {
 	if w.conn != nil {
 		if err := w.conn.Close(); err != nil {
-			log.Printf("Ошибка при закрытии gRPC соединения: %v", err)
+			slog.Error("Ошибка при закрытии gRPC соединения", "error", err)
 			return err
 		}
-		log.Println("gRPC соединение закрыто успешно")
+		slog.Debug("gRPC соединение закрыто")
 	} else {
-		log.Println("gRPC соединение уже закрыто или не было установлено")
+		slog.Debug("gRPC соединение уже закрыто или не было установлено")
 	}
 	return nil
 }
@@ -1,15 +1,27 @@
 package config
 
 import (
-	"log"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"TODO/internal/ratelimit"
+)
+
+// Драйверы базы данных пользователей и задач
+const (
+	DBDriverPostgres = "postgres"
+	DBDriverSQLite   = "sqlite"
 )
 
 // Config представляет структуру для конфигурации сервиса
 type Config struct {
-	KafkaBrokers []string // Список брокеров Kafka
+	KafkaBrokers []string // Список брокеров Kafka, пустой список отключает отправку сообщений
 	KafkaGroupID string   // ID группы Kafka
 	KafkaTopic   string   // Топик Kafka
 	DBUser       string   // Пользователь базы данных
@@ -19,16 +31,88 @@ type Config struct {
 	DBPort       string   // Порт базы данных
 	GrpcPort     string   // Порт gRPC
 	HttpPort     string   // Порт HTTP
-	RedisAddr    string   // Адрес Redis
+	PublicURL    string   // Внешний URL HTTP Gateway
+	RedisAddr    string   // Адрес Redis, пустой адрес отключает кэш
 	RedisDB      int      // Номер базы данных Redis
 	MetricsAddr  string   // Адрес сервера метрик
 	TracingURL   string   // URL для экспорта трейсинга (Jaeger или другой провайдер)
 	ServiceName  string   // Название сервиса для трейсинга
+	LogLevel     string   // Уровень журнала: debug, info, warn, error
+	LogFormat    string   // Формат журнала: json или text
+
+	DBDriver   string // Драйвер базы данных: postgres или sqlite
+	SQLitePath string // Файл базы SQLite для драйвера sqlite
+
+	DBDSN              string        // Строка подключения PostgreSQL, заменяет DB_USER, DB_PASSWORD, DB_NAME, DB_HOST, DB_PORT и DB_SSLMODE
+	DBSSLMode          string        // Режим SSL подключения к PostgreSQL: disable, prefer, require, verify-full
+	DBMinConns         int           // Минимальное число соединений в пуле
+	DBMaxConns         int           // Максимальное число соединений в пуле
+	DBMaxConnLifetime  time.Duration // Время жизни соединения, после которого оно закрывается
+	DBMaxConnIdleTime  time.Duration // Время простоя, после которого соединение закрывается
+	DBConnectTimeout   time.Duration // Таймаут установки соединения
+	DBStatementTimeout time.Duration // statement_timeout запросов, 0 — без ограничения
+	DBApplicationName  string        // application_name соединений, виден в pg_stat_activity
+	DBReplicaDSN       string        // Строка подключения к реплике PostgreSQL для чтения, пустая строка отключает реплику
+	DBReplicaPinWindow time.Duration // Сколько после записи чтения того же клиента идут в основную базу
+
+	UserDeleteCascade bool   // Удалять задачи вместе с пользователем, иначе удаление пользователя с задачами отклоняется
+	DBAutoMigrate     bool   // Применять встроенные миграции при запуске сервиса
+	SearchLanguage    string // Конфигурация полнотекстового поиска PostgreSQL: russian, english, simple. Применяется к базе подкомандой migrate up и DB_AUTO_MIGRATE
+
+	GrpcReflection      bool          // Включить gRPC server reflection
+	HealthCheckInterval time.Duration // Интервал фоновых проверок зависимостей
+	HealthCheckTimeout  time.Duration // Таймаут одной проверки зависимости
+
+	GrpcDefaultTimeout time.Duration            // Дедлайн gRPC вызовов по умолчанию
+	GrpcMethodTimeouts map[string]time.Duration // Дедлайны отдельных gRPC методов
+	GrpcTrustedProxies []netip.Prefix           // Адреса HTTP-gateway, от которых gRPC сервер принимает IP адрес клиента, пустой список — ни от кого
+
+	CorsAllowedOrigins []string // Разрешенные источники CORS
+	CorsAllowedMethods []string // Разрешенные методы CORS
+	CorsAllowedHeaders []string // Разрешенные заголовки CORS
+	CorsExposedHeaders []string // Заголовки ответа, доступные браузеру
+	HttpGzip           bool     // Сжатие ответов HTTP Gateway
+	HttpTLSCertFile    string   // Сертификат HTTPS для HTTP Gateway
+	HttpTLSKeyFile     string   // Ключ HTTPS для HTTP Gateway
+
+	GrpcTLSCertFile       string // Сертификат gRPC сервера
+	GrpcTLSKeyFile        string // Ключ gRPC сервера
+	GrpcTLSCAFile         string // CA для проверки сертификатов gRPC (клиентов на сервере и сервера на клиентах)
+	GrpcTLSClientCertFile string // Клиентский сертификат для mTLS между gateway и gRPC
+	GrpcTLSClientKeyFile  string // Ключ клиентского сертификата
+	GrpcTLSServerName     string // Имя сервера для проверки сертификата gRPC
+
+	ApiTokens        []string // API токены вида "token" или "name:token", пустой список отключает проверку
+	ClientApiToken   string   // API токен, с которым интерактивный режим обращается к gRPC серверу
+	WsAllowedClients []string // Клиенты API токенов (name из "name:token"), которым разрешена подписка на задачи по /ws; anonymous — без токенов
+
+	WebhookWorkers        int           // Количество параллельных доставок webhooks
+	WebhookMaxAttempts    int           // Максимальное число попыток доставки события
+	WebhookInitialBackoff time.Duration // Пауза перед повторной доставкой, удваивается с каждой попыткой
+	WebhookMaxBackoff     time.Duration // Максимальная пауза между попытками доставки
+	WebhookTimeout        time.Duration // Таймаут HTTP запроса к подписчику
+	WebhookMaxFailures    int           // Число неудачных доставок подряд, после которого подписка отключается
+
+	RateLimitEnabled bool                       // Включить ограничение частоты вызовов, по умолчанию выключено
+	RateLimitRedis   bool                       // Хранить счетчики в Redis, общие для всех реплик
+	RateLimitDefault ratelimit.Limit            // Ограничение по умолчанию для каждой вызывающей стороны и метода
+	RateLimitMethods map[string]ratelimit.Limit // Ограничения отдельных методов
+	RateLimitCallers map[string]ratelimit.Limit // Ограничения отдельных клиентов API токенов или IP адресов
+
+	TrashRetention     time.Duration // Срок хранения удаленных пользователей и задач в корзине
+	TrashPurgeInterval time.Duration // Интервал фоновой очистки корзины
 }
 
 // LoadConfig загружает конфигурацию из переменных окружения
 func LoadConfig() *Config {
-	kafkaBrokers := getEnvAsSlice("KAFKA_BROKERS", []string{"localhost:9092"})
+	dbDriver := getEnv("DB_DRIVER", DBDriverPostgres)
+	// С драйвером sqlite сервис по умолчанию работает без внешних зависимостей:
+	// Kafka и Redis подключаются, только если их адреса заданы явно
+	defaultKafkaBrokers, defaultRedisAddr := []string{"localhost:9092"}, "localhost:6379"
+	if dbDriver == DBDriverSQLite {
+		defaultKafkaBrokers, defaultRedisAddr = []string{}, ""
+	}
+	kafkaBrokers := getEnvAsSlice("KAFKA_BROKERS", defaultKafkaBrokers)
 	kafkaGroupID := getEnv("KAFKA_GROUP_ID", "notifier_group")
 	kafkaTopic := getEnv("KAFKA_TOPIC", "task-log")
 	dbUser := getEnv("DB_USER", "postgres")
@@ -38,18 +122,66 @@ func LoadConfig() *Config {
 	dbPort := getEnv("DB_PORT", "5432")
 	grpcPort := getEnv("GRPC_PORT", "50051")
 	httpPort := getEnv("HTTP_PORT", "8080")
-	redisAddr := getEnv("REDIS_ADDR", "localhost:6379")
+	publicURL := getEnv("PUBLIC_URL", "http://localhost:"+httpPort)
+	redisAddr := getEnv("REDIS_ADDR", defaultRedisAddr)
 	redisDB := getEnvAsInt("REDIS_DB", 0)
 	metricsAddr := getEnv("METRICS_ADDR", ":8099")
 	tracingURL := getEnv("TRACING_URL", "http://localhost:14268/api/traces")
 	serviceName := getEnv("SERVICE_NAME", "my-go-service")
-
-	log.Printf("Конфигурация загружена: brokers=%v, groupID=%s, topic=%s", kafkaBrokers, kafkaGroupID, kafkaTopic)
-	log.Printf("Настройки базы данных: user=%s, dbname=%s, host=%s, port=%s", dbUser, dbName, dbHost, dbPort)
-	log.Printf("gRPC порт: %s, HTTP порт: %s", grpcPort, httpPort)
-	log.Printf("Redis: addr=%s, db=%d", redisAddr, redisDB)
-	log.Printf("Metrics: addr=%s", metricsAddr)
-	log.Printf("Tracing: url=%s, service=%s", tracingURL, serviceName)
+	logLevel := getEnv("LOG_LEVEL", "info")
+	logFormat := getEnv("LOG_FORMAT", "json")
+	sqlitePath := getEnv("SQLITE_PATH", "todo.db")
+	dbDSN := getEnv("DB_DSN", "")
+	dbSSLMode := getEnv("DB_SSLMODE", "prefer")
+	dbMinConns := getEnvAsInt("DB_MIN_CONNS", 0)
+	dbMaxConns := getEnvAsInt("DB_MAX_CONNS", 20)
+	dbMaxConnLifetime := getEnvAsDuration("DB_MAX_CONN_LIFETIME", time.Hour)
+	dbMaxConnIdleTime := getEnvAsDuration("DB_MAX_CONN_IDLE_TIME", 5*time.Minute)
+	dbConnectTimeout := getEnvAsDuration("DB_CONNECT_TIMEOUT", 10*time.Second)
+	dbStatementTimeout := getEnvAsDuration("DB_STATEMENT_TIMEOUT", 0)
+	dbApplicationName := getEnv("DB_APPLICATION_NAME", "todo")
+	dbReplicaDSN := getEnv("DB_REPLICA_DSN", "")
+	dbReplicaPinWindow := getEnvAsDuration("DB_REPLICA_PIN_WINDOW", 5*time.Second)
+	userDeleteCascade := getEnvAsBool("USER_DELETE_CASCADE", false)
+	dbAutoMigrate := getEnvAsBool("DB_AUTO_MIGRATE", false)
+	searchLanguage := getEnv("SEARCH_LANGUAGE", "russian")
+	grpcReflection := getEnvAsBool("GRPC_REFLECTION", false)
+	healthCheckInterval := getEnvAsDuration("HEALTH_CHECK_INTERVAL", 10*time.Second)
+	healthCheckTimeout := getEnvAsDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second)
+	grpcDefaultTimeout := getEnvAsDuration("GRPC_DEFAULT_TIMEOUT", 30*time.Second)
+	grpcMethodTimeouts := getEnvAsDurationMap("GRPC_METHOD_TIMEOUTS", map[string]time.Duration{})
+	grpcTrustedProxies := getEnvAsPrefixes("GRPC_TRUSTED_PROXIES", []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32"), netip.MustParsePrefix("::1/128")})
+	corsAllowedOrigins := getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"*"})
+	corsAllowedMethods := getEnvAsSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	corsAllowedHeaders := getEnvAsSlice("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization", "If-Match", "X-Api-Token", "Idempotency-Key", "X-Request-Id",
+		"Connect-Protocol-Version", "Connect-Timeout-Ms", "Grpc-Timeout", "X-Grpc-Web", "X-User-Agent"})
+	corsExposedHeaders := getEnvAsSlice("CORS_EXPOSED_HEADERS", []string{"ETag", "Location", "Retry-After", "X-Request-Id",
+		"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"})
+	httpGzip := getEnvAsBool("HTTP_GZIP", true)
+	httpTLSCertFile := getEnv("HTTP_TLS_CERT_FILE", "")
+	httpTLSKeyFile := getEnv("HTTP_TLS_KEY_FILE", "")
+	grpcTLSCertFile := getEnv("GRPC_TLS_CERT_FILE", "")
+	grpcTLSKeyFile := getEnv("GRPC_TLS_KEY_FILE", "")
+	grpcTLSCAFile := getEnv("GRPC_TLS_CA_FILE", "")
+	grpcTLSClientCertFile := getEnv("GRPC_TLS_CLIENT_CERT_FILE", "")
+	grpcTLSClientKeyFile := getEnv("GRPC_TLS_CLIENT_KEY_FILE", "")
+	grpcTLSServerName := getEnv("GRPC_TLS_SERVER_NAME", "localhost")
+	apiTokens := getEnvAsSlice("API_TOKENS", []string{})
+	clientApiToken := getEnv("API_TOKEN", "")
+	wsAllowedClients := getEnvAsSlice("WS_ALLOWED_CLIENTS", []string{})
+	webhookWorkers := getEnvAsInt("WEBHOOK_WORKERS", 4)
+	webhookMaxAttempts := getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5)
+	webhookInitialBackoff := getEnvAsDuration("WEBHOOK_INITIAL_BACKOFF", time.Second)
+	webhookMaxBackoff := getEnvAsDuration("WEBHOOK_MAX_BACKOFF", time.Minute)
+	webhookTimeout := getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second)
+	webhookMaxFailures := getEnvAsInt("WEBHOOK_MAX_FAILURES", 10)
+	rateLimitEnabled := getEnvAsBool("RATE_LIMIT_ENABLED", false)
+	rateLimitRedis := getEnvAsBool("RATE_LIMIT_REDIS", true)
+	rateLimitDefault := getEnvAsLimit("RATE_LIMIT_DEFAULT", ratelimit.Limit{Rate: 20, Burst: 40})
+	rateLimitMethods := getEnvAsLimitMap("RATE_LIMIT_METHODS", map[string]ratelimit.Limit{})
+	rateLimitCallers := getEnvAsLimitMap("RATE_LIMIT_CALLERS", map[string]ratelimit.Limit{})
+	trashRetention := getEnvAsDuration("TRASH_RETENTION", 30*24*time.Hour)
+	trashPurgeInterval := getEnvAsDuration("TRASH_PURGE_INTERVAL", time.Hour)
 
 	return &Config{
 		KafkaBrokers: kafkaBrokers,
@@ -62,14 +194,134 @@ func LoadConfig() *Config {
 		DBPort:       dbPort,
 		GrpcPort:     grpcPort,
 		HttpPort:     httpPort,
+		PublicURL:    publicURL,
 		RedisAddr:    redisAddr,
 		RedisDB:      redisDB,
 		MetricsAddr:  metricsAddr,
 		TracingURL:   tracingURL,
 		ServiceName:  serviceName,
+		LogLevel:     logLevel,
+		LogFormat:    logFormat,
+
+		DBDriver:   dbDriver,
+		SQLitePath: sqlitePath,
+
+		DBDSN:              dbDSN,
+		DBSSLMode:          dbSSLMode,
+		DBMinConns:         dbMinConns,
+		DBMaxConns:         dbMaxConns,
+		DBMaxConnLifetime:  dbMaxConnLifetime,
+		DBMaxConnIdleTime:  dbMaxConnIdleTime,
+		DBConnectTimeout:   dbConnectTimeout,
+		DBStatementTimeout: dbStatementTimeout,
+		DBApplicationName:  dbApplicationName,
+		DBReplicaDSN:       dbReplicaDSN,
+		DBReplicaPinWindow: dbReplicaPinWindow,
+
+		UserDeleteCascade: userDeleteCascade,
+		DBAutoMigrate:     dbAutoMigrate,
+		SearchLanguage:    searchLanguage,
+
+		GrpcReflection:      grpcReflection,
+		HealthCheckInterval: healthCheckInterval,
+		HealthCheckTimeout:  healthCheckTimeout,
+
+		GrpcDefaultTimeout: grpcDefaultTimeout,
+		GrpcMethodTimeouts: grpcMethodTimeouts,
+		GrpcTrustedProxies: grpcTrustedProxies,
+
+		CorsAllowedOrigins: corsAllowedOrigins,
+		CorsAllowedMethods: corsAllowedMethods,
+		CorsAllowedHeaders: corsAllowedHeaders,
+		CorsExposedHeaders: corsExposedHeaders,
+		HttpGzip:           httpGzip,
+		HttpTLSCertFile:    httpTLSCertFile,
+		HttpTLSKeyFile:     httpTLSKeyFile,
+
+		GrpcTLSCertFile:       grpcTLSCertFile,
+		GrpcTLSKeyFile:        grpcTLSKeyFile,
+		GrpcTLSCAFile:         grpcTLSCAFile,
+		GrpcTLSClientCertFile: grpcTLSClientCertFile,
+		GrpcTLSClientKeyFile:  grpcTLSClientKeyFile,
+		GrpcTLSServerName:     grpcTLSServerName,
+
+		ApiTokens:        apiTokens,
+		ClientApiToken:   clientApiToken,
+		WsAllowedClients: wsAllowedClients,
+
+		WebhookWorkers:        webhookWorkers,
+		WebhookMaxAttempts:    webhookMaxAttempts,
+		WebhookInitialBackoff: webhookInitialBackoff,
+		WebhookMaxBackoff:     webhookMaxBackoff,
+		WebhookTimeout:        webhookTimeout,
+		WebhookMaxFailures:    webhookMaxFailures,
+
+		RateLimitEnabled: rateLimitEnabled,
+		RateLimitRedis:   rateLimitRedis,
+		RateLimitDefault: rateLimitDefault,
+		RateLimitMethods: rateLimitMethods,
+		RateLimitCallers: rateLimitCallers,
+
+		TrashRetention:     trashRetention,
+		TrashPurgeInterval: trashPurgeInterval,
 	}
 }
 
+// LogValue описывает конфигурацию в журнале. Пароли, токены и ключи не выводятся.
+func (c *Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Group("kafka", "brokers", c.KafkaBrokers, "group_id", c.KafkaGroupID, "topic", c.KafkaTopic),
+		slog.Group("db", "driver", c.DBDriver, "sqlite_path", c.SQLitePath, "dsn", c.DBDSN != "",
+			"user", c.DBUser, "name", c.DBName, "host", c.DBHost, "port", c.DBPort, "sslmode", c.DBSSLMode,
+			"replica", c.DBReplicaDSN != "", "replica_pin_window", c.DBReplicaPinWindow,
+			"user_delete_cascade", c.UserDeleteCascade, "auto_migrate", c.DBAutoMigrate, "search_language", c.SearchLanguage),
+		slog.Group("db_pool", "min_conns", c.DBMinConns, "max_conns", c.DBMaxConns,
+			"max_conn_lifetime", c.DBMaxConnLifetime, "max_conn_idle_time", c.DBMaxConnIdleTime,
+			"connect_timeout", c.DBConnectTimeout, "statement_timeout", c.DBStatementTimeout, "application_name", c.DBApplicationName),
+		slog.Group("ports", "grpc", c.GrpcPort, "http", c.HttpPort, "public_url", c.PublicURL),
+		slog.Group("redis", "addr", c.RedisAddr, "db", c.RedisDB),
+		slog.String("metrics_addr", c.MetricsAddr),
+		slog.Group("tracing", "url", c.TracingURL, "service", c.ServiceName),
+		slog.Group("log", "level", c.LogLevel, "format", c.LogFormat),
+		slog.Bool("grpc_reflection", c.GrpcReflection),
+		slog.Group("health_check", "interval", c.HealthCheckInterval, "timeout", c.HealthCheckTimeout),
+		slog.Group("grpc_deadlines", "default", c.GrpcDefaultTimeout, "methods", c.GrpcMethodTimeouts),
+		slog.Any("grpc_trusted_proxies", c.GrpcTrustedProxies),
+		slog.Group("cors", "origins", c.CorsAllowedOrigins, "methods", c.CorsAllowedMethods, "headers", c.CorsAllowedHeaders),
+		slog.Group("http", "gzip", c.HttpGzip, "tls", c.HttpTLSCertFile != ""),
+		slog.Group("grpc_tls", "enabled", c.GrpcTLSCertFile != "", "mtls", c.GrpcTLSCAFile != ""),
+		slog.Int("api_tokens", len(c.ApiTokens)),
+		slog.Any("ws_allowed_clients", c.WsAllowedClients),
+		slog.Group("webhooks",
+			"workers", c.WebhookWorkers, "max_attempts", c.WebhookMaxAttempts,
+			"initial_backoff", c.WebhookInitialBackoff, "max_backoff", c.WebhookMaxBackoff,
+			"timeout", c.WebhookTimeout, "max_failures", c.WebhookMaxFailures),
+		slog.Group("rate_limit",
+			"enabled", c.RateLimitEnabled, "redis", c.RateLimitRedis, "default", c.RateLimitDefault.String(),
+			"methods", len(c.RateLimitMethods), "callers", len(c.RateLimitCallers)),
+		slog.Group("trash", "retention", c.TrashRetention, "purge_interval", c.TrashPurgeInterval),
+	)
+}
+
+// PostgresDSN возвращает строку подключения к PostgreSQL: DB_DSN, если она задана,
+// иначе URL из отдельных параметров, в котором имя пользователя и пароль экранированы
+func (c *Config) PostgresDSN() string {
+	if c.DBDSN != "" {
+		return c.DBDSN
+	}
+
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.DBUser, c.DBPassword),
+		Host:   net.JoinHostPort(c.DBHost, c.DBPort),
+		Path:   "/" + c.DBName,
+	}
+	if c.DBSSLMode != "" {
+		dsn.RawQuery = url.Values{"sslmode": {c.DBSSLMode}}.Encode()
+	}
+	return dsn.String()
+}
+
 // getEnv возвращает значение переменной окружения или значение по умолчанию
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -84,12 +336,123 @@ func getEnvAsInt(key string, fallback int) int {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue
 		} else {
-			log.Printf("Ошибка при преобразовании переменной окружения %s: %v", key, err)
+			slog.Warn("Некорректное значение переменной окружения", "key", key, "error", err)
+		}
+	}
+	return fallback
+}
+
+// getEnvAsBool возвращает значение переменной окружения как bool или значение по умолчанию
+func getEnvAsBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		} else {
+			slog.Warn("Некорректное значение переменной окружения", "key", key, "error", err)
+		}
+	}
+	return fallback
+}
+
+// getEnvAsDuration возвращает значение переменной окружения как time.Duration или значение по умолчанию
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		} else {
+			slog.Warn("Некорректное значение переменной окружения", "key", key, "error", err)
 		}
 	}
 	return fallback
 }
 
+// getEnvAsDurationMap возвращает значение переменной окружения вида "key=1s,other=500ms"
+// как словарь длительностей или значение по умолчанию
+func getEnvAsDurationMap(key string, fallback map[string]time.Duration) map[string]time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists || strings.TrimSpace(value) == "" {
+		return fallback
+	}
+
+	result := make(map[string]time.Duration)
+	for _, pair := range splitAndTrim(value, ",") {
+		name, rawDuration, ok := strings.Cut(pair, "=")
+		if !ok {
+			slog.Warn("Некорректное значение переменной окружения, ожидается формат key=duration", "key", key, "value", pair)
+			continue
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(rawDuration))
+		if err != nil {
+			slog.Warn("Некорректное значение переменной окружения", "key", key, "error", err)
+			continue
+		}
+		result[strings.TrimSpace(name)] = duration
+	}
+	return result
+}
+
+// getEnvAsLimit возвращает значение переменной окружения вида "rate:burst"
+// как ограничение частоты или значение по умолчанию
+func getEnvAsLimit(key string, fallback ratelimit.Limit) ratelimit.Limit {
+	if value, exists := os.LookupEnv(key); exists {
+		if limit, err := ratelimit.ParseLimit(value); err == nil {
+			return limit
+		} else {
+			slog.Warn("Некорректное значение переменной окружения", "key", key, "error", err)
+		}
+	}
+	return fallback
+}
+
+// getEnvAsLimitMap возвращает значение переменной окружения вида "key=10:20,other=1:5"
+// как словарь ограничений частоты или значение по умолчанию
+func getEnvAsLimitMap(key string, fallback map[string]ratelimit.Limit) map[string]ratelimit.Limit {
+	value, exists := os.LookupEnv(key)
+	if !exists || strings.TrimSpace(value) == "" {
+		return fallback
+	}
+
+	result := make(map[string]ratelimit.Limit)
+	for _, pair := range splitAndTrim(value, ",") {
+		name, rawLimit, ok := strings.Cut(pair, "=")
+		if !ok {
+			slog.Warn("Некорректное значение переменной окружения, ожидается формат key=rate:burst", "key", key, "value", pair)
+			continue
+		}
+		limit, err := ratelimit.ParseLimit(rawLimit)
+		if err != nil {
+			slog.Warn("Некорректное значение переменной окружения", "key", key, "error", err)
+			continue
+		}
+		result[strings.TrimSpace(name)] = limit
+	}
+	return result
+}
+
+// getEnvAsPrefixes возвращает значение переменной окружения вида "10.0.0.0/8,127.0.0.1"
+// как список подсетей или значение по умолчанию. Отдельный адрес означает подсеть из одного адреса.
+func getEnvAsPrefixes(key string, fallback []netip.Prefix) []netip.Prefix {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+
+	result := make([]netip.Prefix, 0)
+	for _, item := range splitAndTrim(value, ",") {
+		if addr, err := netip.ParseAddr(item); err == nil {
+			result = append(result, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			slog.Warn("Некорректное значение переменной окружения, ожидается адрес или подсеть", "key", key, "value", item)
+			continue
+		}
+		result = append(result, prefix.Masked())
+	}
+	return result
+}
+
 // getEnvAsSlice возвращает значение переменной окружения как срез строк или значение по умолчанию
 func getEnvAsSlice(key string, fallback []string) []string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -98,11 +461,13 @@ func getEnvAsSlice(key string, fallback []string) []string {
 	return fallback
 }
 
-// splitAndTrim разбивает строку по разделителю и удаляет пробелы
+// splitAndTrim разбивает строку по разделителю, удаляет пробелы и пропускает пустые элементы
 func splitAndTrim(str, sep string) []string {
-	parts := strings.Split(str, sep)
-	for i := range parts {
-		parts[i] = strings.TrimSpace(parts[i])
+	parts := make([]string, 0)
+	for _, part := range strings.Split(str, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
 	}
 	return parts
 }
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"TODO/internal/model"
+	"TODO/internal/service"
+	"TODO/internal/tracing"
+	"context"
+	"fmt"
+)
+
+// GetTaskHistory получает историю изменений задачи с трассировкой.
+func GetTaskHistory(ctx context.Context, auditService *service.AuditService, taskID int64, limit, offset int) ([]model.AuditEvent, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "GetTaskHistory")
+	defer span.End()
+
+	events, err := auditService.GetTaskHistory(ctx, taskID, limit, offset)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка получения истории задачи с ID %d: %w", taskID, err)
+	}
+
+	span.AddEvent("История задачи успешно получена")
+	return events, nil
+}
+
+// ListAuditEvents получает записи журнала аудита по фильтру с трассировкой.
+func ListAuditEvents(ctx context.Context, auditService *service.AuditService, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "ListAuditEvents")
+	defer span.End()
+
+	events, err := auditService.ListAuditEvents(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка получения журнала аудита: %w", err)
+	}
+
+	span.AddEvent("Журнал аудита успешно получен")
+	return events, nil
+}
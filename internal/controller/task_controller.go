@@ -1,38 +1,48 @@
 package controller
 
 import (
+	"TODO/internal/dao"
 	"TODO/internal/metrics"
 	"TODO/internal/model"
 	"TODO/internal/service"
 	"TODO/internal/tracing"
 	"context"
+	"errors"
 	"fmt"
 )
 
 // CreateTask создает новую задачу с проверкой существования пользователя и трассировкой.
-func CreateTask(ctx context.Context, taskService *service.TaskService, userService *service.UserService, userID int64, title, note string) (int64, error) {
+func CreateTask(ctx context.Context, taskService *service.TaskService, userService *service.UserService, userID int64, title, note string) (*model.Task, error) {
 	ctx, span := tracing.GetTracer().Start(ctx, "CreateTask")
 	defer span.End()
 
 	span.AddEvent("Начинаем создание задачи")
 
-	_, err := userService.GetUserByID(ctx, userID)
+	// Проверка пользователя и создание задачи выполняются в одной транзакции,
+	// чтобы пользователь не был удален между ними
+	var task *model.Task
+	err := taskService.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := userService.GetUserByID(ctx, userID); err != nil {
+			return fmt.Errorf("пользователь с ID %d не найден: %w", userID, err)
+		}
+
+		var err error
+		task, err = taskService.CreateTask(ctx, userID, title, note)
+		if err != nil {
+			return fmt.Errorf("ошибка при создании задачи: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
 		span.RecordError(err)
-		return 0, fmt.Errorf("пользователь с ID %d не найден: %w", userID, err)
-	}
-
-	taskID, err := taskService.CreateTask(ctx, userID, title, note)
-	if err != nil {
-		span.RecordError(err)
-		return 0, fmt.Errorf("ошибка при создании задачи: %w", err)
+		return nil, err
 	}
 
 	metrics.IncrementTaskCreated("created")
 
 	span.AddEvent("Задача успешно создана")
 
-	return taskID, nil
+	return task, nil
 }
 
 // GetTask получает задачу по ID с трассировкой.
@@ -69,8 +79,42 @@ func GetAllTasks(ctx context.Context, taskService *service.TaskService) ([]model
 	return tasks, nil
 }
 
+// ListTasks получает задачи по фильтру с трассировкой.
+func ListTasks(ctx context.Context, taskService *service.TaskService, filter model.TaskFilter) ([]model.Task, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "ListTasks")
+	defer span.End()
+
+	span.AddEvent("Начинаем получение списка задач")
+
+	tasks, err := taskService.ListTasks(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка получения списка задач: %w", err)
+	}
+
+	span.AddEvent("Список задач успешно получен")
+	return tasks, nil
+}
+
+// SearchTasks выполняет полнотекстовый поиск задач с трассировкой.
+func SearchTasks(ctx context.Context, taskService *service.TaskService, search model.TaskSearch) ([]model.TaskSearchResult, int64, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "SearchTasks")
+	defer span.End()
+
+	span.AddEvent("Начинаем поиск задач")
+
+	results, total, err := taskService.SearchTasks(ctx, search)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, fmt.Errorf("ошибка поиска задач: %w", err)
+	}
+
+	span.AddEvent("Поиск задач успешно выполнен")
+	return results, total, nil
+}
+
 // UpdateTask обновляет задачу с проверкой существования и трассировкой.
-func UpdateTask(ctx context.Context, taskService *service.TaskService, taskID int64, title, note string, done bool) error {
+func UpdateTask(ctx context.Context, taskService *service.TaskService, taskID int64, title, note string, done bool, expectedVersion int64) (*model.Task, error) {
 	ctx, span := tracing.GetTracer().Start(ctx, "UpdateTask")
 	defer span.End()
 
@@ -79,21 +123,21 @@ func UpdateTask(ctx context.Context, taskService *service.TaskService, taskID in
 	_, err := taskService.GetTask(ctx, taskID)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("задача с ID %d не найдена: %w", taskID, err)
+		return nil, fmt.Errorf("задача с ID %d не найдена: %w", taskID, err)
 	}
 
-	err = taskService.UpdateTask(ctx, taskID, title, note, done)
+	task, err := taskService.UpdateTask(ctx, taskID, title, note, done, expectedVersion)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("ошибка обновления задачи с ID %d: %w", taskID, err)
+		return nil, fmt.Errorf("ошибка обновления задачи с ID %d: %w", taskID, err)
 	}
 
 	span.AddEvent("Задача успешно обновлена")
-	return nil
+	return task, nil
 }
 
 // DeleteTask удаляет задачу с проверкой существования и трассировкой.
-func DeleteTask(ctx context.Context, taskService *service.TaskService, taskID int64) error {
+func DeleteTask(ctx context.Context, taskService *service.TaskService, taskID, expectedVersion int64) error {
 	ctx, span := tracing.GetTracer().Start(ctx, "DeleteTask")
 	defer span.End()
 
@@ -105,7 +149,7 @@ func DeleteTask(ctx context.Context, taskService *service.TaskService, taskID in
 		return fmt.Errorf("задача с ID %d не найдена: %w", taskID, err)
 	}
 
-	err = taskService.DeleteTask(ctx, taskID)
+	err = taskService.DeleteTask(ctx, taskID, expectedVersion)
 	if err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("ошибка удаления задачи с ID %d: %w", taskID, err)
@@ -114,3 +158,60 @@ func DeleteTask(ctx context.Context, taskService *service.TaskService, taskID in
 	span.AddEvent("Задача успешно удалена")
 	return nil
 }
+
+// RestoreTask восстанавливает задачу из корзины с проверкой владельца и трассировкой.
+// Задача владельца, находящегося в корзине, не восстанавливается (ErrOwnerDeleted).
+func RestoreTask(ctx context.Context, taskService *service.TaskService, userService *service.UserService, taskID int64) (*model.Task, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "RestoreTask")
+	defer span.End()
+
+	span.AddEvent("Начинаем восстановление задачи")
+
+	var task *model.Task
+	err := taskService.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		task, err = taskService.RestoreTask(ctx, taskID)
+		if err != nil {
+			return fmt.Errorf("ошибка восстановления задачи с ID %d: %w", taskID, err)
+		}
+
+		if _, err := userService.GetUserByID(ctx, task.UserID); err != nil {
+			if errors.Is(err, dao.ErrNotFound) {
+				return fmt.Errorf("пользователь с ID %d находится в корзине: %w", task.UserID, dao.ErrOwnerDeleted)
+			}
+			return fmt.Errorf("ошибка проверки владельца задачи с ID %d: %w", taskID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.AddEvent("Задача успешно восстановлена")
+	return task, nil
+}
+
+// ListTrash получает содержимое корзины с трассировкой. Если userID больше нуля,
+// возвращаются только пользователь с этим ID и его задачи.
+func ListTrash(ctx context.Context, taskService *service.TaskService, userService *service.UserService, userID int64) (*model.Trash, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "ListTrash")
+	defer span.End()
+
+	span.AddEvent("Начинаем получение корзины")
+
+	users, err := userService.ListDeletedUsers(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка получения корзины: %w", err)
+	}
+
+	tasks, err := taskService.ListDeletedTasks(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка получения корзины: %w", err)
+	}
+
+	span.AddEvent("Корзина успешно получена")
+	return &model.Trash{Users: users, Tasks: tasks}, nil
+}
@@ -9,21 +9,21 @@ import (
 )
 
 // CreateUser создает нового пользователя с трассировкой.
-func CreateUser(ctx context.Context, userService *service.UserService, username string) (int64, error) {
+func CreateUser(ctx context.Context, userService *service.UserService, username string) (*model.User, error) {
 	ctx, span := tracing.GetTracer().Start(ctx, "CreateUser")
 	defer span.End()
 
 	span.AddEvent("Начинаем создание пользователя")
 
-	userID, err := userService.CreateUser(ctx, username)
+	user, err := userService.CreateUser(ctx, username)
 	if err != nil {
 		span.RecordError(err)
-		return 0, fmt.Errorf("ошибка создания пользователя: %w", err)
+		return nil, fmt.Errorf("ошибка создания пользователя: %w", err)
 	}
 
 	span.AddEvent("Пользователь успешно создан")
 
-	return userID, nil
+	return user, nil
 }
 
 // GetUserByID возвращает пользователя по его ID с трассировкой.
@@ -63,7 +63,7 @@ func GetAllUsers(ctx context.Context, userService *service.UserService) ([]model
 }
 
 // UpdateUser обновляет существующего пользователя с трассировкой.
-func UpdateUser(ctx context.Context, userService *service.UserService, userID int64, username string) error {
+func UpdateUser(ctx context.Context, userService *service.UserService, userID int64, username string, expectedVersion int64) (*model.User, error) {
 	ctx, span := tracing.GetTracer().Start(ctx, "UpdateUser")
 	defer span.End()
 
@@ -72,18 +72,18 @@ func UpdateUser(ctx context.Context, userService *service.UserService, userID in
 	_, err := userService.GetUserByID(ctx, userID)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("пользователь с ID %d не найден: %w", userID, err)
+		return nil, fmt.Errorf("пользователь с ID %d не найден: %w", userID, err)
 	}
 
-	err = userService.UpdateUser(ctx, userID, username)
+	user, err := userService.UpdateUser(ctx, userID, username, expectedVersion)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("ошибка обновления пользователя с ID %d: %w", userID, err)
+		return nil, fmt.Errorf("ошибка обновления пользователя с ID %d: %w", userID, err)
 	}
 
 	span.AddEvent("Пользователь успешно обновлен")
 
-	return nil
+	return user, nil
 }
 
 // DeleteUser удаляет пользователя по его ID с трассировкой.
@@ -109,3 +109,21 @@ func DeleteUser(ctx context.Context, userService *service.UserService, userID in
 
 	return nil
 }
+
+// RestoreUser восстанавливает пользователя из корзины с трассировкой.
+func RestoreUser(ctx context.Context, userService *service.UserService, userID int64) (*model.User, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "RestoreUser")
+	defer span.End()
+
+	span.AddEvent("Начинаем восстановление пользователя")
+
+	user, err := userService.RestoreUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка восстановления пользователя с ID %d: %w", userID, err)
+	}
+
+	span.AddEvent("Пользователь успешно восстановлен")
+
+	return user, nil
+}
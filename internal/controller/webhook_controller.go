@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"TODO/internal/model"
+	"TODO/internal/service"
+	"TODO/internal/tracing"
+	"context"
+	"fmt"
+)
+
+// CreateWebhook создает подписку на события задач с трассировкой.
+func CreateWebhook(ctx context.Context, webhookService *service.WebhookService, webhook model.Webhook) (*model.Webhook, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "CreateWebhook")
+	defer span.End()
+
+	created, err := webhookService.CreateWebhook(ctx, webhook)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка при создании подписки: %w", err)
+	}
+
+	span.AddEvent("Подписка успешно создана")
+	return created, nil
+}
+
+// GetWebhook получает подписку по ID с трассировкой.
+func GetWebhook(ctx context.Context, webhookService *service.WebhookService, webhookID int64) (*model.Webhook, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "GetWebhook")
+	defer span.End()
+
+	webhook, err := webhookService.GetWebhook(ctx, webhookID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка получения подписки с ID %d: %w", webhookID, err)
+	}
+
+	return webhook, nil
+}
+
+// GetAllWebhooks получает все подписки с трассировкой.
+func GetAllWebhooks(ctx context.Context, webhookService *service.WebhookService) ([]model.Webhook, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "GetAllWebhooks")
+	defer span.End()
+
+	webhooks, err := webhookService.GetAllWebhooks(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка получения подписок: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook обновляет подписку с трассировкой.
+func UpdateWebhook(ctx context.Context, webhookService *service.WebhookService, webhook model.Webhook) (*model.Webhook, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "UpdateWebhook")
+	defer span.End()
+
+	updated, err := webhookService.UpdateWebhook(ctx, webhook)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка обновления подписки с ID %d: %w", webhook.ID, err)
+	}
+
+	span.AddEvent("Подписка успешно обновлена")
+	return updated, nil
+}
+
+// DeleteWebhook удаляет подписку с трассировкой.
+func DeleteWebhook(ctx context.Context, webhookService *service.WebhookService, webhookID int64) error {
+	ctx, span := tracing.GetTracer().Start(ctx, "DeleteWebhook")
+	defer span.End()
+
+	if err := webhookService.DeleteWebhook(ctx, webhookID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("ошибка удаления подписки с ID %d: %w", webhookID, err)
+	}
+
+	span.AddEvent("Подписка успешно удалена")
+	return nil
+}
+
+// GetWebhookDeliveries получает журнал доставок подписки с трассировкой.
+func GetWebhookDeliveries(ctx context.Context, webhookService *service.WebhookService, webhookID int64, limit, offset int) ([]model.WebhookDelivery, error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "GetWebhookDeliveries")
+	defer span.End()
+
+	deliveries, err := webhookService.GetWebhookDeliveries(ctx, webhookID, limit, offset)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ошибка получения доставок подписки с ID %d: %w", webhookID, err)
+	}
+
+	return deliveries, nil
+}
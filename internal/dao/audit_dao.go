@@ -0,0 +1,82 @@
+package dao
+
+import (
+	"TODO/internal/auth"
+	"TODO/internal/model"
+	"TODO/internal/requestid"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// auditActor возвращает вызывающую сторону для журнала аудита: имя клиента API токена,
+// anonymous для вызовов без имени и system для фоновых задач вне запросов
+func auditActor(ctx context.Context) string {
+	principal, ok := auth.FromContext(ctx)
+	switch {
+	case !ok:
+		return "system"
+	case principal.Name == "":
+		return "anonymous"
+	}
+	return principal.Name
+}
+
+// beginAuditedTransaction начинает транзакцию изменения пользователей или задач и передает
+// триггерам аудита вызывающую сторону и ID запроса. Настройки действуют до конца транзакции.
+func beginAuditedTransaction(ctx context.Context, tm *TransactionManager, isoLevel pgx.TxIsoLevel) (pgx.Tx, *pgxpool.Conn, error) {
+	tx, conn, err := tm.BeginTransaction(ctx, isoLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = tx.Exec(ctx, `SELECT set_config('audit.actor', $1, true), set_config('audit.request_id', $2, true)`,
+		auditActor(ctx), requestid.FromContext(ctx))
+	if err != nil {
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+		}
+		return nil, nil, fmt.Errorf("ошибка передачи контекста аудита: %w", err)
+	}
+
+	return tx, conn, nil
+}
+
+// ListAuditEvents извлекает записи журнала аудита, подходящие под фильтр, последние первыми.
+func ListAuditEvents(ctx context.Context, filter model.AuditFilter, pool *pgxpool.Pool) ([]model.AuditEvent, error) {
+	rows, err := pool.Query(ctx,
+		`SELECT id, entity_type, entity_id, operation, actor, request_id, before, after, diff, created_at
+		 FROM audit_events
+		 WHERE ($1 = '' OR entity_type = $1)
+		   AND ($2::BIGINT = 0 OR entity_id = $2)
+		   AND ($3 = '' OR actor = $3)
+		   AND ($4 = '' OR operation = $4)
+		   AND ($5 = '' OR request_id = $5)
+		   AND ($6::TIMESTAMPTZ IS NULL OR created_at >= $6)
+		   AND ($7::TIMESTAMPTZ IS NULL OR created_at < $7)
+		 ORDER BY id DESC LIMIT $8 OFFSET $9`,
+		filter.EntityType, filter.EntityID, filter.Actor, filter.Operation, filter.RequestID,
+		filter.Since, filter.Until, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения журнала аудита: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]model.AuditEvent, 0)
+	for rows.Next() {
+		var event model.AuditEvent
+		err := rows.Scan(&event.ID, &event.EntityType, &event.EntityID, &event.Operation, &event.Actor, &event.RequestID,
+			&event.Before, &event.After, &event.Diff, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования записи журнала аудита: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по строкам журнала аудита: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,83 @@
+package dao_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"TODO/internal/auth"
+	"TODO/internal/dao"
+	"TODO/internal/model"
+	"TODO/internal/requestid"
+)
+
+// Триггеры записывают в журнал аудита каждое изменение задачи вместе с вызывающей стороной и ID запроса
+func TestAuditEvents(t *testing.T) {
+	pool := testPool(t)
+	users := dao.NewPgUserRepository(pool, nil, false, discardLog)
+	tasks := dao.NewPgTaskRepository(pool, nil, discardLog)
+
+	ctx := auth.NewContext(context.Background(), auth.Principal{Name: "dashboard"})
+	ctx = requestid.NewContext(ctx, requestid.New())
+	now := time.Now().UTC().Truncate(time.Second)
+
+	user, err := users.CreateUser(ctx, model.User{Username: uniqueUsername("audit"), CreatedAt: now})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	task, err := tasks.CreateTask(ctx, model.Task{UserID: user.ID, Title: "купить", Note: "молоко", CreatedAt: now, UpdatedAt: now})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	task.Title = "продать"
+	if task.Version, err = tasks.UpdateTask(ctx, *task); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if _, err := tasks.DeleteTask(ctx, task.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+
+	events, err := dao.ListAuditEvents(ctx, model.AuditFilter{EntityType: model.AuditEntityTask, EntityID: task.ID, Limit: 10}, pool)
+	if err != nil {
+		t.Fatalf("ListAuditEvents: %v", err)
+	}
+	wantOperations := []string{"delete", "update", "create"}
+	if len(events) != len(wantOperations) {
+		t.Fatalf("записей журнала %d, ожидалось %d: %+v", len(events), len(wantOperations), events)
+	}
+	for i, event := range events {
+		if event.Operation != wantOperations[i] {
+			t.Errorf("запись %d: операция %q, ожидалась %q", i, event.Operation, wantOperations[i])
+		}
+		if event.Actor != "dashboard" || event.RequestID != requestid.FromContext(ctx) {
+			t.Errorf("запись %d: actor %q, request_id %q, ожидались dashboard и ID запроса", i, event.Actor, event.RequestID)
+		}
+	}
+
+	var diff map[string]struct {
+		Old string `json:"old"`
+		New string `json:"new"`
+	}
+	if err := json.Unmarshal(events[1].Diff, &diff); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if diff["title"].Old != "купить" || diff["title"].New != "продать" {
+		t.Errorf("diff обновления = %s, ожидалось изменение title", events[1].Diff)
+	}
+	if events[2].Before != nil {
+		t.Errorf("before при создании = %s, ожидалось пустое значение", events[2].Before)
+	}
+
+	// Фоновые задачи вне запросов записываются от имени system
+	if _, err := tasks.RestoreTask(context.Background(), task.ID); err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	events, err = dao.ListAuditEvents(ctx, model.AuditFilter{EntityType: model.AuditEntityTask, EntityID: task.ID, Operation: "restore", Limit: 10}, pool)
+	if err != nil {
+		t.Fatalf("ListAuditEvents: %v", err)
+	}
+	if len(events) != 1 || events[0].Actor != "system" {
+		t.Errorf("записи восстановления = %+v, ожидалась одна от system", events)
+	}
+}
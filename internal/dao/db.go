@@ -2,40 +2,109 @@ package dao
 
 import (
 	"context"
-	"fmt"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"log"
+	"log/slog"
+	"os"
+	"strconv"
 	"time"
 )
 
 var pool *pgxpool.Pool
 
-// InitDB инициализирует пул соединений к базе данных PostgreSQL
-func Initdb(user, password, dbname, host string, port int) {
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%d/%s", user, password, host, port, dbname)
+// replicaPool пул соединений к реплике для чтения, nil — реплика не настроена
+var replicaPool *pgxpool.Pool
 
-	config, err := pgxpool.ParseConfig(connStr)
+// PoolSettings параметры пулов соединений PostgreSQL
+type PoolSettings struct {
+	MinConns         int32         // Минимальное число соединений в пуле
+	MaxConns         int32         // Максимальное число соединений в пуле
+	MaxConnLifetime  time.Duration // Время жизни соединения
+	MaxConnIdleTime  time.Duration // Время простоя, после которого соединение закрывается
+	ConnectTimeout   time.Duration // Таймаут установки соединения
+	StatementTimeout time.Duration // statement_timeout запросов, 0 — без ограничения
+	ApplicationName  string        // application_name соединений, если он не задан в строке подключения
+}
+
+// poolConfig разбирает строку подключения dsn и применяет к ней параметры пула.
+// Нулевые параметры не меняют значения из строки подключения и значения по умолчанию pgxpool.
+func poolConfig(dsn string, settings PoolSettings) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
-		log.Fatalf("Ошибка парсинга конфигурации подключения: %v\n", err)
+		return nil, err
 	}
 
-	config.MaxConns = 20
-	config.MaxConnIdleTime = 5 * time.Minute
-	config.ConnConfig.ConnectTimeout = 10 * time.Second
+	if settings.MinConns > 0 {
+		config.MinConns = settings.MinConns
+	}
+	if settings.MaxConns > 0 {
+		config.MaxConns = settings.MaxConns
+	}
+	if settings.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = settings.MaxConnLifetime
+	}
+	if settings.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = settings.MaxConnIdleTime
+	}
+	if settings.ConnectTimeout > 0 {
+		config.ConnConfig.ConnectTimeout = settings.ConnectTimeout
+	}
+	if settings.StatementTimeout > 0 {
+		config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(settings.StatementTimeout.Milliseconds(), 10)
+	}
+	if settings.ApplicationName != "" && config.ConnConfig.RuntimeParams["application_name"] == "" {
+		config.ConnConfig.RuntimeParams["application_name"] = settings.ApplicationName
+	}
+	return config, nil
+}
 
+// InitDB инициализирует пул соединений к базе данных PostgreSQL по строке подключения dsn
+// (URL postgres:// или строка вида "host=... user=...")
+func Initdb(dsn string, settings PoolSettings, log *slog.Logger) {
+	config, err := poolConfig(dsn, settings)
+	if err != nil {
+		log.Error("Ошибка парсинга конфигурации подключения", "error", err)
+		os.Exit(1)
+	}
+
+	host, port, dbname := config.ConnConfig.Host, config.ConnConfig.Port, config.ConnConfig.Database
 	pool, err = pgxpool.ConnectConfig(context.Background(), config)
 	if err != nil {
-		log.Fatalf("Ошибка подключения к базе данных: %v\n", err)
+		log.Error("Ошибка подключения к базе данных", "host", host, "port", port, "dbname", dbname, "error", err)
+		os.Exit(1)
 	} else {
-		log.Println("Успешное подключение к базе данных с использованием пула.")
+		log.Info("Успешное подключение к базе данных с использованием пула", "host", host, "port", port, "dbname", dbname,
+			"min_conns", config.MinConns, "max_conns", config.MaxConns)
 	}
 }
 
-// CloseDB закрывает пул соединений
-func Closedb() {
+// InitReplica инициализирует пул соединений к реплике PostgreSQL по строке подключения dsn.
+// Соединения открываются при первом запросе, поэтому недоступная при запуске реплика
+// не мешает работе сервиса: чтения выполняются на основной базе.
+func InitReplica(dsn string, settings PoolSettings, log *slog.Logger) {
+	config, err := poolConfig(dsn, settings)
+	if err != nil {
+		log.Error("Ошибка парсинга конфигурации подключения к реплике", "error", err)
+		os.Exit(1)
+	}
+	config.LazyConnect = true
+
+	replicaPool, err = pgxpool.ConnectConfig(context.Background(), config)
+	if err != nil {
+		log.Error("Ошибка подключения к реплике базы данных", "host", config.ConnConfig.Host, "error", err)
+		os.Exit(1)
+	}
+	log.Info("Пул соединений к реплике базы данных создан", "host", config.ConnConfig.Host, "port", config.ConnConfig.Port, "dbname", config.ConnConfig.Database)
+}
+
+// CloseDB закрывает пулы соединений
+func Closedb(log *slog.Logger) {
+	if replicaPool != nil {
+		replicaPool.Close()
+		log.Info("Пул соединений к реплике закрыт")
+	}
 	if pool != nil {
 		pool.Close()
-		log.Println("Пул соединений закрыт.")
+		log.Info("Пул соединений закрыт")
 	}
 }
 
@@ -43,3 +112,8 @@ func Closedb() {
 func GetPool() *pgxpool.Pool {
 	return pool
 }
+
+// GetReplicaPool возвращает пул соединений к реплике или nil, если реплика не настроена
+func GetReplicaPool() *pgxpool.Pool {
+	return replicaPool
+}
@@ -0,0 +1,35 @@
+package dao
+
+import (
+	"errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Коды SQLSTATE, которые DAO преобразует в собственные ошибки
+const (
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+)
+
+// ErrVersionConflict возвращается, когда версия записи в базе не совпадает с ожидаемой клиентом.
+var ErrVersionConflict = errors.New("версия записи не совпадает с ожидаемой")
+
+// ErrNotFound возвращается, когда запись не найдена. Совпадает с pgx.ErrNoRows,
+// поэтому вызывающий код одинаково обрабатывает ошибки всех реализаций репозиториев.
+var ErrNotFound = pgx.ErrNoRows
+
+// ErrUserHasTasks возвращается при удалении пользователя, у которого остались задачи, без каскадного удаления.
+var ErrUserHasTasks = errors.New("у пользователя есть задачи")
+
+// ErrOwnerDeleted возвращается при восстановлении задачи, владелец которой находится в корзине.
+var ErrOwnerDeleted = errors.New("владелец задачи удален")
+
+// ErrUsernameTaken возвращается, когда имя пользователя уже занято.
+var ErrUsernameTaken = errors.New("имя пользователя уже занято")
+
+// hasSQLState проверяет, что ошибка PostgreSQL имеет заданный код SQLSTATE
+func hasSQLState(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
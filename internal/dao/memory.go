@@ -0,0 +1,562 @@
+package dao
+
+import (
+	"TODO/internal/model"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	_ TaskRepository = (*MemoryTaskRepository)(nil)
+	_ UserRepository = (*MemoryUserRepository)(nil)
+	_ Transactor     = (*MemoryTransactor)(nil)
+)
+
+// MemoryTransactor выполняет единицы работы над хранилищами в памяти по одной,
+// поэтому они не пересекаются между собой. Если fn возвращает ошибку, хранилища возвращаются
+// к состоянию на начало единицы работы, как при откате транзакции, вместе с изменениями,
+// сделанными за это время вне единиц работы.
+type MemoryTransactor struct {
+	mu    sync.Mutex
+	tasks *MemoryTaskRepository
+	users *MemoryUserRepository
+}
+
+// NewMemoryTransactor создает исполнитель единиц работы над хранилищами tasks и users.
+// Любое из хранилищ может быть nil, тогда его изменения не откатываются.
+func NewMemoryTransactor(tasks *MemoryTaskRepository, users *MemoryUserRepository) *MemoryTransactor {
+	return &MemoryTransactor{tasks: tasks, users: users}
+}
+
+func (t *MemoryTransactor) WithinTx(ctx context.Context, _ pgx.TxOptions, fn func(ctx context.Context) error) error {
+	if InTx(ctx) {
+		return fn(ctx)
+	}
+
+	t.mu.Lock()
+	var restore []func()
+	if t.tasks != nil {
+		restore = append(restore, t.tasks.snapshot())
+	}
+	if t.users != nil {
+		restore = append(restore, t.users.snapshot())
+	}
+
+	uow := &unitOfWork{}
+	err := fn(context.WithValue(ctx, txKey{}, uow))
+	if err != nil {
+		for _, rollback := range restore {
+			rollback()
+		}
+	}
+	t.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	uow.commit()
+	return nil
+}
+
+// MemoryTaskRepository потокобезопасное хранилище задач в памяти процесса.
+// Повторяет поведение PgTaskRepository и используется для тестов сервисов без PostgreSQL.
+type MemoryTaskRepository struct {
+	mu     sync.RWMutex
+	tasks  map[int64]model.Task
+	nextID int64
+}
+
+// NewMemoryTaskRepository создает пустое хранилище задач в памяти
+func NewMemoryTaskRepository() *MemoryTaskRepository {
+	return &MemoryTaskRepository{tasks: make(map[int64]model.Task)}
+}
+
+// snapshot копирует задачи и возвращает функцию, которая возвращает хранилище к этой копии.
+// Счетчик ID не откатывается, как и последовательности PostgreSQL.
+func (r *MemoryTaskRepository) snapshot() func() {
+	r.mu.RLock()
+	tasks := maps.Clone(r.tasks)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.tasks = tasks
+		r.mu.Unlock()
+	}
+}
+
+func (r *MemoryTaskRepository) CreateTask(_ context.Context, task model.Task) (*model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	task.ID = r.nextID
+	task.Version = 1
+	r.tasks[task.ID] = task
+	return &task, nil
+}
+
+func (r *MemoryTaskRepository) GetTaskByID(_ context.Context, taskID int64) (*model.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[taskID]
+	if !ok || task.DeletedAt != nil {
+		return nil, fmt.Errorf("ошибка получения задачи с ID %d: %w", taskID, ErrNotFound)
+	}
+	return &task, nil
+}
+
+func (r *MemoryTaskRepository) UpdateTask(_ context.Context, task model.Task) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tasks[task.ID]
+	if !ok || stored.DeletedAt != nil {
+		return 0, fmt.Errorf("ошибка обновления задачи с ID %d: %w", task.ID, ErrNotFound)
+	}
+	if stored.Version != task.Version {
+		return 0, fmt.Errorf("ошибка обновления задачи с ID %d: задача была изменена: %w", task.ID, ErrVersionConflict)
+	}
+
+	stored.Title = task.Title
+	stored.Note = task.Note
+	stored.Done = task.Done
+	stored.UpdatedAt = task.UpdatedAt
+	stored.Version++
+	r.tasks[task.ID] = stored
+	return stored.Version, nil
+}
+
+func (r *MemoryTaskRepository) DeleteTask(_ context.Context, taskID, expectedVersion int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[taskID]
+	if !ok || task.DeletedAt != nil {
+		return 0, fmt.Errorf("ошибка удаления задачи с ID %d: %w", taskID, ErrNotFound)
+	}
+	if expectedVersion > 0 && task.Version != expectedVersion {
+		return 0, fmt.Errorf("ошибка удаления задачи с ID %d: задача была изменена: %w", taskID, ErrVersionConflict)
+	}
+
+	now := time.Now()
+	task.DeletedAt = &now
+	task.Version++
+	r.tasks[taskID] = task
+	return task.UserID, nil
+}
+
+func (r *MemoryTaskRepository) GetAllTasks(ctx context.Context) ([]model.Task, error) {
+	return r.ListTasks(ctx, model.TaskFilter{})
+}
+
+func (r *MemoryTaskRepository) ListTasks(_ context.Context, filter model.TaskFilter) ([]model.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	userIDs := make(map[int64]struct{}, len(filter.UserIDs))
+	for _, userID := range filter.UserIDs {
+		userIDs[userID] = struct{}{}
+	}
+
+	tasks := make([]model.Task, 0)
+	for _, task := range r.tasks {
+		if task.DeletedAt != nil {
+			continue
+		}
+		if _, ok := userIDs[task.UserID]; len(userIDs) > 0 && !ok {
+			continue
+		}
+		if filter.Done != nil && task.Done != *filter.Done {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return paginate(tasks, filter.Limit, filter.Offset), nil
+}
+
+// SearchTasks находит задачи, в названии или заметке которых встречаются все слова запроса без учета регистра.
+// Совпадение в названии ценится выше, совпадения выделяются тегами <b>, как в ts_headline.
+func (r *MemoryTaskRepository) SearchTasks(_ context.Context, search model.TaskSearch) ([]model.TaskSearchResult, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	words := strings.Fields(strings.ToLower(search.Query))
+	if len(words) == 0 {
+		return []model.TaskSearchResult{}, 0, nil
+	}
+
+	results := make([]model.TaskSearchResult, 0)
+	for _, task := range r.tasks {
+		if task.DeletedAt != nil || (search.UserID != 0 && task.UserID != search.UserID) {
+			continue
+		}
+		title, note := strings.ToLower(task.Title), strings.ToLower(task.Note)
+		var rank float64
+		matched := true
+		for _, word := range words {
+			inTitle, inNote := strings.Contains(title, word), strings.Contains(note, word)
+			if !inTitle && !inNote {
+				matched = false
+				break
+			}
+			if inTitle {
+				rank += 1
+			}
+			if inNote {
+				rank += 0.4
+			}
+		}
+		if !matched {
+			continue
+		}
+		results = append(results, model.TaskSearchResult{
+			Task:           task,
+			Rank:           rank,
+			TitleHighlight: highlight(task.Title, words),
+			NoteHighlight:  highlight(task.Note, words),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Task.ID < results[j].Task.ID
+	})
+
+	return paginate(results, search.Limit, search.Offset), int64(len(results)), nil
+}
+
+func (r *MemoryTaskRepository) RestoreTask(_ context.Context, taskID int64) (*model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[taskID]
+	if !ok || task.DeletedAt == nil {
+		return nil, fmt.Errorf("ошибка восстановления задачи с ID %d: %w", taskID, ErrNotFound)
+	}
+
+	task.DeletedAt = nil
+	task.Version++
+	r.tasks[taskID] = task
+	return &task, nil
+}
+
+func (r *MemoryTaskRepository) ListDeletedTasks(_ context.Context, userID int64) ([]model.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]model.Task, 0)
+	for _, task := range r.tasks {
+		if task.DeletedAt != nil && (userID == 0 || task.UserID == userID) {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks, nil
+}
+
+func (r *MemoryTaskRepository) PurgeTasks(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, task := range r.tasks {
+		if task.DeletedAt != nil && task.DeletedAt.Before(before) {
+			delete(r.tasks, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// highlight выделяет тегами <b> вхождения слов words (в нижнем регистре) в тексте
+func highlight(text string, words []string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Смена регистра изменила длину строки, позиции вхождений не совпадут с исходным текстом
+		return text
+	}
+
+	marked := make([]bool, len(text))
+	for _, word := range words {
+		for start := 0; ; {
+			i := strings.Index(lower[start:], word)
+			if i < 0 {
+				break
+			}
+			for j := start + i; j < start+i+len(word); j++ {
+				marked[j] = true
+			}
+			start += i + len(word)
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString("<b>")
+		}
+		b.WriteByte(text[i])
+		if marked[i] && (i == len(text)-1 || !marked[i+1]) {
+			b.WriteString("</b>")
+		}
+	}
+	return b.String()
+}
+
+// MemoryUserRepository потокобезопасное хранилище пользователей в памяти процесса.
+// Повторяет поведение PgUserRepository и используется для тестов сервисов без PostgreSQL.
+type MemoryUserRepository struct {
+	mu           sync.RWMutex
+	users        map[int64]model.User
+	nextID       int64
+	tasks        *MemoryTaskRepository
+	cascadeTasks bool
+}
+
+// NewMemoryUserRepository создает пустое хранилище пользователей в памяти.
+// Задачи пользователей из tasks перемещаются в корзину вместе с ними при cascadeTasks,
+// иначе удаление пользователя с задачами возвращает ErrUserHasTasks. tasks может быть nil.
+func NewMemoryUserRepository(tasks *MemoryTaskRepository, cascadeTasks bool) *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users:        make(map[int64]model.User),
+		tasks:        tasks,
+		cascadeTasks: cascadeTasks,
+	}
+}
+
+// snapshot копирует пользователей и возвращает функцию, которая возвращает хранилище к этой копии.
+// Счетчик ID не откатывается, как и последовательности PostgreSQL.
+func (r *MemoryUserRepository) snapshot() func() {
+	r.mu.RLock()
+	users := maps.Clone(r.users)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.users = users
+		r.mu.Unlock()
+	}
+}
+
+// usernameTaken проверяет, что имя занято другим пользователем
+func (r *MemoryUserRepository) usernameTaken(username string, exceptID int64) bool {
+	for _, user := range r.users {
+		if user.ID != exceptID && user.DeletedAt == nil && user.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryUserRepository) CreateUser(_ context.Context, user model.User) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.usernameTaken(user.Username, 0) {
+		return nil, fmt.Errorf("ошибка создания пользователя: пользователь %q уже существует: %w", user.Username, ErrUsernameTaken)
+	}
+
+	r.nextID++
+	user.ID = r.nextID
+	user.Version = 1
+	r.users[user.ID] = user
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) GetUserByID(_ context.Context, userID int64) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.DeletedAt != nil {
+		return nil, fmt.Errorf("ошибка получения пользователя с ID %d: %w", userID, ErrNotFound)
+	}
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) UpdateUser(_ context.Context, user model.User) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID]
+	if !ok || stored.DeletedAt != nil || stored.Version != user.Version {
+		return 0, fmt.Errorf("пользователь с ID %d был изменен: %w", user.ID, ErrVersionConflict)
+	}
+	if r.usernameTaken(user.Username, user.ID) {
+		return 0, fmt.Errorf("пользователь %q уже существует: %w", user.Username, ErrUsernameTaken)
+	}
+
+	stored.Username = user.Username
+	stored.Version++
+	r.users[user.ID] = stored
+	return stored.Version, nil
+}
+
+func (r *MemoryUserRepository) DeleteUser(_ context.Context, userID int64) ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	taskIDs := make([]int64, 0)
+	if r.tasks != nil {
+		r.tasks.mu.Lock()
+		defer r.tasks.mu.Unlock()
+
+		for id, task := range r.tasks.tasks {
+			if task.UserID != userID || task.DeletedAt != nil {
+				continue
+			}
+			if !r.cascadeTasks {
+				return nil, fmt.Errorf("ошибка удаления пользователя с ID %d: пользователь с ID %d: %w", userID, userID, ErrUserHasTasks)
+			}
+			taskIDs = append(taskIDs, id)
+		}
+		for _, id := range taskIDs {
+			task := r.tasks.tasks[id]
+			task.DeletedAt = &now
+			task.Version++
+			r.tasks.tasks[id] = task
+		}
+		sort.Slice(taskIDs, func(i, j int) bool { return taskIDs[i] < taskIDs[j] })
+	}
+
+	if user, ok := r.users[userID]; ok && user.DeletedAt == nil {
+		user.DeletedAt = &now
+		user.Version++
+		r.users[userID] = user
+	}
+	return taskIDs, nil
+}
+
+func (r *MemoryUserRepository) GetAllUsers(_ context.Context) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]model.User, 0, len(r.users))
+	for _, user := range r.users {
+		if user.DeletedAt == nil {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *MemoryUserRepository) GetUserNameByID(_ context.Context, userID int64) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.DeletedAt != nil {
+		return "", fmt.Errorf("ошибка получения имени пользователя с ID %d: %w", userID, ErrNotFound)
+	}
+	return user.Username, nil
+}
+
+func (r *MemoryUserRepository) RestoreUser(_ context.Context, userID int64) (*model.User, []model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.DeletedAt == nil {
+		return nil, nil, fmt.Errorf("ошибка восстановления пользователя с ID %d: %w", userID, ErrNotFound)
+	}
+	if r.usernameTaken(user.Username, userID) {
+		return nil, nil, fmt.Errorf("ошибка восстановления пользователя с ID %d: пользователь %q уже существует: %w", userID, user.Username, ErrUsernameTaken)
+	}
+
+	tasks := make([]model.Task, 0)
+	if r.tasks != nil {
+		r.tasks.mu.Lock()
+		defer r.tasks.mu.Unlock()
+
+		for id, task := range r.tasks.tasks {
+			if task.UserID == userID && task.DeletedAt != nil && task.DeletedAt.Equal(*user.DeletedAt) {
+				task.DeletedAt = nil
+				task.Version++
+				r.tasks.tasks[id] = task
+				tasks = append(tasks, task)
+			}
+		}
+		sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	}
+
+	user.DeletedAt = nil
+	user.Version++
+	r.users[userID] = user
+	return &user, tasks, nil
+}
+
+func (r *MemoryUserRepository) ListDeletedUsers(_ context.Context, userID int64) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]model.User, 0)
+	for _, user := range r.users {
+		if user.DeletedAt != nil && (userID == 0 || user.ID == userID) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].DeletedAt.Equal(*users[j].DeletedAt) {
+			return users[i].DeletedAt.After(*users[j].DeletedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+func (r *MemoryUserRepository) PurgeUsers(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks != nil {
+		r.tasks.mu.Lock()
+		defer r.tasks.mu.Unlock()
+	}
+
+	var purged int64
+	for id, user := range r.users {
+		if user.DeletedAt == nil || !user.DeletedAt.Before(before) {
+			continue
+		}
+		if r.tasks != nil {
+			for taskID, task := range r.tasks.tasks {
+				if task.UserID == id {
+					delete(r.tasks.tasks, taskID)
+				}
+			}
+		}
+		delete(r.users, id)
+		purged++
+	}
+	return purged, nil
+}
+
+// paginate применяет смещение и ограничение к отсортированной выборке, limit равный нулю снимает ограничение
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,105 @@
+package dao
+
+import (
+	"TODO/internal/session"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// replicaKey ключ контекста, которым отмечены запросы к реплике
+type replicaKey struct{}
+
+// onReplica сообщает, что вызов DAO выполняется на реплике: транзакция должна быть только
+// для чтения и не выше RepeatableRead, так как реплика в режиме hot standby не принимает Serializable
+func onReplica(ctx context.Context) bool {
+	replica, _ := ctx.Value(replicaKey{}).(bool)
+	return replica
+}
+
+// ReadRouter направляет чтения хранилищ PostgreSQL на реплику, а при ее ошибке — на основную базу.
+// Чтения внутри WithinTx и чтения сеанса, который недавно писал, выполняются на основной базе,
+// чтобы сеанс видел собственные записи независимо от отставания реплики.
+type ReadRouter struct {
+	primary *TransactionManager
+	replica *TransactionManager // nil — реплика не настроена
+	window  time.Duration
+	log     *slog.Logger
+
+	mu        sync.Mutex
+	writes    map[string]time.Time // время последней записи по ключу сеанса
+	lastPrune time.Time
+}
+
+// NewReadRouter создает маршрутизатор чтений. Если replica равна nil, все запросы идут в primary.
+// window задает, сколько после записи чтения сеанса выполняются на основной базе.
+// В log пишутся ошибки реплики, после которых чтение повторяется на основной базе.
+func NewReadRouter(primary, replica *pgxpool.Pool, window time.Duration, log *slog.Logger) *ReadRouter {
+	r := &ReadRouter{
+		primary: NewTransactionManager(primary, log),
+		window:  window,
+		log:     log,
+		writes:  make(map[string]time.Time),
+	}
+	if replica != nil {
+		r.replica = NewTransactionManager(replica, log)
+	}
+	return r
+}
+
+// read выполняет чтение fn на реплике или на основной базе. Ошибки реплики, кроме отмены контекста,
+// пишутся в журнал, и чтение повторяется на основной базе. Повторяется и ErrNotFound:
+// запись могла еще не дойти до реплики.
+func (r *ReadRouter) read(ctx context.Context, fn func(ctx context.Context, tm *TransactionManager) error) error {
+	if r.replica == nil || InTx(ctx) || r.pinned(ctx) {
+		return fn(ctx, r.primary)
+	}
+
+	err := fn(context.WithValue(ctx, replicaKey{}, true), r.replica)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if !errors.Is(err, ErrNotFound) {
+		r.log.WarnContext(ctx, "Ошибка чтения с реплики, запрос повторяется на основной базе", "error", err)
+	}
+	return fn(ctx, r.primary)
+}
+
+// pinned сообщает, что сеанс из контекста писал в базу не раньше window назад
+func (r *ReadRouter) pinned(ctx context.Context) bool {
+	key := session.FromContext(ctx)
+	if key == "" {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wroteAt, ok := r.writes[key]
+	return ok && time.Since(wroteAt) < r.window
+}
+
+// wrote отмечает запись сеанса из контекста. Устаревшие отметки удаляются не чаще раза в window.
+func (r *ReadRouter) wrote(ctx context.Context) {
+	key := session.FromContext(ctx)
+	if r.replica == nil || r.window <= 0 || key == "" {
+		return
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes[key] = now
+
+	if now.Sub(r.lastPrune) < r.window {
+		return
+	}
+	for k, wroteAt := range r.writes {
+		if now.Sub(wroteAt) >= r.window {
+			delete(r.writes, k)
+		}
+	}
+	r.lastPrune = now
+}
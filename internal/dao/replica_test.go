@@ -0,0 +1,119 @@
+package dao_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"TODO/internal/dao"
+	"TODO/internal/model"
+	"TODO/internal/session"
+)
+
+// replicaFallbackMessage запись журнала о чтении, повторенном на основной базе после ошибки реплики
+const replicaFallbackMessage = "Ошибка чтения с реплики"
+
+// unreachablePool создает пул, который подключается лениво к адресу без сервера:
+// любой запрос к нему завершается ошибкой
+func unreachablePool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	config, err := pgxpool.ParseConfig("postgres://todo@127.0.0.1:1/todo?connect_timeout=1")
+	if err != nil {
+		t.Fatalf("pgxpool.ParseConfig: %v", err)
+	}
+	config.LazyConnect = true
+	pool, err := pgxpool.ConnectConfig(context.Background(), config)
+	if err != nil {
+		t.Fatalf("pgxpool.ConnectConfig: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// routedUsers создает хранилище пользователей с недоступной репликой и возвращает
+// счетчик чтений, которые ушли на реплику и были повторены на основной базе
+func routedUsers(t *testing.T, primary *pgxpool.Pool, window time.Duration) (dao.UserRepository, func() int) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	reads := dao.NewReadRouter(primary, unreachablePool(t), window, log)
+	return dao.NewPgUserRepository(primary, reads, false, log), func() int {
+		return strings.Count(buf.String(), replicaFallbackMessage)
+	}
+}
+
+// Ошибка реплики не доходит до вызывающей стороны: чтение повторяется на основной базе,
+// а после отмены контекста не повторяется
+func TestReadRouterFallback(t *testing.T) {
+	users, fallbacks := routedUsers(t, unreachablePool(t), time.Minute)
+
+	if _, err := users.GetUserByID(context.Background(), 1); err == nil {
+		t.Fatal("GetUserByID: ожидалась ошибка недоступной основной базы")
+	}
+	if got := fallbacks(); got != 1 {
+		t.Errorf("повторов на основной базе %d, ожидался один", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := users.GetUserByID(ctx, 1); err == nil {
+		t.Fatal("GetUserByID: ожидалась ошибка отмененного контекста")
+	}
+	if got := fallbacks(); got != 1 {
+		t.Errorf("после отмены контекста чтение повторено на основной базе, повторов %d", got)
+	}
+}
+
+// Сеанс, который недавно писал, читает с основной базы, остальные сеансы — с реплики
+func TestReadRouterPinsWritingSession(t *testing.T) {
+	window := 200 * time.Millisecond
+	users, fallbacks := routedUsers(t, testPool(t), window)
+
+	writer := session.NewContext(context.Background(), "writer")
+	user, err := users.CreateUser(writer, model.User{Username: uniqueUsername("replica"), CreatedAt: time.Now().UTC().Truncate(time.Second)})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	if _, err := users.GetUserByID(writer, user.ID); err != nil {
+		t.Fatalf("GetUserByID писавшего сеанса: %v", err)
+	}
+	if got := fallbacks(); got != 0 {
+		t.Errorf("чтение писавшего сеанса ушло на реплику, повторов %d", got)
+	}
+
+	reader := session.NewContext(context.Background(), "reader")
+	if _, err := users.GetUserByID(reader, user.ID); err != nil {
+		t.Fatalf("GetUserByID другого сеанса: %v", err)
+	}
+	if got := fallbacks(); got != 1 {
+		t.Errorf("повторов на основной базе %d, ожидался один: чтение другого сеанса должно уйти на реплику", got)
+	}
+
+	err = dao.NewTransactionManager(testPool(t), discardLog).WithinTx(reader, pgx.TxOptions{}, func(ctx context.Context) error {
+		_, err := users.GetUserByID(ctx, user.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("GetUserByID внутри WithinTx: %v", err)
+	}
+	if got := fallbacks(); got != 1 {
+		t.Errorf("чтение внутри WithinTx ушло на реплику, повторов %d", got)
+	}
+
+	time.Sleep(window)
+	if _, err := users.GetUserByID(writer, user.ID); err != nil {
+		t.Fatalf("GetUserByID после окна: %v", err)
+	}
+	if got := fallbacks(); got != 2 {
+		t.Errorf("повторов на основной базе %d, ожидалось два: после окна сеанс читает с реплики", got)
+	}
+}
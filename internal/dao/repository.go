@@ -0,0 +1,247 @@
+package dao
+
+import (
+	"TODO/internal/model"
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log/slog"
+	"time"
+)
+
+// TaskRepository хранилище задач
+type TaskRepository interface {
+	// CreateTask создает задачу и возвращает ее с присвоенными ID и версией
+	CreateTask(ctx context.Context, task model.Task) (*model.Task, error)
+	// GetTaskByID возвращает задачу по ID или ErrNotFound
+	GetTaskByID(ctx context.Context, taskID int64) (*model.Task, error)
+	// UpdateTask обновляет задачу при совпадении версии и возвращает новую версию.
+	// Если задачи нет или она в корзине, возвращается ErrNotFound, при несовпадении версии — ErrVersionConflict
+	UpdateTask(ctx context.Context, task model.Task) (int64, error)
+	// DeleteTask перемещает задачу в корзину и возвращает ID ее владельца.
+	// Если задачи нет или она уже в корзине, возвращается ErrNotFound, при несовпадении версии — ErrVersionConflict
+	DeleteTask(ctx context.Context, taskID, expectedVersion int64) (int64, error)
+	// GetAllTasks возвращает все задачи
+	GetAllTasks(ctx context.Context) ([]model.Task, error)
+	// ListTasks возвращает задачи, подходящие под фильтр, упорядоченные по ID
+	ListTasks(ctx context.Context, filter model.TaskFilter) ([]model.Task, error)
+	// SearchTasks ищет задачи по тексту и возвращает страницу результатов по убыванию релевантности и их общее количество
+	SearchTasks(ctx context.Context, search model.TaskSearch) ([]model.TaskSearchResult, int64, error)
+	// RestoreTask возвращает задачу из корзины или ErrNotFound, если ее там нет
+	RestoreTask(ctx context.Context, taskID int64) (*model.Task, error)
+	// ListDeletedTasks возвращает задачи из корзины (только пользователя userID, если он больше нуля)
+	ListDeletedTasks(ctx context.Context, userID int64) ([]model.Task, error)
+	// PurgeTasks окончательно удаляет задачи, перемещенные в корзину раньше before
+	PurgeTasks(ctx context.Context, before time.Time) (int64, error)
+}
+
+// UserRepository хранилище пользователей
+type UserRepository interface {
+	// CreateUser создает пользователя и возвращает его с присвоенными ID и версией или ErrUsernameTaken
+	CreateUser(ctx context.Context, user model.User) (*model.User, error)
+	// GetUserByID возвращает пользователя по ID или ErrNotFound
+	GetUserByID(ctx context.Context, userID int64) (*model.User, error)
+	// UpdateUser обновляет пользователя при совпадении версии и возвращает новую версию
+	UpdateUser(ctx context.Context, user model.User) (int64, error)
+	// DeleteUser перемещает пользователя в корзину вместе с задачами и возвращает их ID или возвращает ErrUserHasTasks
+	DeleteUser(ctx context.Context, userID int64) ([]int64, error)
+	// GetAllUsers возвращает всех пользователей
+	GetAllUsers(ctx context.Context) ([]model.User, error)
+	// GetUserNameByID возвращает имя пользователя по ID или ErrNotFound
+	GetUserNameByID(ctx context.Context, userID int64) (string, error)
+	// RestoreUser возвращает пользователя из корзины вместе с задачами, удаленными вместе с ним, и возвращает эти задачи
+	// или возвращает ErrNotFound, если его там нет, и ErrUsernameTaken, если его имя заняли
+	RestoreUser(ctx context.Context, userID int64) (*model.User, []model.Task, error)
+	// ListDeletedUsers возвращает пользователей из корзины (только userID, если он больше нуля)
+	ListDeletedUsers(ctx context.Context, userID int64) ([]model.User, error)
+	// PurgeUsers окончательно удаляет пользователей, перемещенных в корзину раньше before, вместе с их задачами
+	PurgeUsers(ctx context.Context, before time.Time) (int64, error)
+}
+
+var (
+	_ TaskRepository = (*PgTaskRepository)(nil)
+	_ UserRepository = (*PgUserRepository)(nil)
+)
+
+// PgTaskRepository хранилище задач в PostgreSQL
+type PgTaskRepository struct {
+	tm    *TransactionManager
+	reads *ReadRouter
+}
+
+// NewPgTaskRepository создает хранилище задач поверх пула соединений.
+// Чтения распределяются через reads, nil — все запросы идут в pool. Ошибки отката транзакций пишутся в log.
+func NewPgTaskRepository(pool *pgxpool.Pool, reads *ReadRouter, log *slog.Logger) *PgTaskRepository {
+	if reads == nil {
+		reads = NewReadRouter(pool, nil, 0, log)
+	}
+	return &PgTaskRepository{tm: NewTransactionManager(pool, log), reads: reads}
+}
+
+func (r *PgTaskRepository) CreateTask(ctx context.Context, task model.Task) (*model.Task, error) {
+	created, err := CreateTask(ctx, task, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return created, err
+}
+
+func (r *PgTaskRepository) GetTaskByID(ctx context.Context, taskID int64) (task *model.Task, err error) {
+	err = r.reads.read(ctx, func(ctx context.Context, tm *TransactionManager) (err error) {
+		task, err = GetTaskByID(ctx, taskID, tm)
+		return err
+	})
+	return task, err
+}
+
+func (r *PgTaskRepository) UpdateTask(ctx context.Context, task model.Task) (int64, error) {
+	version, err := UpdateTask(ctx, task, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return version, err
+}
+
+func (r *PgTaskRepository) DeleteTask(ctx context.Context, taskID, expectedVersion int64) (int64, error) {
+	userID, err := DeleteTask(ctx, taskID, expectedVersion, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return userID, err
+}
+
+func (r *PgTaskRepository) GetAllTasks(ctx context.Context) (tasks []model.Task, err error) {
+	err = r.reads.read(ctx, func(ctx context.Context, tm *TransactionManager) (err error) {
+		tasks, err = GetAllTasks(ctx, tm)
+		return err
+	})
+	return tasks, err
+}
+
+func (r *PgTaskRepository) ListTasks(ctx context.Context, filter model.TaskFilter) (tasks []model.Task, err error) {
+	err = r.reads.read(ctx, func(ctx context.Context, tm *TransactionManager) (err error) {
+		tasks, err = ListTasks(ctx, filter, tm)
+		return err
+	})
+	return tasks, err
+}
+
+func (r *PgTaskRepository) SearchTasks(ctx context.Context, search model.TaskSearch) (results []model.TaskSearchResult, total int64, err error) {
+	err = r.reads.read(ctx, func(ctx context.Context, tm *TransactionManager) (err error) {
+		results, total, err = SearchTasks(ctx, search, tm)
+		return err
+	})
+	return results, total, err
+}
+
+func (r *PgTaskRepository) RestoreTask(ctx context.Context, taskID int64) (*model.Task, error) {
+	task, err := RestoreTask(ctx, taskID, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return task, err
+}
+
+func (r *PgTaskRepository) ListDeletedTasks(ctx context.Context, userID int64) (tasks []model.Task, err error) {
+	err = r.reads.read(ctx, func(ctx context.Context, tm *TransactionManager) (err error) {
+		tasks, err = ListDeletedTasks(ctx, userID, tm)
+		return err
+	})
+	return tasks, err
+}
+
+func (r *PgTaskRepository) PurgeTasks(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := PurgeTasks(ctx, before, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return purged, err
+}
+
+// PgUserRepository хранилище пользователей в PostgreSQL
+type PgUserRepository struct {
+	tm           *TransactionManager
+	reads        *ReadRouter
+	cascadeTasks bool
+}
+
+// NewPgUserRepository создает хранилище пользователей поверх пула соединений.
+// Чтения распределяются через reads, nil — все запросы идут в pool.
+// cascadeTasks включает удаление задач вместе с пользователем. Ошибки отката транзакций пишутся в log.
+func NewPgUserRepository(pool *pgxpool.Pool, reads *ReadRouter, cascadeTasks bool, log *slog.Logger) *PgUserRepository {
+	if reads == nil {
+		reads = NewReadRouter(pool, nil, 0, log)
+	}
+	return &PgUserRepository{tm: NewTransactionManager(pool, log), reads: reads, cascadeTasks: cascadeTasks}
+}
+
+func (r *PgUserRepository) CreateUser(ctx context.Context, user model.User) (*model.User, error) {
+	created, err := CreateUser(ctx, user, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return created, err
+}
+
+func (r *PgUserRepository) GetUserByID(ctx context.Context, userID int64) (user *model.User, err error) {
+	err = r.reads.read(ctx, func(ctx context.Context, tm *TransactionManager) (err error) {
+		user, err = GetUserByID(ctx, userID, tm)
+		return err
+	})
+	return user, err
+}
+
+func (r *PgUserRepository) UpdateUser(ctx context.Context, user model.User) (int64, error) {
+	version, err := UpdateUser(ctx, user, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return version, err
+}
+
+func (r *PgUserRepository) DeleteUser(ctx context.Context, userID int64) ([]int64, error) {
+	taskIDs, err := DeleteUser(ctx, userID, r.cascadeTasks, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return taskIDs, err
+}
+
+func (r *PgUserRepository) GetAllUsers(ctx context.Context) (users []model.User, err error) {
+	err = r.reads.read(ctx, func(ctx context.Context, tm *TransactionManager) (err error) {
+		users, err = GetAllUsers(ctx, tm)
+		return err
+	})
+	return users, err
+}
+
+func (r *PgUserRepository) GetUserNameByID(ctx context.Context, userID int64) (username string, err error) {
+	err = r.reads.read(ctx, func(ctx context.Context, tm *TransactionManager) (err error) {
+		username, err = GetUserNameByID(ctx, userID, tm)
+		return err
+	})
+	return username, err
+}
+
+func (r *PgUserRepository) RestoreUser(ctx context.Context, userID int64) (*model.User, []model.Task, error) {
+	user, tasks, err := RestoreUser(ctx, userID, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return user, tasks, err
+}
+
+func (r *PgUserRepository) ListDeletedUsers(ctx context.Context, userID int64) (users []model.User, err error) {
+	err = r.reads.read(ctx, func(ctx context.Context, tm *TransactionManager) (err error) {
+		users, err = ListDeletedUsers(ctx, userID, tm)
+		return err
+	})
+	return users, err
+}
+
+func (r *PgUserRepository) PurgeUsers(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := PurgeUsers(ctx, before, r.tm)
+	if err == nil {
+		r.reads.wrote(ctx)
+	}
+	return purged, err
+}
@@ -0,0 +1,114 @@
+package dao_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"TODO/internal/dao"
+	"TODO/internal/migrate"
+	"TODO/internal/model"
+)
+
+// repositories создает хранилища пользователей и задач одной реализации
+type repositories func(t *testing.T) (dao.UserRepository, dao.TaskRepository)
+
+// allRepositories реализации репозиториев, которые должны вести себя одинаково
+var allRepositories = map[string]repositories{
+	"memory": func(t *testing.T) (dao.UserRepository, dao.TaskRepository) {
+		tasks := dao.NewMemoryTaskRepository()
+		return dao.NewMemoryUserRepository(tasks, false), tasks
+	},
+	"sqlite": func(t *testing.T) (dao.UserRepository, dao.TaskRepository) {
+		path := filepath.Join(t.TempDir(), "todo.db")
+		migrator, err := migrate.NewSQLite(path, discardLog)
+		if err != nil {
+			t.Fatalf("migrate.NewSQLite: %v", err)
+		}
+		if err := migrator.Up(context.Background()); err != nil {
+			t.Fatalf("migrator.Up: %v", err)
+		}
+		if err := migrator.Close(); err != nil {
+			t.Fatalf("migrator.Close: %v", err)
+		}
+
+		db, err := dao.OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("dao.OpenSQLite: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		return dao.NewSQLiteUserRepository(db, false, discardLog), dao.NewSQLiteTaskRepository(db, discardLog)
+	},
+	"postgres": func(t *testing.T) (dao.UserRepository, dao.TaskRepository) {
+		pool := testPool(t)
+		reads := dao.NewReadRouter(pool, nil, 0, discardLog)
+		return dao.NewPgUserRepository(pool, reads, false, discardLog), dao.NewPgTaskRepository(pool, reads, discardLog)
+	},
+}
+
+func TestTaskRepositoryNotFoundAndVersionConflict(t *testing.T) {
+	for name, newRepositories := range allRepositories {
+		t.Run(name, func(t *testing.T) {
+			users, tasks := newRepositories(t)
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Second)
+
+			user, err := users.CreateUser(ctx, model.User{Username: fmt.Sprintf("repo-%d", time.Now().UnixNano()), CreatedAt: now})
+			if err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+			createTask := func(title string) *model.Task {
+				t.Helper()
+				task, err := tasks.CreateTask(ctx, model.Task{UserID: user.ID, Title: title, CreatedAt: now, UpdatedAt: now})
+				if err != nil {
+					t.Fatalf("CreateTask: %v", err)
+				}
+				return task
+			}
+
+			active := createTask("действующая")
+			trashed := createTask("в корзине")
+			if _, err := tasks.DeleteTask(ctx, trashed.ID, 0); err != nil {
+				t.Fatalf("DeleteTask: %v", err)
+			}
+			const missingID = 1 << 40
+
+			update := func(task model.Task) error {
+				task.UpdatedAt = now
+				_, err := tasks.UpdateTask(ctx, task)
+				return err
+			}
+			remove := func(taskID, version int64) error {
+				_, err := tasks.DeleteTask(ctx, taskID, version)
+				return err
+			}
+
+			tests := []struct {
+				name string
+				call func() error
+				want error
+			}{
+				{name: "изменение несуществующей задачи", call: func() error { return update(model.Task{ID: missingID, Version: 1}) }, want: dao.ErrNotFound},
+				{name: "изменение задачи в корзине", call: func() error { return update(model.Task{ID: trashed.ID, Version: trashed.Version + 1}) }, want: dao.ErrNotFound},
+				{name: "изменение с другой версией", call: func() error { return update(model.Task{ID: active.ID, Version: active.Version + 5}) }, want: dao.ErrVersionConflict},
+				{name: "удаление несуществующей задачи", call: func() error { return remove(missingID, 0) }, want: dao.ErrNotFound},
+				{name: "удаление несуществующей задачи с версией", call: func() error { return remove(missingID, 1) }, want: dao.ErrNotFound},
+				{name: "повторное удаление", call: func() error { return remove(trashed.ID, 0) }, want: dao.ErrNotFound},
+				{name: "удаление с другой версией", call: func() error { return remove(active.ID, active.Version+5) }, want: dao.ErrVersionConflict},
+			}
+			for _, tt := range tests {
+				if err := tt.call(); !errors.Is(err, tt.want) {
+					t.Errorf("%s: ошибка %v, ожидалась %v", tt.name, err, tt.want)
+				}
+			}
+
+			userID, err := tasks.DeleteTask(ctx, active.ID, active.Version)
+			if err != nil || userID != user.ID {
+				t.Fatalf("DeleteTask = %d, %v, ожидалось %d, nil", userID, err, user.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var _ Transactor = (*SQLiteTransactor)(nil)
+
+// sqliteTimeLayout формат времени в базе SQLite. Время хранится в UTC с фиксированным числом
+// знаков после запятой, поэтому сравнение строк совпадает со сравнением моментов времени.
+const sqliteTimeLayout = "2006-01-02 15:04:05.000000000"
+
+// sqliteTime преобразует время в формат хранения SQLite
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+// OpenSQLite открывает базу SQLite в файле path, создавая его при необходимости.
+// База используется через одно соединение: SQLite допускает только одного писателя,
+// а общее соединение исключает ошибки SQLITE_BUSY между запросами сервиса.
+func OpenSQLite(path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "synchronous(NORMAL)")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы SQLite %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ошибка подключения к базе SQLite %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// isSQLiteUniqueViolation проверяет, что запрос нарушил уникальный индекс SQLite
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// sqliteNotFound заменяет sql.ErrNoRows на ErrNotFound, общую для всех реализаций репозиториев
+func sqliteNotFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// SQLiteTransactor выполняет единицы работы в транзакциях SQLite.
+// Транзакции SQLite сериализуемы, поэтому уровень изоляции из opts не используется.
+type SQLiteTransactor struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewSQLiteTransactor создает исполнитель единиц работы для хранилищ SQLite.
+// Ошибки отката транзакций пишутся в log.
+func NewSQLiteTransactor(db *sql.DB, log *slog.Logger) *SQLiteTransactor {
+	return &SQLiteTransactor{db: db, log: log}
+}
+
+func (t *SQLiteTransactor) WithinTx(ctx context.Context, _ pgx.TxOptions, fn func(ctx context.Context) error) error {
+	if InTx(ctx) {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	uow := &unitOfWork{sqlTx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, uow)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			t.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	}
+	uow.commit()
+	return nil
+}
+
+// sqliteTx транзакция одного вызова DAO SQLite: собственная транзакция
+// или точка сохранения в транзакции единицы работы WithinTx
+type sqliteTx struct {
+	tx        *sql.Tx
+	savepoint bool
+	log       *slog.Logger
+}
+
+// beginSQLite начинает транзакцию вызова DAO. Внутри WithinTx вместо новой транзакции
+// создается точка сохранения во внешней, как и в BeginTransaction для PostgreSQL. Ошибки отката пишутся в log.
+func beginSQLite(ctx context.Context, db *sql.DB, log *slog.Logger) (*sqliteTx, error) {
+	if uow, ok := unitOfWorkFromContext(ctx); ok && uow.sqlTx != nil {
+		if _, err := uow.sqlTx.ExecContext(ctx, `SAVEPOINT dao`); err != nil {
+			return nil, fmt.Errorf("ошибка создания точки сохранения: %w", err)
+		}
+		return &sqliteTx{tx: uow.sqlTx, savepoint: true, log: log}, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	return &sqliteTx{tx: tx, log: log}, nil
+}
+
+// commit подтверждает транзакцию или освобождает точку сохранения
+func (t *sqliteTx) commit(ctx context.Context) error {
+	var err error
+	if t.savepoint {
+		_, err = t.tx.ExecContext(ctx, `RELEASE dao`)
+	} else {
+		err = t.tx.Commit()
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	}
+	return nil
+}
+
+// rollback откатывает транзакцию или изменения после точки сохранения. Ошибка отката пишется в журнал.
+func (t *sqliteTx) rollback(ctx context.Context) {
+	var err error
+	if t.savepoint {
+		if _, err = t.tx.ExecContext(ctx, `ROLLBACK TO dao`); err == nil {
+			_, err = t.tx.ExecContext(ctx, `RELEASE dao`)
+		}
+	} else {
+		err = t.tx.Rollback()
+	}
+	if err != nil {
+		t.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", err)
+	}
+}
+
+// ftsQuery переводит поисковый запрос в синтаксисе websearch (слова, "фраза", or, -слово)
+// в запрос FTS5. Каждое слово и фраза заключаются в кавычки, поэтому служебные символы
+// FTS5 в запросе ищутся как обычный текст. Исключения без искомых слов не поддерживаются
+// и отбрасываются. Пустая строка означает, что в запросе нет слов для поиска.
+func ftsQuery(query string) string {
+	type group struct {
+		include []string
+		exclude []string
+	}
+	groups := []*group{{}}
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		negate := false
+		if runes[i] == '-' {
+			negate = true
+			i++
+		}
+
+		var term string
+		quoted := i < len(runes) && runes[i] == '"'
+		if quoted {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			term = string(runes[i+1 : end])
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			term = string(runes[i:end])
+			i = end
+		}
+
+		current := groups[len(groups)-1]
+		if !quoted && !negate && strings.EqualFold(term, "or") {
+			if len(current.include) > 0 {
+				groups = append(groups, &group{})
+			}
+			continue
+		}
+		if strings.TrimFunc(term, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) == "" {
+			continue
+		}
+
+		term = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if negate {
+			current.exclude = append(current.exclude, term)
+		} else {
+			current.include = append(current.include, term)
+		}
+	}
+
+	parts := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if len(g.include) == 0 {
+			continue
+		}
+		part := strings.Join(g.include, " AND ")
+		for _, term := range g.exclude {
+			part += " NOT " + term
+		}
+		parts = append(parts, "("+part+")")
+	}
+	return strings.Join(parts, " OR ")
+}
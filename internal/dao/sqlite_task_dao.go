@@ -0,0 +1,331 @@
+package dao
+
+import (
+	"TODO/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var _ TaskRepository = (*SQLiteTaskRepository)(nil)
+
+// sqliteTaskColumns столбцы задачи в порядке полей scanSQLiteTask
+const sqliteTaskColumns = `id, user_id, title, note, done, created_at, updated_at, version`
+
+// sqliteRow строка результата запроса SQLite
+type sqliteRow interface {
+	Scan(dest ...any) error
+}
+
+// scanSQLiteTask читает задачу из столбцов sqliteTaskColumns и дополнительных столбцов extra
+func scanSQLiteTask(row sqliteRow, task *model.Task, extra ...any) error {
+	dest := append([]any{&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt, &task.Version}, extra...)
+	return row.Scan(dest...)
+}
+
+// SQLiteTaskRepository хранилище задач в SQLite для запуска сервиса на одном узле без PostgreSQL.
+// Полнотекстовый поиск выполняется по индексу FTS5 без учета словоформ.
+type SQLiteTaskRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewSQLiteTaskRepository создает хранилище задач поверх базы SQLite, открытой OpenSQLite.
+// Ошибки отката транзакций пишутся в log.
+func NewSQLiteTaskRepository(db *sql.DB, log *slog.Logger) *SQLiteTaskRepository {
+	return &SQLiteTaskRepository{db: db, log: log}
+}
+
+func (r *SQLiteTaskRepository) CreateTask(ctx context.Context, task model.Task) (*model.Task, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.tx.QueryRowContext(ctx, `INSERT INTO tasks (user_id, title, note, done, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, version`,
+		task.UserID, task.Title, task.Note, task.Done, sqliteTime(task.CreatedAt), sqliteTime(task.UpdatedAt)).
+		Scan(&task.ID, &task.Version)
+	if err != nil {
+		tx.rollback(ctx)
+		return nil, fmt.Errorf("ошибка создания задачи: %w", err)
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *SQLiteTaskRepository) GetTaskByID(ctx context.Context, taskID int64) (*model.Task, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	var task model.Task
+	err = scanSQLiteTask(tx.tx.QueryRowContext(ctx, `SELECT `+sqliteTaskColumns+` FROM tasks WHERE id = $1 AND deleted_at IS NULL`, taskID), &task)
+	if err != nil {
+		tx.rollback(ctx)
+		return nil, fmt.Errorf("ошибка получения задачи с ID %d: %w", taskID, sqliteNotFound(err))
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *SQLiteTaskRepository) UpdateTask(ctx context.Context, task model.Task) (int64, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	err = tx.tx.QueryRowContext(ctx, `UPDATE tasks SET title = $2, note = $3, done = $4, updated_at = $5, version = version + 1
+		WHERE id = $1 AND version = $6 AND deleted_at IS NULL RETURNING version`,
+		task.ID, task.Title, task.Note, task.Done, sqliteTime(task.UpdatedAt), task.Version).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = sqliteTaskMissingError(ctx, tx.tx, task.ID)
+	}
+	if err != nil {
+		tx.rollback(ctx)
+		return 0, fmt.Errorf("ошибка обновления задачи с ID %d: %w", task.ID, err)
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (r *SQLiteTaskRepository) DeleteTask(ctx context.Context, taskID, expectedVersion int64) (int64, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int64
+	err = tx.tx.QueryRowContext(ctx, `UPDATE tasks SET deleted_at = $3, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2) RETURNING user_id`,
+		taskID, expectedVersion, sqliteTime(time.Now())).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = sqliteTaskMissingError(ctx, tx.tx, taskID)
+	}
+	if err != nil {
+		tx.rollback(ctx)
+		return 0, fmt.Errorf("ошибка удаления задачи с ID %d: %w", taskID, err)
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// sqliteTaskMissingError определяет, почему изменение задачи с проверкой версии не затронуло ни одной строки:
+// ErrNotFound, если задачи нет или она в корзине, иначе ErrVersionConflict.
+func sqliteTaskMissingError(ctx context.Context, tx *sql.Tx, taskID int64) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND deleted_at IS NULL)`, taskID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки существования задачи: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("задача с ID %d не найдена: %w", taskID, ErrNotFound)
+	}
+	return fmt.Errorf("задача с ID %d была изменена: %w", taskID, ErrVersionConflict)
+}
+
+func (r *SQLiteTaskRepository) GetAllTasks(ctx context.Context) ([]model.Task, error) {
+	return r.ListTasks(ctx, model.TaskFilter{})
+}
+
+func (r *SQLiteTaskRepository) ListTasks(ctx context.Context, filter model.TaskFilter) ([]model.Task, error) {
+	query := `SELECT ` + sqliteTaskColumns + ` FROM tasks WHERE deleted_at IS NULL`
+	var args []any
+	if len(filter.UserIDs) > 0 {
+		placeholders := make([]string, len(filter.UserIDs))
+		for i, userID := range filter.UserIDs {
+			args = append(args, userID)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		query += ` AND user_id IN (` + strings.Join(placeholders, ", ") + `)`
+	}
+	if filter.Done != nil {
+		args = append(args, *filter.Done)
+		query += fmt.Sprintf(` AND done = $%d`, len(args))
+	}
+	// LIMIT -1 в SQLite снимает ограничение на количество строк
+	limit := -1
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	args = append(args, limit, filter.Offset)
+	query += fmt.Sprintf(` ORDER BY id LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	tasks, err := r.queryTasks(ctx, false, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка задач: %w", err)
+	}
+	return tasks, nil
+}
+
+func (r *SQLiteTaskRepository) SearchTasks(ctx context.Context, search model.TaskSearch) ([]model.TaskSearchResult, int64, error) {
+	match := ftsQuery(search.Query)
+	if match == "" {
+		return []model.TaskSearchResult{}, 0, nil
+	}
+
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.rollback(ctx)
+		}
+	}()
+
+	var total int64
+	err = tx.tx.QueryRowContext(ctx, `SELECT count(*) FROM tasks_fts JOIN tasks ON tasks.id = tasks_fts.rowid
+		WHERE tasks_fts MATCH $1 AND tasks.deleted_at IS NULL AND ($2 = 0 OR tasks.user_id = $2)`,
+		match, search.UserID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка подсчета найденных задач: %w", err)
+	}
+
+	// bm25 возвращает тем меньшее значение, чем выше релевантность; вес названия выше веса заметки,
+	// как у весов A и B в поисковом индексе PostgreSQL
+	rows, err := tx.tx.QueryContext(ctx, `SELECT tasks.id, tasks.user_id, tasks.title, tasks.note, tasks.done,
+			tasks.created_at, tasks.updated_at, tasks.version,
+			-bm25(tasks_fts, 1.0, 0.4) AS rank,
+			highlight(tasks_fts, 0, '<b>', '</b>'),
+			snippet(tasks_fts, 1, '<b>', '</b>', ' ... ', 20)
+		FROM tasks_fts JOIN tasks ON tasks.id = tasks_fts.rowid
+		WHERE tasks_fts MATCH $1 AND tasks.deleted_at IS NULL AND ($2 = 0 OR tasks.user_id = $2)
+		ORDER BY rank DESC, tasks.id
+		LIMIT $3 OFFSET $4`, match, search.UserID, search.Limit, search.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска задач: %w", err)
+	}
+
+	results := make([]model.TaskSearchResult, 0)
+	for rows.Next() {
+		var result model.TaskSearchResult
+		err = scanSQLiteTask(rows, &result.Task, &result.Rank, &result.TitleHighlight, &result.NoteHighlight)
+		if err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("ошибка сканирования найденной задачи: %w", err)
+		}
+		results = append(results, result)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка итерации по найденным задачам: %w", err)
+	}
+
+	if commitErr := tx.commit(ctx); commitErr != nil {
+		return nil, 0, commitErr
+	}
+	return results, total, nil
+}
+
+func (r *SQLiteTaskRepository) RestoreTask(ctx context.Context, taskID int64) (*model.Task, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	var task model.Task
+	err = scanSQLiteTask(tx.tx.QueryRowContext(ctx, `UPDATE tasks SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL RETURNING `+sqliteTaskColumns, taskID), &task)
+	if err != nil {
+		tx.rollback(ctx)
+		return nil, fmt.Errorf("ошибка восстановления задачи с ID %d: %w", taskID, sqliteNotFound(err))
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *SQLiteTaskRepository) ListDeletedTasks(ctx context.Context, userID int64) ([]model.Task, error) {
+	tasks, err := r.queryTasks(ctx, true, `SELECT `+sqliteTaskColumns+`, deleted_at FROM tasks
+		WHERE deleted_at IS NOT NULL AND ($1 = 0 OR user_id = $1)
+		ORDER BY deleted_at DESC, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения задач из корзины: %w", err)
+	}
+	return tasks, nil
+}
+
+func (r *SQLiteTaskRepository) PurgeTasks(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.tx.ExecContext(ctx, `DELETE FROM tasks WHERE deleted_at < $1`, sqliteTime(before))
+	if err != nil {
+		tx.rollback(ctx)
+		return 0, fmt.Errorf("ошибка очистки корзины задач: %w", err)
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// queryTasks выполняет запрос задач. Если deleted равен true, запрос выбирает
+// после sqliteTaskColumns столбец deleted_at, который читается в DeletedAt.
+func (r *SQLiteTaskRepository) queryTasks(ctx context.Context, deleted bool, query string, args ...any) ([]model.Task, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.rollback(ctx)
+		}
+	}()
+
+	rows, err := tx.tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]model.Task, 0)
+	for rows.Next() {
+		var task model.Task
+		var extra []any
+		if deleted {
+			extra = append(extra, &task.DeletedAt)
+		}
+		if err = scanSQLiteTask(rows, &task, extra...); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("ошибка сканирования задачи: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по строкам задач: %w", err)
+	}
+
+	if commitErr := tx.commit(ctx); commitErr != nil {
+		return nil, commitErr
+	}
+	return tasks, nil
+}
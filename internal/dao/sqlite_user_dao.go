@@ -0,0 +1,309 @@
+package dao
+
+import (
+	"TODO/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+var _ UserRepository = (*SQLiteUserRepository)(nil)
+
+// SQLiteUserRepository хранилище пользователей в SQLite для запуска сервиса на одном узле без PostgreSQL
+type SQLiteUserRepository struct {
+	db           *sql.DB
+	cascadeTasks bool
+	log          *slog.Logger
+}
+
+// NewSQLiteUserRepository создает хранилище пользователей поверх базы SQLite, открытой OpenSQLite.
+// cascadeTasks включает удаление задач вместе с пользователем. Ошибки отката транзакций пишутся в log.
+func NewSQLiteUserRepository(db *sql.DB, cascadeTasks bool, log *slog.Logger) *SQLiteUserRepository {
+	return &SQLiteUserRepository{db: db, cascadeTasks: cascadeTasks, log: log}
+}
+
+func (r *SQLiteUserRepository) CreateUser(ctx context.Context, user model.User) (*model.User, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.tx.QueryRowContext(ctx, `INSERT INTO users (username, created_at) VALUES ($1, $2) RETURNING id, version`,
+		user.Username, sqliteTime(user.CreatedAt)).Scan(&user.ID, &user.Version)
+	if isSQLiteUniqueViolation(err) {
+		err = fmt.Errorf("пользователь %q уже существует: %w", user.Username, ErrUsernameTaken)
+	}
+	if err != nil {
+		tx.rollback(ctx)
+		return nil, fmt.Errorf("ошибка создания пользователя: %w", err)
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *SQLiteUserRepository) GetUserByID(ctx context.Context, userID int64) (*model.User, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	err = tx.tx.QueryRowContext(ctx, `SELECT id, username, created_at, version FROM users WHERE id = $1 AND deleted_at IS NULL`, userID).
+		Scan(&user.ID, &user.Username, &user.CreatedAt, &user.Version)
+	if err != nil {
+		tx.rollback(ctx)
+		return nil, fmt.Errorf("ошибка получения пользователя с ID %d: %w", userID, sqliteNotFound(err))
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *SQLiteUserRepository) UpdateUser(ctx context.Context, user model.User) (int64, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	err = tx.tx.QueryRowContext(ctx, `UPDATE users SET username = $2, version = version + 1
+		WHERE id = $1 AND version = $3 AND deleted_at IS NULL RETURNING version`,
+		user.ID, user.Username, user.Version).Scan(&version)
+	if err != nil {
+		tx.rollback(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("пользователь с ID %d был изменен: %w", user.ID, ErrVersionConflict)
+		}
+		if isSQLiteUniqueViolation(err) {
+			return 0, fmt.Errorf("пользователь %q уже существует: %w", user.Username, ErrUsernameTaken)
+		}
+		return 0, fmt.Errorf("ошибка обновления пользователя с ID %d: %w", user.ID, err)
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (r *SQLiteUserRepository) DeleteUser(ctx context.Context, userID int64) ([]int64, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пользователь и его задачи получают одинаковое deleted_at, по которому RestoreUser находит задачи
+	deletedAt := sqliteTime(time.Now())
+	taskIDs := make([]int64, 0)
+	if r.cascadeTasks {
+		var rows *sql.Rows
+		rows, err = tx.tx.QueryContext(ctx, `UPDATE tasks SET deleted_at = $2, version = version + 1 WHERE user_id = $1 AND deleted_at IS NULL RETURNING id`,
+			userID, deletedAt)
+		if err == nil {
+			for rows.Next() {
+				var taskID int64
+				if err = rows.Scan(&taskID); err != nil {
+					break
+				}
+				taskIDs = append(taskIDs, taskID)
+			}
+			rows.Close()
+			if err == nil {
+				err = rows.Err()
+			}
+		}
+	} else {
+		var hasTasks bool
+		err = tx.tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE user_id = $1 AND deleted_at IS NULL)`, userID).Scan(&hasTasks)
+		if err == nil && hasTasks {
+			err = fmt.Errorf("пользователь с ID %d: %w", userID, ErrUserHasTasks)
+		}
+	}
+	if err == nil {
+		_, err = tx.tx.ExecContext(ctx, `UPDATE users SET deleted_at = $2, version = version + 1 WHERE id = $1 AND deleted_at IS NULL`,
+			userID, deletedAt)
+	}
+	if err != nil {
+		tx.rollback(ctx)
+		return nil, fmt.Errorf("ошибка удаления пользователя с ID %d: %w", userID, err)
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return nil, err
+	}
+	return taskIDs, nil
+}
+
+func (r *SQLiteUserRepository) GetAllUsers(ctx context.Context) ([]model.User, error) {
+	users, err := r.queryUsers(ctx, false, `SELECT id, username, created_at, version FROM users WHERE deleted_at IS NULL ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей: %w", err)
+	}
+	return users, nil
+}
+
+func (r *SQLiteUserRepository) GetUserNameByID(ctx context.Context, userID int64) (string, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return "", err
+	}
+
+	var username string
+	err = tx.tx.QueryRowContext(ctx, `SELECT username FROM users WHERE id = $1 AND deleted_at IS NULL`, userID).Scan(&username)
+	if err != nil {
+		tx.rollback(ctx)
+		return "", fmt.Errorf("ошибка получения имени пользователя с ID %d: %w", userID, sqliteNotFound(err))
+	}
+
+	if err = tx.commit(ctx); err != nil {
+		return "", err
+	}
+	return username, nil
+}
+
+func (r *SQLiteUserRepository) RestoreUser(ctx context.Context, userID int64) (*model.User, []model.Task, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.rollback(ctx)
+		}
+	}()
+
+	var user model.User
+	var deletedAt time.Time
+	err = tx.tx.QueryRowContext(ctx, `SELECT username, deleted_at FROM users WHERE id = $1 AND deleted_at IS NOT NULL`, userID).
+		Scan(&user.Username, &deletedAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка восстановления пользователя с ID %d: %w", userID, sqliteNotFound(err))
+	}
+
+	err = tx.tx.QueryRowContext(ctx, `UPDATE users SET deleted_at = NULL, version = version + 1 WHERE id = $1
+		RETURNING id, username, created_at, version`, userID).
+		Scan(&user.ID, &user.Username, &user.CreatedAt, &user.Version)
+	if isSQLiteUniqueViolation(err) {
+		err = fmt.Errorf("пользователь %q уже существует: %w", user.Username, ErrUsernameTaken)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка восстановления пользователя с ID %d: %w", userID, err)
+	}
+
+	rows, err := tx.tx.QueryContext(ctx, `UPDATE tasks SET deleted_at = NULL, version = version + 1 WHERE user_id = $1 AND deleted_at = $2
+		RETURNING `+sqliteTaskColumns, userID, sqliteTime(deletedAt))
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка восстановления задач пользователя с ID %d: %w", userID, err)
+	}
+
+	tasks := make([]model.Task, 0)
+	for rows.Next() {
+		var task model.Task
+		if err = scanSQLiteTask(rows, &task); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("ошибка сканирования задачи: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("ошибка восстановления задач пользователя с ID %d: %w", userID, err)
+	}
+
+	if commitErr := tx.commit(ctx); commitErr != nil {
+		return nil, nil, commitErr
+	}
+	return &user, tasks, nil
+}
+
+func (r *SQLiteUserRepository) ListDeletedUsers(ctx context.Context, userID int64) ([]model.User, error) {
+	users, err := r.queryUsers(ctx, true, `SELECT id, username, created_at, version, deleted_at FROM users
+		WHERE deleted_at IS NOT NULL AND ($1 = 0 OR id = $1)
+		ORDER BY deleted_at DESC, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей из корзины: %w", err)
+	}
+	return users, nil
+}
+
+func (r *SQLiteUserRepository) PurgeUsers(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.rollback(ctx)
+		}
+	}()
+
+	_, err = tx.tx.ExecContext(ctx, `DELETE FROM tasks WHERE user_id IN (SELECT id FROM users WHERE deleted_at < $1)`, sqliteTime(before))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки задач удаленных пользователей: %w", err)
+	}
+
+	result, err := tx.tx.ExecContext(ctx, `DELETE FROM users WHERE deleted_at < $1`, sqliteTime(before))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки корзины пользователей: %w", err)
+	}
+
+	if commitErr := tx.commit(ctx); commitErr != nil {
+		return 0, commitErr
+	}
+	return result.RowsAffected()
+}
+
+// queryUsers выполняет запрос пользователей. Если deleted равен true, запрос выбирает
+// после id, username, created_at и version столбец deleted_at, который читается в DeletedAt.
+func (r *SQLiteUserRepository) queryUsers(ctx context.Context, deleted bool, query string, args ...any) ([]model.User, error) {
+	tx, err := beginSQLite(ctx, r.db, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.rollback(ctx)
+		}
+	}()
+
+	rows, err := tx.tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]model.User, 0)
+	for rows.Next() {
+		var user model.User
+		dest := []any{&user.ID, &user.Username, &user.CreatedAt, &user.Version}
+		if deleted {
+			dest = append(dest, &user.DeletedAt)
+		}
+		if err = rows.Scan(dest...); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("ошибка сканирования пользователя: %w", err)
+		}
+		users = append(users, user)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по строкам пользователей: %w", err)
+	}
+
+	if commitErr := tx.commit(ctx); commitErr != nil {
+		return nil, commitErr
+	}
+	return users, nil
+}
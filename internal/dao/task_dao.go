@@ -3,61 +3,64 @@ package dao
 import (
 	"TODO/internal/model"
 	"context"
+	"errors"
 	"fmt"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
-	"log"
+	"time"
 )
 
-// CreateTask создает новую задачу.
-func CreateTask(ctx context.Context, task model.Task, pool *pgxpool.Pool) (int64, error) {
-	tx, conn, err := NewTransactionManager(pool).BeginTransaction(ctx, pgx.ReadCommitted)
+// CreateTask создает новую задачу и возвращает ее с присвоенными ID и версией.
+func CreateTask(ctx context.Context, task model.Task, tm *TransactionManager) (*model.Task, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.ReadCommitted)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	defer func() {
 		if err != nil {
-			if rollbackErr := NewTransactionManager(pool).RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-				log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 			}
 		}
 	}()
 
-	query := `INSERT INTO tasks (user_id, title, note, done, created_at, updated_at)
-			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	query := `INSERT INTO tasks (user_id, title, note, done, created_at, updated_at, search_vector)
+			  VALUES ($1, $2, $3, $4, $5, $6,
+			  (SELECT setweight(to_tsvector(language, $2), 'A') || setweight(to_tsvector(language, $3), 'B') FROM search_settings))
+			  RETURNING id, version`
 
-	var taskID int64
-	err = tx.QueryRow(ctx, query, task.UserID, task.Title, task.Note, task.Done, task.CreatedAt, task.UpdatedAt).Scan(&taskID)
+	err = tx.QueryRow(ctx, query, task.UserID, task.Title, task.Note, task.Done, task.CreatedAt, task.UpdatedAt).
+		Scan(&task.ID, &task.Version)
 	if err != nil {
-		return 0, fmt.Errorf("ошибка создания задачи: %w", err)
+		return nil, fmt.Errorf("ошибка создания задачи: %w", err)
 	}
 
-	if err = NewTransactionManager(pool).CommitTransaction(ctx, tx, conn); err != nil {
-		return 0, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	if commitErr := tm.CommitTransaction(ctx, tx, conn); commitErr != nil {
+		return nil, fmt.Errorf("ошибка подтверждения транзакции: %w", commitErr)
 	}
 
-	return taskID, nil
+	return &task, nil
 }
 
 // GetTaskByID извлекает задачу по ее идентификатору .
-func GetTaskByID(ctx context.Context, taskID int64, pool *pgxpool.Pool) (*model.Task, error) {
-	tx, conn, err := NewTransactionManager(pool).BeginTransaction(ctx, pgx.RepeatableRead)
+func GetTaskByID(ctx context.Context, taskID int64, tm *TransactionManager) (*model.Task, error) {
+	tx, conn, err := tm.BeginTransaction(ctx, pgx.RepeatableRead)
 	if err != nil {
 		return nil, err
 	}
 
 	var task model.Task
-	err = tx.QueryRow(ctx, `SELECT id, user_id, title, note, done, created_at, updated_at FROM tasks WHERE id = $1`, taskID).
-		Scan(&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt)
+	err = tx.QueryRow(ctx, `SELECT id, user_id, title, note, done, created_at, updated_at, version FROM tasks WHERE id = $1 AND deleted_at IS NULL`, taskID).
+		Scan(&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt, &task.Version)
 	if err != nil {
-		if rollbackErr := NewTransactionManager(pool).RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
 		return nil, fmt.Errorf("ошибка получения задачи с ID %d: %w", taskID, err)
 	}
 
-	if err = NewTransactionManager(pool).CommitTransaction(ctx, tx, conn); err != nil {
+	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
 		return nil, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
 	}
 
@@ -65,61 +68,93 @@ func GetTaskByID(ctx context.Context, taskID int64, pool *pgxpool.Pool) (*model.
 }
 
 // UpdateTask обновление данных задачи.
-func UpdateTask(ctx context.Context, task model.Task, pool *pgxpool.Pool) error {
-	tx, conn, err := NewTransactionManager(pool).BeginTransaction(ctx, pgx.RepeatableRead)
+// Обновление выполняется только если версия в базе совпадает с task.Version,
+// иначе возвращается ErrVersionConflict. Если задачи нет или она в корзине, возвращается ErrNotFound.
+// Возвращает новую версию задачи.
+func UpdateTask(ctx context.Context, task model.Task, tm *TransactionManager) (int64, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.RepeatableRead)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = tx.Exec(ctx, `UPDATE tasks SET title = $2, note = $3, done = $4, updated_at = $5 WHERE id = $1`,
-		task.ID, task.Title, task.Note, task.Done, task.UpdatedAt)
+	var version int64
+	err = tx.QueryRow(ctx, `UPDATE tasks SET title = $2, note = $3, done = $4, updated_at = $5, version = version + 1,
+			  search_vector = setweight(to_tsvector(language, $2), 'A') || setweight(to_tsvector(language, $3), 'B')
+			  FROM search_settings
+			  WHERE id = $1 AND version = $6 AND deleted_at IS NULL RETURNING version`,
+		task.ID, task.Title, task.Note, task.Done, task.UpdatedAt, task.Version).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = taskMissingError(ctx, tx, task.ID)
+	}
 	if err != nil {
-		if rollbackErr := NewTransactionManager(pool).RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
-		return fmt.Errorf("ошибка обновления задачи с ID %d: %w", task.ID, err)
+		return 0, fmt.Errorf("ошибка обновления задачи с ID %d: %w", task.ID, err)
 	}
 
-	if err = NewTransactionManager(pool).CommitTransaction(ctx, tx, conn); err != nil {
-		return fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
+		return 0, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
 	}
 
-	return nil
+	return version, nil
 }
 
-// DeleteTask удаляет задачу.
-func DeleteTask(ctx context.Context, taskID int64, pool *pgxpool.Pool) error {
-	tx, conn, err := NewTransactionManager(pool).BeginTransaction(ctx, pgx.Serializable)
+// DeleteTask перемещает задачу в корзину и возвращает ID ее владельца.
+// Если expectedVersion больше нуля, задача удаляется только при совпадении версии,
+// иначе возвращается ErrVersionConflict. Если задачи нет или она уже в корзине, возвращается ErrNotFound.
+func DeleteTask(ctx context.Context, taskID, expectedVersion int64, tm *TransactionManager) (int64, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.Serializable)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = tx.Exec(ctx, `DELETE FROM tasks WHERE id = $1`, taskID)
+	var userID int64
+	err = tx.QueryRow(ctx, `UPDATE tasks SET deleted_at = now(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::BIGINT = 0 OR version = $2) RETURNING user_id`,
+		taskID, expectedVersion).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = taskMissingError(ctx, tx, taskID)
+	}
 	if err != nil {
-		if rollbackErr := NewTransactionManager(pool).RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
-		return fmt.Errorf("ошибка удаления задачи с ID %d: %w", taskID, err)
+		return 0, fmt.Errorf("ошибка удаления задачи с ID %d: %w", taskID, err)
 	}
 
-	if err = NewTransactionManager(pool).CommitTransaction(ctx, tx, conn); err != nil {
-		return fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
+		return 0, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
 	}
 
-	return nil
+	return userID, nil
+}
+
+// taskMissingError определяет, почему изменение задачи с проверкой версии не затронуло ни одной строки:
+// ErrNotFound, если задачи нет или она в корзине, иначе ErrVersionConflict.
+func taskMissingError(ctx context.Context, tx pgx.Tx, taskID int64) error {
+	var exists bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND deleted_at IS NULL)`, taskID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки существования задачи: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("задача с ID %d не найдена: %w", taskID, ErrNotFound)
+	}
+	return fmt.Errorf("задача с ID %d была изменена: %w", taskID, ErrVersionConflict)
 }
 
 // GetAllTasks извлекает все задания.
-func GetAllTasks(ctx context.Context, pool *pgxpool.Pool) ([]model.Task, error) {
-	tx, conn, err := NewTransactionManager(pool).BeginTransaction(ctx, pgx.Serializable)
+func GetAllTasks(ctx context.Context, tm *TransactionManager) ([]model.Task, error) {
+	tx, conn, err := tm.BeginTransaction(ctx, pgx.Serializable)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := tx.Query(ctx, `SELECT id, user_id, title, note, done, created_at, updated_at FROM tasks`)
+	rows, err := tx.Query(ctx, `SELECT id, user_id, title, note, done, created_at, updated_at, version FROM tasks WHERE deleted_at IS NULL`)
 	if err != nil {
-		if rollbackErr := NewTransactionManager(pool).RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
 		return nil, fmt.Errorf("ошибка получения всех задач: %w", err)
 	}
@@ -128,10 +163,10 @@ func GetAllTasks(ctx context.Context, pool *pgxpool.Pool) ([]model.Task, error)
 	var tasks []model.Task
 	for rows.Next() {
 		var task model.Task
-		err := rows.Scan(&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt)
+		err := rows.Scan(&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt, &task.Version)
 		if err != nil {
-			if rollbackErr := NewTransactionManager(pool).RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-				log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 			}
 			return nil, fmt.Errorf("ошибка сканирования задачи: %w", err)
 		}
@@ -139,15 +174,302 @@ func GetAllTasks(ctx context.Context, pool *pgxpool.Pool) ([]model.Task, error)
 	}
 
 	if err = rows.Err(); err != nil {
-		if rollbackErr := NewTransactionManager(pool).RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
 		return nil, fmt.Errorf("ошибка итерации по строкам задач: %w", err)
 	}
 
-	if err = NewTransactionManager(pool).CommitTransaction(ctx, tx, conn); err != nil {
+	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
+		return nil, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ListTasks извлекает задачи, подходящие под фильтр, упорядоченные по ID.
+func ListTasks(ctx context.Context, filter model.TaskFilter, tm *TransactionManager) ([]model.Task, error) {
+	userIDs := filter.UserIDs
+	if userIDs == nil {
+		userIDs = []int64{}
+	}
+	// LIMIT NULL в PostgreSQL снимает ограничение на количество строк
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	tx, conn, err := tm.BeginTransaction(ctx, pgx.RepeatableRead)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id, user_id, title, note, done, created_at, updated_at, version FROM tasks
+		WHERE deleted_at IS NULL
+		  AND (cardinality($1::BIGINT[]) = 0 OR user_id = ANY($1))
+		  AND ($2::BOOLEAN IS NULL OR done = $2)
+		ORDER BY id
+		LIMIT $3 OFFSET $4`, userIDs, filter.Done, limit, filter.Offset)
+	if err != nil {
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+		}
+		return nil, fmt.Errorf("ошибка получения списка задач: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]model.Task, 0)
+	for rows.Next() {
+		var task model.Task
+		err := rows.Scan(&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt, &task.Version)
+		if err != nil {
+			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+			}
+			return nil, fmt.Errorf("ошибка сканирования задачи: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+		}
+		return nil, fmt.Errorf("ошибка итерации по строкам задач: %w", err)
+	}
+
+	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
+		return nil, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// SearchTasks ищет задачи по названию и заметке и возвращает страницу результатов
+// по убыванию релевантности вместе с общим количеством найденных задач.
+func SearchTasks(ctx context.Context, search model.TaskSearch, tm *TransactionManager) ([]model.TaskSearchResult, int64, error) {
+	tx, conn, err := tm.BeginTransaction(ctx, pgx.RepeatableRead)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+			}
+		}
+	}()
+
+	var total int64
+	err = tx.QueryRow(ctx, `SELECT count(*) FROM tasks, search_settings
+		WHERE search_vector @@ websearch_to_tsquery(language, $1) AND deleted_at IS NULL AND ($2::BIGINT = 0 OR user_id = $2)`,
+		search.Query, search.UserID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка подсчета найденных задач: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id, user_id, title, note, done, created_at, updated_at, version,
+			ts_rank_cd(search_vector, query) AS rank,
+			ts_headline(language, title, query, 'HighlightAll=true'),
+			ts_headline(language, note, query, 'MaxFragments=2, MaxWords=20, MinWords=5')
+		FROM tasks, search_settings, websearch_to_tsquery(language, $1) AS query
+		WHERE search_vector @@ query AND deleted_at IS NULL AND ($2::BIGINT = 0 OR user_id = $2)
+		ORDER BY rank DESC, id
+		LIMIT $3 OFFSET $4`, search.Query, search.UserID, search.Limit, search.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска задач: %w", err)
+	}
+
+	results := make([]model.TaskSearchResult, 0)
+	for rows.Next() {
+		var result model.TaskSearchResult
+		task := &result.Task
+		err = rows.Scan(&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt, &task.Version,
+			&result.Rank, &result.TitleHighlight, &result.NoteHighlight)
+		if err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("ошибка сканирования найденной задачи: %w", err)
+		}
+		results = append(results, result)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка итерации по найденным задачам: %w", err)
+	}
+
+	if commitErr := tm.CommitTransaction(ctx, tx, conn); commitErr != nil {
+		return nil, 0, fmt.Errorf("ошибка подтверждения транзакции: %w", commitErr)
+	}
+
+	return results, total, nil
+}
+
+// ReindexSearch сверяет язык поиска language, например russian или english, с языком, с которым
+// построены поисковые векторы задач. Если они различаются, векторы всех задач, включая удаленные в корзину,
+// перестраиваются с новым языком в одной транзакции под блокировкой таблицы задач.
+// Возвращает число перестроенных задач, 0 — язык не изменился.
+func ReindexSearch(ctx context.Context, language string, tm *TransactionManager) (int64, error) {
+	// Если язык не изменился, таблица задач не блокируется
+	changed, err := searchLanguageChanged(ctx, tm.pool, language)
+	if err != nil || !changed {
+		return 0, err
+	}
+
+	tx, conn, err := tm.BeginTransaction(ctx, pgx.ReadCommitted)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+			}
+		}
+	}()
+
+	// Блокировка не дает создавать и изменять задачи, пока векторы перестраиваются,
+	// поэтому ни одна задача не сохранится с вектором на прежнем языке
+	if _, err = tx.Exec(ctx, `LOCK TABLE tasks IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return 0, fmt.Errorf("ошибка блокировки задач: %w", err)
+	}
+
+	// Язык мог смениться, пока ожидалась блокировка
+	if changed, err = searchLanguageChanged(ctx, tx, language); err != nil {
+		return 0, err
+	}
+
+	var reindexed int64
+	if changed {
+		var tag pgconn.CommandTag
+		tag, err = tx.Exec(ctx, `UPDATE tasks
+			SET search_vector = setweight(to_tsvector($1::regconfig, title), 'A') || setweight(to_tsvector($1::regconfig, note), 'B')`,
+			language)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка перестроения поисковых векторов: %w", err)
+		}
+		reindexed = tag.RowsAffected()
+
+		if _, err = tx.Exec(ctx, `UPDATE search_settings SET language = $1::regconfig`, language); err != nil {
+			return 0, fmt.Errorf("ошибка сохранения языка поиска: %w", err)
+		}
+	}
+
+	if commitErr := tm.CommitTransaction(ctx, tx, conn); commitErr != nil {
+		return 0, fmt.Errorf("ошибка подтверждения транзакции: %w", commitErr)
+	}
+
+	return reindexed, nil
+}
+
+// rowQuerier выполняет запрос, возвращающий одну строку: пул соединений или транзакция
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// searchLanguageChanged сообщает, что поисковые векторы задач построены не с языком language
+func searchLanguageChanged(ctx context.Context, q rowQuerier, language string) (bool, error) {
+	var changed bool
+	err := q.QueryRow(ctx, `SELECT language IS DISTINCT FROM $1::regconfig FROM search_settings`, language).Scan(&changed)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки языка поиска %q: %w", language, err)
+	}
+	return changed, nil
+}
+
+// RestoreTask возвращает задачу из корзины. Если задачи нет в корзине, возвращается ErrNotFound.
+func RestoreTask(ctx context.Context, taskID int64, tm *TransactionManager) (*model.Task, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.ReadCommitted)
+	if err != nil {
+		return nil, err
+	}
+
+	var task model.Task
+	err = tx.QueryRow(ctx, `UPDATE tasks SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING id, user_id, title, note, done, created_at, updated_at, version`, taskID).
+		Scan(&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt, &task.Version)
+	if err != nil {
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+		}
+		return nil, fmt.Errorf("ошибка восстановления задачи с ID %d: %w", taskID, err)
+	}
+
+	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
 		return nil, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
 	}
 
+	return &task, nil
+}
+
+// ListDeletedTasks извлекает задачи из корзины, последние удаленные первыми.
+// Если userID больше нуля, возвращаются только задачи этого пользователя.
+func ListDeletedTasks(ctx context.Context, userID int64, tm *TransactionManager) ([]model.Task, error) {
+	tx, conn, err := tm.BeginTransaction(ctx, pgx.RepeatableRead)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+			}
+		}
+	}()
+
+	rows, err := tx.Query(ctx, `SELECT id, user_id, title, note, done, created_at, updated_at, version, deleted_at FROM tasks
+		WHERE deleted_at IS NOT NULL AND ($1::BIGINT = 0 OR user_id = $1)
+		ORDER BY deleted_at DESC, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения задач из корзины: %w", err)
+	}
+
+	tasks := make([]model.Task, 0)
+	for rows.Next() {
+		var task model.Task
+		err = rows.Scan(&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt, &task.Version, &task.DeletedAt)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("ошибка сканирования задачи: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по строкам задач: %w", err)
+	}
+
+	if commitErr := tm.CommitTransaction(ctx, tx, conn); commitErr != nil {
+		return nil, fmt.Errorf("ошибка подтверждения транзакции: %w", commitErr)
+	}
+
 	return tasks, nil
 }
+
+// PurgeTasks окончательно удаляет задачи, перемещенные в корзину раньше before,
+// и возвращает количество удаленных задач.
+func PurgeTasks(ctx context.Context, before time.Time, tm *TransactionManager) (int64, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.ReadCommitted)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM tasks WHERE deleted_at < $1`, before)
+	if err != nil {
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+		}
+		return 0, fmt.Errorf("ошибка очистки корзины задач: %w", err)
+	}
+
+	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
+		return 0, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
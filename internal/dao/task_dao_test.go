@@ -0,0 +1,110 @@
+package dao_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"TODO/internal/dao"
+	"TODO/internal/migrate"
+	"TODO/internal/model"
+)
+
+// discardLog журнал тестов, записи которого отбрасываются
+var discardLog = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// testPool подключается к PostgreSQL из TEST_POSTGRES_DSN и применяет миграции.
+// Без TEST_POSTGRES_DSN тест пропускается. Тесты добавляют в базу свои данные, поэтому
+// база должна быть отдельной от рабочей.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN не задан")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("ошибка подключения к PostgreSQL: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrator, err := migrate.New(pool, discardLog)
+	if err != nil {
+		t.Fatalf("migrate.New: %v", err)
+	}
+	defer migrator.Close()
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate.Up: %v", err)
+	}
+	return pool
+}
+
+func TestSearchTasksLanguage(t *testing.T) {
+	tm := dao.NewTransactionManager(testPool(t), discardLog)
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		if _, err := dao.ReindexSearch(ctx, "russian", tm); err != nil {
+			t.Errorf("ReindexSearch: %v", err)
+		}
+	})
+
+	if _, err := dao.ReindexSearch(ctx, "russian", tm); err != nil {
+		t.Fatalf("ReindexSearch: %v", err)
+	}
+
+	user, err := dao.CreateUser(ctx, model.User{Username: fmt.Sprintf("search-%d", time.Now().UnixNano()), CreatedAt: time.Now()}, tm)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	createTask := func(title string) {
+		t.Helper()
+		now := time.Now()
+		if _, err := dao.CreateTask(ctx, model.Task{UserID: user.ID, Title: title, CreatedAt: now, UpdatedAt: now}, tm); err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+	}
+	assertFound := func(query string, want int64) {
+		t.Helper()
+		_, total, err := dao.SearchTasks(ctx, model.TaskSearch{Query: query, UserID: user.ID, Limit: 10}, tm)
+		if err != nil {
+			t.Fatalf("SearchTasks(%q): %v", query, err)
+		}
+		if total != want {
+			t.Errorf("SearchTasks(%q) нашел %d задач, ожидалось %d", query, total, want)
+		}
+	}
+
+	createTask("Купить молоко")
+
+	// Конфигурация russian приводит слова к основе, поэтому находит другие словоформы
+	assertFound("молока", 1)
+
+	// Конфигурация simple не приводит слова к основе: после смены языка существующая задача
+	// находится только по точной словоформе, как и задача, созданная после смены
+	reindexed, err := dao.ReindexSearch(ctx, "simple", tm)
+	if err != nil {
+		t.Fatalf("ReindexSearch: %v", err)
+	}
+	if reindexed == 0 {
+		t.Fatal("ReindexSearch не перестроил векторы после смены языка")
+	}
+	createTask("Нет молока")
+
+	assertFound("молоко", 1)
+	assertFound("молока", 1)
+
+	// Повторный запуск с тем же языком не перестраивает векторы
+	if reindexed, err := dao.ReindexSearch(ctx, "simple", tm); err != nil || reindexed != 0 {
+		t.Fatalf("повторный ReindexSearch = %d, %v, ожидалось 0, nil", reindexed, err)
+	}
+}
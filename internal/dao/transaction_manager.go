@@ -2,31 +2,170 @@ package dao
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"log/slog"
+	"sync"
+	"time"
 )
 
+// maxTxAttempts число попыток выполнить единицу работы при ошибках сериализации
+const maxTxAttempts = 3
+
+// Transactor выполняет функцию в единой транзакции. Вложенные вызовы присоединяются к внешней транзакции.
+type Transactor interface {
+	WithinTx(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) error
+}
+
+var _ Transactor = (*TransactionManager)(nil)
+
+// txKey ключ контекста, под которым хранится текущая единица работы
+type txKey struct{}
+
+// unitOfWork транзакция, общая для всех вызовов DAO внутри WithinTx,
+// и действия, которые выполняются после ее подтверждения
+type unitOfWork struct {
+	tx    pgx.Tx  // транзакция PostgreSQL, nil для остальных хранилищ
+	sqlTx *sql.Tx // транзакция SQLite, nil для остальных хранилищ
+
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+// unitOfWorkFromContext возвращает единицу работы из контекста
+func unitOfWorkFromContext(ctx context.Context) (*unitOfWork, bool) {
+	uow, ok := ctx.Value(txKey{}).(*unitOfWork)
+	return uow, ok
+}
+
+// InTx сообщает, выполняется ли вызов внутри WithinTx
+func InTx(ctx context.Context) bool {
+	_, ok := unitOfWorkFromContext(ctx)
+	return ok
+}
+
+// AfterCommit откладывает fn до подтверждения транзакции из контекста.
+// Если fn повторяется вместе с транзакцией или транзакция откатывается, отложенные действия отбрасываются.
+// Вне транзакции fn выполняется сразу.
+func AfterCommit(ctx context.Context, fn func()) {
+	uow, ok := unitOfWorkFromContext(ctx)
+	if !ok {
+		fn()
+		return
+	}
+	uow.mu.Lock()
+	defer uow.mu.Unlock()
+	uow.afterCommit = append(uow.afterCommit, fn)
+}
+
+// commit выполняет отложенные действия после подтверждения транзакции
+func (u *unitOfWork) commit() {
+	u.mu.Lock()
+	callbacks := u.afterCommit
+	u.afterCommit = nil
+	u.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
 // TransactionManager управляет транзакциями через пул соединений.
 type TransactionManager struct {
 	pool *pgxpool.Pool
+	log  *slog.Logger
 }
 
 // NewTransactionManager создает новый менеджер транзакций с использованием пула соединений.
-func NewTransactionManager(pool *pgxpool.Pool) *TransactionManager {
-	return &TransactionManager{pool: pool}
+// В log пишутся повторы транзакций и ошибки отката.
+func NewTransactionManager(pool *pgxpool.Pool, log *slog.Logger) *TransactionManager {
+	return &TransactionManager{pool: pool, log: log}
+}
+
+// WithinTx выполняет fn в одной транзакции, которая передается через контекст всем вызовам DAO внутри fn.
+// Если контекст уже содержит транзакцию, fn присоединяется к ней. При ошибке сериализации (SQLSTATE 40001)
+// транзакция повторяется целиком до maxTxAttempts раз, поэтому fn не должна иметь побочных эффектов
+// вне базы данных — их следует откладывать через AfterCommit.
+func (tm *TransactionManager) WithinTx(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	if InTx(ctx) {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = tm.runTx(ctx, opts, fn)
+		if !isSerializationFailure(err) {
+			return err
+		}
+		tm.log.WarnContext(ctx, "Ошибка сериализации транзакции, повторяем", "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 10 * time.Millisecond):
+		}
+	}
+	return fmt.Errorf("транзакция не выполнена после %d попыток: %w", maxTxAttempts, err)
+}
+
+// runTx выполняет одну попытку единицы работы
+func (tm *TransactionManager) runTx(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	tx, err := tm.pool.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	uow := &unitOfWork{tx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, uow)); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	}
+	uow.commit()
+	return nil
+}
+
+// isSerializationFailure проверяет, что транзакцию прервал конфликт сериализации
+func isSerializationFailure(err error) bool {
+	return hasSQLState(err, serializationFailure)
 }
 
 // BeginTransaction начинает новую транзакцию с заданным уровнем изоляции.
+// На реплике транзакция начинается только для чтения, а Serializable заменяется на RepeatableRead.
+// Внутри WithinTx вместо новой транзакции создается точка сохранения во внешней,
+// а соединение не возвращается (nil), так как им владеет WithinTx.
 func (tm *TransactionManager) BeginTransaction(ctx context.Context, isoLevel pgx.TxIsoLevel) (pgx.Tx, *pgxpool.Conn, error) {
+	if uow, ok := unitOfWorkFromContext(ctx); ok && uow.tx != nil {
+		tx, err := uow.tx.Begin(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ошибка создания точки сохранения: %w", err)
+		}
+		return tx, nil, nil
+	}
+
 	conn, err := tm.pool.Acquire(ctx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("ошибка получения соединения из пула: %w", err)
 	}
 
+	accessMode := pgx.ReadWrite
+	if onReplica(ctx) {
+		accessMode = pgx.ReadOnly
+		if isoLevel == pgx.Serializable {
+			isoLevel = pgx.RepeatableRead
+		}
+	}
+
 	tx, err := conn.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:   isoLevel,
-		AccessMode: pgx.ReadWrite,
+		AccessMode: accessMode,
 	})
 	if err != nil {
 		conn.Release()
@@ -36,20 +175,25 @@ func (tm *TransactionManager) BeginTransaction(ctx context.Context, isoLevel pgx
 	return tx, conn, nil
 }
 
-// CommitTransaction подтверждает транзакцию и освобождает соединение обратно в пул.
+// CommitTransaction подтверждает транзакцию и освобождает соединение обратно в пул, в том числе при ошибке.
+// После вызова транзакция завершена: откатывать ее не нужно, даже если подтверждение не удалось.
 func (tm *TransactionManager) CommitTransaction(ctx context.Context, tx pgx.Tx, conn *pgxpool.Conn) error {
+	if conn != nil {
+		defer conn.Release()
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("ошибка подтверждения транзакции: %w", err)
 	}
-	conn.Release()
 	return nil
 }
 
-// RollbackTransaction откатывает транзакцию и освобождает соединение обратно в пул.
+// RollbackTransaction откатывает транзакцию и освобождает соединение обратно в пул, в том числе при ошибке.
 func (tm *TransactionManager) RollbackTransaction(ctx context.Context, tx pgx.Tx, conn *pgxpool.Conn) error {
+	if conn != nil {
+		defer conn.Release()
+	}
 	if err := tx.Rollback(ctx); err != nil {
 		return fmt.Errorf("ошибка отката транзакции: %w", err)
 	}
-	conn.Release()
 	return nil
 }
@@ -0,0 +1,216 @@
+package dao_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+
+	"TODO/internal/dao"
+	"TODO/internal/migrate"
+	"TODO/internal/model"
+)
+
+// unitOfWork исполнитель единиц работы и хранилища одной реализации
+type unitOfWork struct {
+	tx    dao.Transactor
+	users dao.UserRepository
+	tasks dao.TaskRepository
+}
+
+// allUnitsOfWork реализации единицы работы, которые должны вести себя одинаково
+var allUnitsOfWork = map[string]func(t *testing.T) unitOfWork{
+	"sqlite": func(t *testing.T) unitOfWork {
+		path := filepath.Join(t.TempDir(), "todo.db")
+		migrator, err := migrate.NewSQLite(path, discardLog)
+		if err != nil {
+			t.Fatalf("migrate.NewSQLite: %v", err)
+		}
+		if err := migrator.Up(context.Background()); err != nil {
+			t.Fatalf("migrator.Up: %v", err)
+		}
+		if err := migrator.Close(); err != nil {
+			t.Fatalf("migrator.Close: %v", err)
+		}
+
+		db, err := dao.OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("dao.OpenSQLite: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		return unitOfWork{
+			tx:    dao.NewSQLiteTransactor(db, discardLog),
+			users: dao.NewSQLiteUserRepository(db, false, discardLog),
+			tasks: dao.NewSQLiteTaskRepository(db, discardLog),
+		}
+	},
+	"postgres": func(t *testing.T) unitOfWork {
+		pool := testPool(t)
+		return unitOfWork{
+			tx:    dao.NewTransactionManager(pool, discardLog),
+			users: dao.NewPgUserRepository(pool, nil, false, discardLog),
+			tasks: dao.NewPgTaskRepository(pool, nil, discardLog),
+		}
+	},
+}
+
+// uniqueUsername возвращает имя пользователя, не занятое предыдущими запусками тестов
+func uniqueUsername(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
+
+func TestWithinTxCommitAndRollback(t *testing.T) {
+	for name, newUnitOfWork := range allUnitsOfWork {
+		t.Run(name, func(t *testing.T) {
+			uow := newUnitOfWork(t)
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Second)
+
+			var committed *model.User
+			callbacks := 0
+			err := uow.tx.WithinTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(ctx context.Context) error {
+				if !dao.InTx(ctx) {
+					t.Error("InTx = false внутри WithinTx")
+				}
+				var err error
+				committed, err = uow.users.CreateUser(ctx, model.User{Username: uniqueUsername("commit"), CreatedAt: now})
+				dao.AfterCommit(ctx, func() { callbacks++ })
+				if callbacks != 0 {
+					t.Error("AfterCommit выполнил действие до подтверждения транзакции")
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatalf("WithinTx: %v", err)
+			}
+			if callbacks != 1 {
+				t.Errorf("отложенное действие выполнено %d раз, ожидался один", callbacks)
+			}
+			if _, err := uow.users.GetUserByID(ctx, committed.ID); err != nil {
+				t.Errorf("пользователь из подтвержденной транзакции не найден: %v", err)
+			}
+
+			var rolledBack *model.User
+			errFailed := errors.New("ошибка единицы работы")
+			err = uow.tx.WithinTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(ctx context.Context) error {
+				var err error
+				rolledBack, err = uow.users.CreateUser(ctx, model.User{Username: uniqueUsername("rollback"), CreatedAt: now})
+				if err != nil {
+					return err
+				}
+				dao.AfterCommit(ctx, func() { callbacks++ })
+				return errFailed
+			})
+			if !errors.Is(err, errFailed) {
+				t.Fatalf("WithinTx = %v, ожидалась %v", err, errFailed)
+			}
+			if callbacks != 1 {
+				t.Error("отложенное действие выполнено после отката транзакции")
+			}
+			if _, err := uow.users.GetUserByID(ctx, rolledBack.ID); !errors.Is(err, dao.ErrNotFound) {
+				t.Errorf("пользователь из откаченной транзакции: ошибка %v, ожидалась ErrNotFound", err)
+			}
+		})
+	}
+}
+
+// Ошибка вызова DAO внутри единицы работы откатывает только его точку сохранения:
+// единица работы может обработать ошибку и продолжиться
+func TestWithinTxSavepoint(t *testing.T) {
+	for name, newUnitOfWork := range allUnitsOfWork {
+		t.Run(name, func(t *testing.T) {
+			uow := newUnitOfWork(t)
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Second)
+			username := uniqueUsername("savepoint")
+
+			var user *model.User
+			var task *model.Task
+			err := uow.tx.WithinTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(ctx context.Context) error {
+				var err error
+				if user, err = uow.users.CreateUser(ctx, model.User{Username: username, CreatedAt: now}); err != nil {
+					return err
+				}
+				if _, err := uow.users.CreateUser(ctx, model.User{Username: username, CreatedAt: now}); !errors.Is(err, dao.ErrUsernameTaken) {
+					return fmt.Errorf("повторное имя: ошибка %v, ожидалась ErrUsernameTaken", err)
+				}
+
+				// Вложенная единица работы присоединяется к внешней
+				return uow.tx.WithinTx(ctx, pgx.TxOptions{}, func(ctx context.Context) error {
+					task, err = uow.tasks.CreateTask(ctx, model.Task{UserID: user.ID, Title: "после ошибки", CreatedAt: now, UpdatedAt: now})
+					return err
+				})
+			})
+			if err != nil {
+				t.Fatalf("WithinTx: %v", err)
+			}
+
+			if _, err := uow.users.GetUserByID(ctx, user.ID); err != nil {
+				t.Errorf("пользователь, созданный до ошибки, не найден: %v", err)
+			}
+			if _, err := uow.tasks.GetTaskByID(ctx, task.ID); err != nil {
+				t.Errorf("задача, созданная после ошибки, не найдена: %v", err)
+			}
+		})
+	}
+}
+
+func TestWithinTxRetriesSerializationFailure(t *testing.T) {
+	tm := dao.NewTransactionManager(testPool(t), discardLog)
+	ctx := context.Background()
+
+	attempts, callbacks := 0, 0
+	err := tm.WithinTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(ctx context.Context) error {
+		attempts++
+		dao.AfterCommit(ctx, func() { callbacks++ })
+		if attempts == 1 {
+			return &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("попыток %d, ожидалось 2", attempts)
+	}
+	if callbacks != 1 {
+		t.Errorf("отложенные действия выполнены %d раз, ожидался один: действия неудачной попытки отбрасываются", callbacks)
+	}
+}
+
+func TestWithinTxRetryLimit(t *testing.T) {
+	tm := dao.NewTransactionManager(testPool(t), discardLog)
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		err          error
+		wantAttempts int
+	}{
+		{name: "ошибка сериализации", err: &pgconn.PgError{Code: "40001"}, wantAttempts: 3},
+		{name: "прочая ошибка", err: errors.New("ошибка единицы работы"), wantAttempts: 1},
+		{name: "нарушение уникальности", err: &pgconn.PgError{Code: "23505"}, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := tm.WithinTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(ctx context.Context) error {
+				attempts++
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("WithinTx = %v, ожидалась %v", err, tt.err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("попыток %d, ожидалось %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -3,56 +3,56 @@ package dao
 import (
 	"TODO/internal/model"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
-	"log"
+	"time"
 )
 
-// CreateUser создает нового пользователя.
-func CreateUser(ctx context.Context, user model.User, pool *pgxpool.Pool) (int64, error) {
-	tm := NewTransactionManager(pool)
-	tx, conn, err := tm.BeginTransaction(ctx, pgx.ReadCommitted)
+// CreateUser создает нового пользователя и возвращает его с присвоенными ID и версией.
+func CreateUser(ctx context.Context, user model.User, tm *TransactionManager) (*model.User, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.ReadCommitted)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	defer func() {
 		if err != nil {
 			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-				log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 			}
 		}
 	}()
 
-	query := `INSERT INTO users (username, created_at) VALUES ($1, $2) RETURNING id`
-	var userID int64
-	err = tx.QueryRow(ctx, query, user.Username, user.CreatedAt).Scan(&userID)
+	query := `INSERT INTO users (username, created_at) VALUES ($1, $2) RETURNING id, version`
+	err = tx.QueryRow(ctx, query, user.Username, user.CreatedAt).Scan(&user.ID, &user.Version)
+	if hasSQLState(err, uniqueViolation) {
+		err = fmt.Errorf("пользователь %q уже существует: %w", user.Username, ErrUsernameTaken)
+	}
 	if err != nil {
-		return 0, fmt.Errorf("ошибка создания пользователя: %w", err)
+		return nil, fmt.Errorf("ошибка создания пользователя: %w", err)
 	}
 
-	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
-		return 0, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	if commitErr := tm.CommitTransaction(ctx, tx, conn); commitErr != nil {
+		return nil, fmt.Errorf("ошибка подтверждения транзакции: %w", commitErr)
 	}
 
-	return userID, nil
+	return &user, nil
 }
 
 // GetUserByID возвращает пользователя по его ID.
-func GetUserByID(ctx context.Context, userID int64, pool *pgxpool.Pool) (*model.User, error) {
-	tm := NewTransactionManager(pool)
+func GetUserByID(ctx context.Context, userID int64, tm *TransactionManager) (*model.User, error) {
 	tx, conn, err := tm.BeginTransaction(ctx, pgx.RepeatableRead)
 	if err != nil {
 		return nil, err
 	}
 
 	var user model.User
-	err = tx.QueryRow(ctx, `SELECT id, username, created_at FROM users WHERE id = $1`, userID).
-		Scan(&user.ID, &user.Username, &user.CreatedAt)
+	err = tx.QueryRow(ctx, `SELECT id, username, created_at, version FROM users WHERE id = $1 AND deleted_at IS NULL`, userID).
+		Scan(&user.ID, &user.Username, &user.CreatedAt, &user.Version)
 	if err != nil {
 		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
 		return nil, fmt.Errorf("ошибка получения пользователя с ID %d: %w", userID, err)
 	}
@@ -65,63 +65,99 @@ func GetUserByID(ctx context.Context, userID int64, pool *pgxpool.Pool) (*model.
 }
 
 // UpdateUser обновляет данные пользователя.
-func UpdateUser(ctx context.Context, user model.User, pool *pgxpool.Pool) error {
-	tm := NewTransactionManager(pool)
-	tx, conn, err := tm.BeginTransaction(ctx, pgx.RepeatableRead)
+// Обновление выполняется только если версия в базе совпадает с user.Version,
+// иначе возвращается ErrVersionConflict. Возвращает новую версию пользователя.
+func UpdateUser(ctx context.Context, user model.User, tm *TransactionManager) (int64, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.RepeatableRead)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = tx.Exec(ctx, `UPDATE users SET username = $2 WHERE id = $1`, user.ID, user.Username)
+	var version int64
+	err = tx.QueryRow(ctx, `UPDATE users SET username = $2, version = version + 1 WHERE id = $1 AND version = $3 AND deleted_at IS NULL RETURNING version`,
+		user.ID, user.Username, user.Version).Scan(&version)
 	if err != nil {
 		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
-			return fmt.Errorf("ошибка обновления пользователя с ID %d: %w", user.ID, err)
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("пользователь с ID %d был изменен: %w", user.ID, ErrVersionConflict)
 		}
+		if hasSQLState(err, uniqueViolation) {
+			return 0, fmt.Errorf("пользователь %q уже существует: %w", user.Username, ErrUsernameTaken)
+		}
+		return 0, fmt.Errorf("ошибка обновления пользователя с ID %d: %w", user.ID, err)
 	}
 
 	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
-		return fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+		return 0, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
 	}
 
-	return nil
+	return version, nil
 }
 
-// DeleteUser удаляет пользователя по его ID.
-func DeleteUser(ctx context.Context, userID int64, pool *pgxpool.Pool) error {
-	tm := NewTransactionManager(pool)
-	tx, conn, err := tm.BeginTransaction(ctx, pgx.Serializable)
+// DeleteUser перемещает пользователя в корзину и возвращает ID задач, перемещенных вместе с ним.
+// Если cascadeTasks равен true, вместе с пользователем в корзину перемещаются его задачи
+// с тем же временем удаления, иначе при наличии задач возвращается ErrUserHasTasks.
+func DeleteUser(ctx context.Context, userID int64, cascadeTasks bool, tm *TransactionManager) ([]int64, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.Serializable)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	// now() возвращает время начала транзакции, поэтому пользователь и его задачи получают одинаковое deleted_at
+	taskIDs := make([]int64, 0)
+	if cascadeTasks {
+		var rows pgx.Rows
+		rows, err = tx.Query(ctx, `UPDATE tasks SET deleted_at = now(), version = version + 1 WHERE user_id = $1 AND deleted_at IS NULL RETURNING id`, userID)
+		if err == nil {
+			for rows.Next() {
+				var taskID int64
+				if err = rows.Scan(&taskID); err != nil {
+					break
+				}
+				taskIDs = append(taskIDs, taskID)
+			}
+			rows.Close()
+			if err == nil {
+				err = rows.Err()
+			}
+		}
+	} else {
+		var hasTasks bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE user_id = $1 AND deleted_at IS NULL)`, userID).Scan(&hasTasks)
+		if err == nil && hasTasks {
+			err = fmt.Errorf("пользователь с ID %d: %w", userID, ErrUserHasTasks)
+		}
+	}
+	if err == nil {
+		_, err = tx.Exec(ctx, `UPDATE users SET deleted_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL`, userID)
+	}
 	if err != nil {
 		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
-		return fmt.Errorf("ошибка удаления пользователя с ID %d: %w", userID, err)
+		return nil, fmt.Errorf("ошибка удаления пользователя с ID %d: %w", userID, err)
 	}
 
 	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
-		return fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+		return nil, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
 	}
 
-	return nil
+	return taskIDs, nil
 }
 
 // GetAllUsers возвращает список всех пользователей.
-func GetAllUsers(ctx context.Context, pool *pgxpool.Pool) ([]model.User, error) {
-	tm := NewTransactionManager(pool)
+func GetAllUsers(ctx context.Context, tm *TransactionManager) ([]model.User, error) {
 	tx, conn, err := tm.BeginTransaction(ctx, pgx.Serializable)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := tx.Query(ctx, `SELECT id, username, created_at FROM users`)
+	rows, err := tx.Query(ctx, `SELECT id, username, created_at, version FROM users WHERE deleted_at IS NULL`)
 	if err != nil {
 		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
 		return nil, fmt.Errorf("ошибка получения пользователей: %w", err)
 	}
@@ -130,10 +166,10 @@ func GetAllUsers(ctx context.Context, pool *pgxpool.Pool) ([]model.User, error)
 	var users []model.User
 	for rows.Next() {
 		var user model.User
-		err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt)
+		err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt, &user.Version)
 		if err != nil {
 			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-				log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 			}
 			return nil, fmt.Errorf("ошибка сканирования пользователя: %w", err)
 		}
@@ -142,7 +178,7 @@ func GetAllUsers(ctx context.Context, pool *pgxpool.Pool) ([]model.User, error)
 
 	if err = rows.Err(); err != nil {
 		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
 		return nil, fmt.Errorf("ошибка итерации по строкам пользователей: %w", err)
 	}
@@ -155,26 +191,168 @@ func GetAllUsers(ctx context.Context, pool *pgxpool.Pool) ([]model.User, error)
 }
 
 // GetUserNameByID returns the username of a user by their ID.
-func GetUserNameByID(ctx context.Context, userID int64, pool *pgxpool.Pool) (string, error) {
+func GetUserNameByID(ctx context.Context, userID int64, tm *TransactionManager) (string, error) {
 
-	tx, conn, err := NewTransactionManager(pool).BeginTransaction(ctx, pgx.RepeatableRead)
+	tx, conn, err := tm.BeginTransaction(ctx, pgx.RepeatableRead)
 	if err != nil {
 		return "", err
 	}
 
 	var username string
-	err = tx.QueryRow(ctx, `SELECT username FROM users WHERE id = $1`, userID).Scan(&username)
+	err = tx.QueryRow(ctx, `SELECT username FROM users WHERE id = $1 AND deleted_at IS NULL`, userID).Scan(&username)
 	if err != nil {
 
-		if rollbackErr := NewTransactionManager(pool).RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
-			log.Printf("Ошибка отката транзакции: %v", rollbackErr)
+		if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+			tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
 		}
 		return "", fmt.Errorf("ошибка получения имени пользователя с ID %d: %w", userID, err)
 	}
 
-	if err = NewTransactionManager(pool).CommitTransaction(ctx, tx, conn); err != nil {
+	if err = tm.CommitTransaction(ctx, tx, conn); err != nil {
 		return "", fmt.Errorf("ошибка подтверждения транзакции: %w", err)
 	}
 
 	return username, nil
 }
+
+// RestoreUser возвращает пользователя из корзины вместе с задачами, удаленными вместе с ним,
+// и возвращает восстановленные задачи.
+// Если пользователя нет в корзине, возвращается ErrNotFound, если его имя занято — ErrUsernameTaken.
+func RestoreUser(ctx context.Context, userID int64, tm *TransactionManager) (*model.User, []model.Task, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.ReadCommitted)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+			}
+		}
+	}()
+
+	var user model.User
+	var deletedAt time.Time
+	err = tx.QueryRow(ctx, `SELECT username, deleted_at FROM users WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, userID).
+		Scan(&user.Username, &deletedAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка восстановления пользователя с ID %d: %w", userID, err)
+	}
+
+	err = tx.QueryRow(ctx, `UPDATE users SET deleted_at = NULL, version = version + 1 WHERE id = $1
+		RETURNING id, username, created_at, version`, userID).
+		Scan(&user.ID, &user.Username, &user.CreatedAt, &user.Version)
+	if hasSQLState(err, uniqueViolation) {
+		err = fmt.Errorf("пользователь %q уже существует: %w", user.Username, ErrUsernameTaken)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка восстановления пользователя с ID %d: %w", userID, err)
+	}
+
+	rows, err := tx.Query(ctx, `UPDATE tasks SET deleted_at = NULL, version = version + 1 WHERE user_id = $1 AND deleted_at = $2
+		RETURNING id, user_id, title, note, done, created_at, updated_at, version`, userID, deletedAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка восстановления задач пользователя с ID %d: %w", userID, err)
+	}
+
+	tasks := make([]model.Task, 0)
+	for rows.Next() {
+		var task model.Task
+		if err = rows.Scan(&task.ID, &task.UserID, &task.Title, &task.Note, &task.Done, &task.CreatedAt, &task.UpdatedAt, &task.Version); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("ошибка сканирования задачи: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("ошибка восстановления задач пользователя с ID %d: %w", userID, err)
+	}
+
+	if commitErr := tm.CommitTransaction(ctx, tx, conn); commitErr != nil {
+		return nil, nil, fmt.Errorf("ошибка подтверждения транзакции: %w", commitErr)
+	}
+
+	return &user, tasks, nil
+}
+
+// ListDeletedUsers возвращает пользователей из корзины, последние удаленные первыми.
+// Если userID больше нуля, возвращается только этот пользователь.
+func ListDeletedUsers(ctx context.Context, userID int64, tm *TransactionManager) ([]model.User, error) {
+	tx, conn, err := tm.BeginTransaction(ctx, pgx.RepeatableRead)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+			}
+		}
+	}()
+
+	rows, err := tx.Query(ctx, `SELECT id, username, created_at, version, deleted_at FROM users
+		WHERE deleted_at IS NOT NULL AND ($1::BIGINT = 0 OR id = $1)
+		ORDER BY deleted_at DESC, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей из корзины: %w", err)
+	}
+
+	users := make([]model.User, 0)
+	for rows.Next() {
+		var user model.User
+		err = rows.Scan(&user.ID, &user.Username, &user.CreatedAt, &user.Version, &user.DeletedAt)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("ошибка сканирования пользователя: %w", err)
+		}
+		users = append(users, user)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по строкам пользователей: %w", err)
+	}
+
+	if commitErr := tm.CommitTransaction(ctx, tx, conn); commitErr != nil {
+		return nil, fmt.Errorf("ошибка подтверждения транзакции: %w", commitErr)
+	}
+
+	return users, nil
+}
+
+// PurgeUsers окончательно удаляет пользователей, перемещенных в корзину раньше before, вместе со всеми их задачами
+// и возвращает количество удаленных пользователей.
+func PurgeUsers(ctx context.Context, before time.Time, tm *TransactionManager) (int64, error) {
+	tx, conn, err := beginAuditedTransaction(ctx, tm, pgx.ReadCommitted)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tm.RollbackTransaction(ctx, tx, conn); rollbackErr != nil {
+				tm.log.ErrorContext(ctx, "Ошибка отката транзакции", "error", rollbackErr)
+			}
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `DELETE FROM tasks WHERE user_id IN (SELECT id FROM users WHERE deleted_at < $1)`, before)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки задач удаленных пользователей: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM users WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки корзины пользователей: %w", err)
+	}
+
+	if commitErr := tm.CommitTransaction(ctx, tx, conn); commitErr != nil {
+		return 0, fmt.Errorf("ошибка подтверждения транзакции: %w", commitErr)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,170 @@
+package dao
+
+import (
+	"TODO/internal/model"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const webhookColumns = `id, url, secret, event_types, COALESCE(user_id, 0), active, failure_count, created_at, updated_at, disabled_at`
+
+// scanWebhook читает подписку из строки результата с колонками webhookColumns
+func scanWebhook(row pgx.Row) (*model.Webhook, error) {
+	var webhook model.Webhook
+	err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &webhook.EventTypes, &webhook.UserID,
+		&webhook.Active, &webhook.FailureCount, &webhook.CreatedAt, &webhook.UpdatedAt, &webhook.DisabledAt)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// CreateWebhook создает подписку на события задач.
+func CreateWebhook(ctx context.Context, webhook model.Webhook, pool *pgxpool.Pool) (*model.Webhook, error) {
+	created, err := scanWebhook(pool.QueryRow(ctx,
+		`INSERT INTO webhook_subscriptions (url, secret, event_types, user_id)
+		 VALUES ($1, $2, $3, NULLIF($4, 0)) RETURNING `+webhookColumns,
+		webhook.URL, webhook.Secret, webhook.EventTypes, webhook.UserID))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания подписки: %w", err)
+	}
+	return created, nil
+}
+
+// GetWebhookByID возвращает подписку по ID.
+func GetWebhookByID(ctx context.Context, webhookID int64, pool *pgxpool.Pool) (*model.Webhook, error) {
+	webhook, err := scanWebhook(pool.QueryRow(ctx,
+		`SELECT `+webhookColumns+` FROM webhook_subscriptions WHERE id = $1`, webhookID))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения подписки с ID %d: %w", webhookID, err)
+	}
+	return webhook, nil
+}
+
+// GetAllWebhooks возвращает все подписки.
+func GetAllWebhooks(ctx context.Context, pool *pgxpool.Pool) ([]model.Webhook, error) {
+	return queryWebhooks(ctx, pool, `SELECT `+webhookColumns+` FROM webhook_subscriptions ORDER BY id`)
+}
+
+// GetActiveWebhooksForEvent возвращает активные подписки на тип события,
+// относящиеся ко всем пользователям или к пользователю userID.
+func GetActiveWebhooksForEvent(ctx context.Context, eventType string, userID int64, pool *pgxpool.Pool) ([]model.Webhook, error) {
+	return queryWebhooks(ctx, pool, `SELECT `+webhookColumns+` FROM webhook_subscriptions
+		WHERE active AND $1 = ANY(event_types) AND (user_id IS NULL OR user_id = $2)
+		ORDER BY id`, eventType, userID)
+}
+
+// queryWebhooks выполняет запрос, возвращающий колонки webhookColumns
+func queryWebhooks(ctx context.Context, pool *pgxpool.Pool, query string, args ...interface{}) ([]model.Webhook, error) {
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения подписок: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := make([]model.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования подписки: %w", err)
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по строкам подписок: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook обновляет адрес, типы событий, фильтр и активность подписки.
+// Повторное включение подписки сбрасывает счетчик неудачных доставок.
+func UpdateWebhook(ctx context.Context, webhook model.Webhook, pool *pgxpool.Pool) (*model.Webhook, error) {
+	updated, err := scanWebhook(pool.QueryRow(ctx,
+		`UPDATE webhook_subscriptions
+		 SET url = $2, event_types = $3, user_id = NULLIF($4, 0), active = $5, updated_at = CURRENT_TIMESTAMP,
+		     failure_count = CASE WHEN $5 AND NOT active THEN 0 ELSE failure_count END,
+		     disabled_at = CASE WHEN $5 THEN NULL ELSE disabled_at END
+		 WHERE id = $1 RETURNING `+webhookColumns,
+		webhook.ID, webhook.URL, webhook.EventTypes, webhook.UserID, webhook.Active))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обновления подписки с ID %d: %w", webhook.ID, err)
+	}
+	return updated, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом доставок.
+// Если подписка не найдена, возвращается pgx.ErrNoRows.
+func DeleteWebhook(ctx context.Context, webhookID int64, pool *pgxpool.Pool) error {
+	tag, err := pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, webhookID)
+	if err == nil && tag.RowsAffected() == 0 {
+		err = pgx.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка удаления подписки с ID %d: %w", webhookID, err)
+	}
+	return nil
+}
+
+// RecordWebhookResult обновляет счетчик неудач подписки по итогу доставки события.
+// После maxFailures неудач подряд подписка отключается. Возвращает true, если подписка была отключена.
+func RecordWebhookResult(ctx context.Context, webhookID int64, success bool, maxFailures int, pool *pgxpool.Pool) (bool, error) {
+	var active bool
+	err := pool.QueryRow(ctx,
+		`UPDATE webhook_subscriptions
+		 SET failure_count = CASE WHEN $2 THEN 0 ELSE failure_count + 1 END,
+		     active = active AND ($2 OR $3 <= 0 OR failure_count + 1 < $3),
+		     disabled_at = CASE WHEN active AND NOT ($2 OR $3 <= 0 OR failure_count + 1 < $3)
+		                        THEN CURRENT_TIMESTAMP ELSE disabled_at END
+		 WHERE id = $1 RETURNING active`,
+		webhookID, success, maxFailures).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("ошибка обновления счетчика неудач подписки с ID %d: %w", webhookID, err)
+	}
+	return !active && !success, nil
+}
+
+// CreateWebhookDelivery записывает попытку доставки в журнал.
+func CreateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx,
+		`INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, attempt, status_code, success, error, duration_ms)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Attempt,
+		delivery.StatusCode, delivery.Success, delivery.Error, delivery.DurationMs)
+	if err != nil {
+		return fmt.Errorf("ошибка записи доставки подписки с ID %d: %w", delivery.WebhookID, err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries возвращает журнал доставок подписки, начиная с последних попыток.
+func GetWebhookDeliveries(ctx context.Context, webhookID int64, limit, offset int, pool *pgxpool.Pool) ([]model.WebhookDelivery, error) {
+	rows, err := pool.Query(ctx,
+		`SELECT id, subscription_id, event_id, event_type, attempt, status_code, success, error, duration_ms, created_at
+		 FROM webhook_deliveries WHERE subscription_id = $1
+		 ORDER BY id DESC LIMIT $2 OFFSET $3`, webhookID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения доставок подписки с ID %d: %w", webhookID, err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]model.WebhookDelivery, 0)
+	for rows.Next() {
+		var delivery model.WebhookDelivery
+		err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Attempt,
+			&delivery.StatusCode, &delivery.Success, &delivery.Error, &delivery.DurationMs, &delivery.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования доставки: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по строкам доставок: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,59 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+
+	"TODO/internal/dao"
+	"TODO/internal/model"
+)
+
+func TestRecordWebhookResult(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+
+	webhook, err := dao.CreateWebhook(ctx, model.Webhook{
+		URL: "https://example.com/hook", Secret: "secret", EventTypes: []string{"task.created"}, Active: true,
+	}, pool)
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := dao.DeleteWebhook(ctx, webhook.ID, pool); err != nil {
+			t.Errorf("DeleteWebhook: %v", err)
+		}
+	})
+
+	const maxFailures = 2
+	steps := []struct {
+		success      bool
+		wantDisabled bool
+		wantActive   bool
+		wantFailures int
+	}{
+		{success: false, wantActive: true, wantFailures: 1},
+		{success: true, wantActive: true, wantFailures: 0},
+		{success: false, wantActive: true, wantFailures: 1},
+		{success: false, wantDisabled: true, wantActive: false, wantFailures: 2},
+		{success: false, wantActive: false, wantFailures: 3},
+	}
+
+	for i, step := range steps {
+		disabled, err := dao.RecordWebhookResult(ctx, webhook.ID, step.success, maxFailures, pool)
+		if err != nil {
+			t.Fatalf("шаг %d: RecordWebhookResult: %v", i, err)
+		}
+		if disabled != step.wantDisabled {
+			t.Errorf("шаг %d: disabled = %v, ожидалось %v", i, disabled, step.wantDisabled)
+		}
+
+		got, err := dao.GetWebhookByID(ctx, webhook.ID, pool)
+		if err != nil {
+			t.Fatalf("шаг %d: GetWebhookByID: %v", i, err)
+		}
+		if got.Active != step.wantActive || got.FailureCount != step.wantFailures || (got.DisabledAt != nil) == step.wantActive {
+			t.Errorf("шаг %d: active = %v, failure_count = %d, disabled_at = %v, ожидалось active = %v, failure_count = %d",
+				i, got.Active, got.FailureCount, got.DisabledAt, step.wantActive, step.wantFailures)
+		}
+	}
+}
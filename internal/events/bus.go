@@ -0,0 +1,146 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"TODO/internal/model"
+)
+
+// Операции над задачами, совпадают с операциями сообщений Kafka
+const (
+	OperationCreateTask  = "create-task"
+	OperationUpdateTask  = "update-task"
+	OperationDeleteTask  = "delete-task"
+	OperationRestoreTask = "restore-task"
+)
+
+// TaskEvent описывает изменение задачи внутри процесса
+type TaskEvent struct {
+	Operation string      `json:"operation"`      // Тип операции (create-task, update-task, delete-task, restore-task)
+	TaskID    int64       `json:"task_id"`        // ID задачи
+	UserID    int64       `json:"user_id"`        // ID владельца задачи
+	Task      *model.Task `json:"task,omitempty"` // Состояние задачи после изменения, пусто при удалении
+	Timestamp time.Time   `json:"timestamp"`      // Время изменения
+}
+
+// Типы событий для внешних подписчиков (WebSocket, webhooks)
+const (
+	TypeTaskCreated  = "task.created"
+	TypeTaskUpdated  = "task.updated"
+	TypeTaskDeleted  = "task.deleted"
+	TypeTaskRestored = "task.restored"
+)
+
+// eventTypes соответствие операций над задачами типам событий
+var eventTypes = map[string]string{
+	OperationCreateTask:  TypeTaskCreated,
+	OperationUpdateTask:  TypeTaskUpdated,
+	OperationDeleteTask:  TypeTaskDeleted,
+	OperationRestoreTask: TypeTaskRestored,
+}
+
+// Type возвращает тип события для внешних подписчиков
+func (e TaskEvent) Type() string {
+	return eventTypes[e.Operation]
+}
+
+// Bus раздает события о задачах подписчикам внутри процесса.
+// Публикация никогда не блокируется: подписчик, не успевающий читать события,
+// отключается и должен подписаться заново.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBus создает пустую шину событий
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[*Subscription]struct{})}
+}
+
+// Subscribe регистрирует подписчика с буфером на buffer событий.
+// Если filter задан, подписчик получает только события, для которых он возвращает true.
+func (b *Bus) Subscribe(buffer int, filter func(TaskEvent) bool) *Subscription {
+	sub := &Subscription{
+		bus:    b,
+		filter: filter,
+		events: make(chan TaskEvent, buffer),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Publish отправляет событие всем подписчикам. Подписчики с заполненным буфером отключаются.
+func (b *Bus) Publish(event TaskEvent) {
+	if b == nil {
+		return
+	}
+
+	var overflowed []*Subscription
+
+	b.mu.RLock()
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			overflowed = append(overflowed, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range overflowed {
+		sub.overflow()
+	}
+}
+
+// remove удаляет подписчика и закрывает его канал
+func (b *Bus) remove(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+// Subscription представляет подписку на события шины
+type Subscription struct {
+	bus        *Bus
+	filter     func(TaskEvent) bool
+	events     chan TaskEvent
+	mu         sync.Mutex
+	overflowed bool
+}
+
+// Events возвращает канал событий, который закрывается при отключении подписки
+func (s *Subscription) Events() <-chan TaskEvent {
+	return s.events
+}
+
+// Overflowed сообщает, что подписка отключена из-за переполнения буфера
+func (s *Subscription) Overflowed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.overflowed
+}
+
+// Close отменяет подписку
+func (s *Subscription) Close() {
+	s.bus.remove(s)
+}
+
+func (s *Subscription) overflow() {
+	s.mu.Lock()
+	s.overflowed = true
+	s.mu.Unlock()
+	s.bus.remove(s)
+}
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	v1 "TODO/internal/api/v1"
+	v2 "TODO/internal/api/v2"
+	"TODO/internal/ratelimit"
+)
+
+// registerConnect регистрирует обработчики протоколов Connect и gRPC-Web для APIService v1 и v2 и WebhookService.
+// Обработчики проксируют вызовы в gRPC сервер через conn, поэтому для браузерных клиентов
+// действует та же цепочка интерсепторов, что и для gRPC и JSON API.
+func registerConnect(mux *http.ServeMux, conn grpc.ClientConnInterface) {
+	clientV1 := v1.NewAPIServiceClient(conn)
+	mux.Handle(unaryProcedure(v1.APIService_CreateUser_FullMethodName, clientV1.CreateUser))
+	mux.Handle(unaryProcedure(v1.APIService_GetUser_FullMethodName, clientV1.GetUser))
+	mux.Handle(unaryProcedure(v1.APIService_GetAllUsers_FullMethodName, clientV1.GetAllUsers))
+	mux.Handle(unaryProcedure(v1.APIService_UpdateUser_FullMethodName, clientV1.UpdateUser))
+	mux.Handle(unaryProcedure(v1.APIService_DeleteUser_FullMethodName, clientV1.DeleteUser))
+	mux.Handle(unaryProcedure(v1.APIService_RestoreUser_FullMethodName, clientV1.RestoreUser))
+	mux.Handle(unaryProcedure(v1.APIService_CreateTask_FullMethodName, clientV1.CreateTask))
+	mux.Handle(unaryProcedure(v1.APIService_GetTask_FullMethodName, clientV1.GetTask))
+	mux.Handle(unaryProcedure(v1.APIService_GetAllTasks_FullMethodName, clientV1.GetAllTasks))
+	mux.Handle(unaryProcedure(v1.APIService_UpdateTask_FullMethodName, clientV1.UpdateTask))
+	mux.Handle(unaryProcedure(v1.APIService_DeleteTask_FullMethodName, clientV1.DeleteTask))
+	mux.Handle(unaryProcedure(v1.APIService_SearchTasks_FullMethodName, clientV1.SearchTasks))
+	mux.Handle(unaryProcedure(v1.APIService_RestoreTask_FullMethodName, clientV1.RestoreTask))
+	mux.Handle(unaryProcedure(v1.APIService_ListTrash_FullMethodName, clientV1.ListTrash))
+	mux.Handle(unaryProcedure(v1.APIService_GetTaskHistory_FullMethodName, clientV1.GetTaskHistory))
+	mux.Handle(unaryProcedure(v1.APIService_ListAuditEvents_FullMethodName, clientV1.ListAuditEvents))
+
+	clientV2 := v2.NewAPIServiceClient(conn)
+	mux.Handle(unaryProcedure(v2.APIService_CreateUser_FullMethodName, clientV2.CreateUser))
+	mux.Handle(unaryProcedure(v2.APIService_GetUser_FullMethodName, clientV2.GetUser))
+	mux.Handle(unaryProcedure(v2.APIService_GetAllUsers_FullMethodName, clientV2.GetAllUsers))
+	mux.Handle(unaryProcedure(v2.APIService_UpdateUser_FullMethodName, clientV2.UpdateUser))
+	mux.Handle(unaryProcedure(v2.APIService_DeleteUser_FullMethodName, clientV2.DeleteUser))
+	mux.Handle(unaryProcedure(v2.APIService_RestoreUser_FullMethodName, clientV2.RestoreUser))
+	mux.Handle(unaryProcedure(v2.APIService_CreateTask_FullMethodName, clientV2.CreateTask))
+	mux.Handle(unaryProcedure(v2.APIService_GetTask_FullMethodName, clientV2.GetTask))
+	mux.Handle(unaryProcedure(v2.APIService_GetAllTasks_FullMethodName, clientV2.GetAllTasks))
+	mux.Handle(unaryProcedure(v2.APIService_UpdateTask_FullMethodName, clientV2.UpdateTask))
+	mux.Handle(unaryProcedure(v2.APIService_DeleteTask_FullMethodName, clientV2.DeleteTask))
+	mux.Handle(unaryProcedure(v2.APIService_SearchTasks_FullMethodName, clientV2.SearchTasks))
+	mux.Handle(unaryProcedure(v2.APIService_RestoreTask_FullMethodName, clientV2.RestoreTask))
+	mux.Handle(unaryProcedure(v2.APIService_GetTaskHistory_FullMethodName, clientV2.GetTaskHistory))
+	mux.Handle(unaryProcedure(v2.APIService_ListTrash_FullMethodName, clientV2.ListTrash))
+	mux.Handle(unaryProcedure(v2.APIService_ListAuditEvents_FullMethodName, clientV2.ListAuditEvents))
+
+	webhookClient := v2.NewWebhookServiceClient(conn)
+	mux.Handle(unaryProcedure(v2.WebhookService_CreateWebhook_FullMethodName, webhookClient.CreateWebhook))
+	mux.Handle(unaryProcedure(v2.WebhookService_GetWebhook_FullMethodName, webhookClient.GetWebhook))
+	mux.Handle(unaryProcedure(v2.WebhookService_ListWebhooks_FullMethodName, webhookClient.ListWebhooks))
+	mux.Handle(unaryProcedure(v2.WebhookService_UpdateWebhook_FullMethodName, webhookClient.UpdateWebhook))
+	mux.Handle(unaryProcedure(v2.WebhookService_DeleteWebhook_FullMethodName, webhookClient.DeleteWebhook))
+	mux.Handle(unaryProcedure(v2.WebhookService_ListWebhookDeliveries_FullMethodName, webhookClient.ListWebhookDeliveries))
+}
+
+// unaryProcedure создает обработчик Connect/gRPC-Web для унарного метода gRPC.
+// Заголовки запроса передаются в метаданные по тем же правилам, что и в JSON API,
+// а метаданные ответа возвращаются в заголовках.
+func unaryProcedure[Req, Res any](
+	procedure string,
+	call func(context.Context, *Req, ...grpc.CallOption) (*Res, error),
+) (string, http.Handler) {
+	handler := connect.NewUnaryHandler(procedure, func(ctx context.Context, req *connect.Request[Req]) (*connect.Response[Res], error) {
+		md := metadata.MD{}
+		for key, values := range req.Header() {
+			if name, ok := incomingHeaderMatcher(key); ok {
+				md.Append(name, values...)
+			}
+		}
+		// Адрес клиента передается так же, как это делает JSON API gateway
+		md.Set(ratelimit.ClientIPMetadataKey, remoteIP(req.Peer().Addr))
+
+		var header metadata.MD
+		res, err := call(metadata.NewOutgoingContext(ctx, md), req.Msg, grpc.Header(&header))
+		if err != nil {
+			connectErr := connectError(err)
+			copyHeader(connectErr.Meta(), header)
+			return nil, connectErr
+		}
+
+		response := connect.NewResponse(res)
+		copyHeader(response.Header(), header)
+		return response, nil
+	})
+	return procedure, handler
+}
+
+// copyHeader переносит метаданные ответа gRPC в заголовки HTTP
+func copyHeader(dst http.Header, md metadata.MD) {
+	for key, values := range md {
+		if name, ok := outgoingHeaderMatcher(key); ok {
+			for _, value := range values {
+				dst.Add(name, value)
+			}
+		}
+	}
+}
+
+// connectError преобразует статус gRPC в ошибку Connect с сохранением кода и деталей
+func connectError(err error) *connect.Error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return connect.NewError(connect.CodeUnknown, err)
+	}
+
+	connectErr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, detail := range st.Proto().GetDetails() {
+		if errDetail, detailErr := connect.NewErrorDetail(detail); detailErr == nil {
+			connectErr.AddDetail(errDetail)
+		}
+	}
+	return connectErr
+}
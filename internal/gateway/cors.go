@@ -0,0 +1,70 @@
+package gateway
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// CORSConfig описывает правила CORS для HTTP-gateway
+type CORSConfig struct {
+	AllowedOrigins []string // Разрешенные источники, "*" разрешает любой
+	AllowedMethods []string // Разрешенные HTTP-методы
+	AllowedHeaders []string // Разрешенные заголовки запроса
+	ExposedHeaders []string // Заголовки ответа, доступные браузеру
+}
+
+// corsMiddleware добавляет заголовки CORS для разрешенных источников
+// и отвечает на preflight-запросы.
+func corsMiddleware(cfg CORSConfig, handler http.Handler) http.Handler {
+	allowAny := slices.Contains(cfg.AllowedOrigins, "*")
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !allowAny && !originAllowed(cfg.AllowedOrigins, origin) {
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if allowAny {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if exposed != "" {
+			w.Header().Set("Access-Control-Expose-Headers", exposed)
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed проверяет источник по списку без учета регистра
+func originAllowed(allowed []string, origin string) bool {
+	for _, candidate := range allowed {
+		if strings.EqualFold(candidate, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,54 +2,218 @@ package gateway
 
 import (
 	v1 "TODO/internal/api/v1"
+	v2 "TODO/internal/api/v2"
+	"TODO/internal/auth"
+	"TODO/internal/events"
+	"TODO/internal/health"
+	"TODO/internal/ratelimit"
+	"TODO/internal/requestid"
+	"TODO/internal/server"
 	"context"
+	"crypto/tls"
+	"fmt"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
-	"log"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/textproto"
+	"strings"
 )
 
+// Config содержит параметры HTTP-gateway
+type Config struct {
+	GrpcEndpoint string // Адрес gRPC сервера, к которому проксируются запросы
+	HttpEndpoint string // Адрес, на котором слушает gateway
+	PublicURL    string // Внешний URL gateway для секции servers в OpenAPI
+
+	CORS        CORSConfig  // Правила CORS
+	TLSCertFile string      // Сертификат HTTPS, без него gateway работает по HTTP
+	TLSKeyFile  string      // Приватный ключ HTTPS
+	GrpcTLS     *tls.Config // TLS для соединения с gRPC сервером, nil — без шифрования
+	Gzip        bool        // Сжимать ответы gzip по Accept-Encoding
+
+	Events        *events.Bus         // Шина событий задач для WebSocket, с Kafka — изменения всех экземпляров
+	Authenticator *auth.Authenticator // Проверка API токенов WebSocket соединений
+	WsClients     []string            // Клиенты API токенов, которым разрешено подключение к /ws
+	GraphQL       http.Handler        // Обработчик GraphQL, nil отключает /graphql
+	RateLimiter   *ratelimit.Limiter  // Ограничение частоты запросов к /graphql и /ws, nil — без ограничения
+
+	Logger *slog.Logger // Журнал gateway
+}
+
 // RunGateway запускает HTTP-gateway, который работает как прокси для gRPC сервера.
-func RunGateway(ctx context.Context, grpcEndpoint, httpEndpoint string) error {
-	mux := runtime.NewServeMux()
+// Помимо JSON API gateway обслуживает протоколы Connect и gRPC-Web, события задач
+// по WebSocket на /ws и GraphQL на /graphql, отдает /healthz
+// и /readyz с результатами проверок checker, спецификацию OpenAPI и Swagger UI по пути /docs.
+func RunGateway(ctx context.Context, cfg Config, checker *health.Checker) error {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithMetadata(clientIPMetadata),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithErrorHandler(errorHandler(cfg.Logger)),
+		runtime.WithForwardResponseOption(forwardResponseOption),
+	)
 
+	transportCredentials := insecure.NewCredentials()
+	if cfg.GrpcTLS != nil {
+		transportCredentials = credentials.NewTLS(cfg.GrpcTLS)
+	}
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(transportCredentials),
+	}
+
+	conn, err := grpc.NewClient(cfg.GrpcEndpoint, opts...)
+	if err != nil {
+		return fmt.Errorf("не удалось подключиться к gRPC серверу %s: %w", cfg.GrpcEndpoint, err)
+	}
+	go func() {
+		<-ctx.Done()
+		if err := conn.Close(); err != nil {
+			cfg.Logger.Warn("Ошибка закрытия соединения HTTP-gateway с gRPC", "error", err)
+		}
+	}()
+
+	if err := registerServices(ctx, mux, conn); err != nil {
+		return fmt.Errorf("не удалось зарегистрировать сервисы HTTP-gateway: %w", err)
 	}
 
-	if err := registerServices(ctx, mux, grpcEndpoint, opts); err != nil {
-		log.Fatalf("Не удалось зарегистрировать сервисы HTTP-gateway: %v", err)
+	routes := http.NewServeMux()
+	routes.Handle("/healthz", checker.LivenessHandler())
+	routes.Handle("/readyz", checker.ReadinessHandler())
+	if err := registerOpenAPI(routes, cfg.PublicURL, cfg.Logger); err != nil {
+		return fmt.Errorf("не удалось зарегистрировать OpenAPI: %w", err)
 	}
+	routes.Handle("/ws", rateLimitMiddleware(cfg.RateLimiter, cfg.Authenticator, "websocket", cfg.Logger,
+		websocketHandler(cfg.Events, cfg.Authenticator, cfg.WsClients, cfg.CORS, cfg.Logger)))
+	if cfg.GraphQL != nil {
+		routes.Handle("/graphql", rateLimitMiddleware(cfg.RateLimiter, cfg.Authenticator, "graphql", cfg.Logger, cfg.GraphQL))
+	}
+	registerConnect(routes, conn)
+	routes.Handle("/", mux)
+
+	var handler http.Handler = corsMiddleware(cfg.CORS, routes)
+	if cfg.Gzip {
+		handler = gzipMiddleware(handler)
+	}
+	handler = requestIDMiddleware(handler)
 
-	handler := corsMiddleware(mux)
+	if cfg.TLSCertFile != "" {
+		cfg.Logger.Info("HTTPS Gateway запущен", "addr", cfg.HttpEndpoint, "grpc", cfg.GrpcEndpoint)
+		return http.ListenAndServeTLS(cfg.HttpEndpoint, cfg.TLSCertFile, cfg.TLSKeyFile, handler)
+	}
 
-	log.Printf("HTTP Gateway запущен на %s, проксирует к gRPC на %s", httpEndpoint, grpcEndpoint)
-	return http.ListenAndServe(httpEndpoint, handler)
+	cfg.Logger.Info("HTTP Gateway запущен", "addr", cfg.HttpEndpoint, "grpc", cfg.GrpcEndpoint)
+	return http.ListenAndServe(cfg.HttpEndpoint, handler)
 }
 
-// registerServices регистрирует единый APIService для HTTP-Gateway.
-func registerServices(ctx context.Context, mux *runtime.ServeMux, grpcEndpoint string, opts []grpc.DialOption) error {
+// registerServices регистрирует APIService версий v1 и v2 и WebhookService для HTTP-Gateway.
+func registerServices(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error {
 
-	if err := v1.RegisterAPIServiceHandlerFromEndpoint(ctx, mux, grpcEndpoint, opts); err != nil {
+	if err := v1.RegisterAPIServiceHandler(ctx, mux, conn); err != nil {
+		return err
+	}
+	if err := v2.RegisterAPIServiceHandler(ctx, mux, conn); err != nil {
+		return err
+	}
+	if err := v2.RegisterWebhookServiceHandler(ctx, mux, conn); err != nil {
 		return err
 	}
 	return nil
 }
 
-// corsMiddleware добавляет поддержку CORS, чтобы позволить запросы с других доменов.
-func corsMiddleware(handler http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// forwardedHeaders заголовки HTTP, которые передаются в gRPC метаданные без префикса
+var forwardedHeaders = map[string]string{
+	"X-Api-Token":     "x-api-token",
+	"Idempotency-Key": "idempotency-key",
+	"If-Match":        "if-match",
+	"X-Request-Id":    "x-request-id",
+	"Traceparent":     "traceparent",
+	"Tracestate":      "tracestate",
+}
 
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// exposedMetadata метаданные ответа gRPC, которые возвращаются как стандартные заголовки HTTP
+var exposedMetadata = map[string]string{
+	"etag":        "ETag",
+	"location":    "Location",
+	"retry-after": "Retry-After",
+}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+// incomingHeaderMatcher передает заголовки из forwardedHeaders в gRPC метаданные
+// без префикса, остальные заголовки обрабатываются по умолчанию. Адрес клиента
+// передает только сам gateway, поэтому заголовок Grpc-Metadata-X-Client-Ip отбрасывается.
+func incomingHeaderMatcher(key string) (string, bool) {
+	if name, ok := forwardedHeaders[textproto.CanonicalMIMEHeaderKey(key)]; ok {
+		return name, true
+	}
+	name, ok := runtime.DefaultHeaderMatcher(key)
+	if ok && strings.EqualFold(name, ratelimit.ClientIPMetadataKey) {
+		return "", false
+	}
+	return name, ok
+}
 
-		handler.ServeHTTP(w, r)
-	})
+// clientIPMetadata передает gRPC серверу IP адрес клиента, с которого пришел HTTP запрос
+func clientIPMetadata(_ context.Context, r *http.Request) metadata.MD {
+	return metadata.Pairs(ratelimit.ClientIPMetadataKey, remoteIP(r.RemoteAddr))
+}
+
+// remoteIP возвращает IP адрес из адреса соединения вида host:port
+func remoteIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// outgoingHeaderMatcher возвращает метаданные из exposedMetadata как стандартные заголовки,
+// остальные метаданные получают префикс Grpc-Metadata-. Заголовок X-Request-Id
+// уже выставлен requestIDMiddleware, поэтому метаданные x-request-id пропускаются.
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if key == requestid.MetadataKey {
+		return "", false
+	}
+	if header, ok := exposedMetadata[key]; ok {
+		return header, true
+	}
+	return fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, key), true
+}
+
+// forwardResponseOption отвечает 201 Created, если сервер сообщил адрес созданного ресурса
+func forwardResponseOption(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+	md, ok := runtime.ServerMetadataFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if len(md.HeaderMD.Get("location")) > 0 {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return nil
+}
+
+// isVersionMismatch проверяет, что ошибка gRPC описывает конфликт версий ресурса.
+func isVersionMismatch(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		return false
+	}
+	for _, detail := range st.Details() {
+		failure, ok := detail.(*errdetails.PreconditionFailure)
+		if !ok {
+			continue
+		}
+		for _, violation := range failure.GetViolations() {
+			if violation.GetType() == server.VersionMismatchViolation {
+				return true
+			}
+		}
+	}
+	return false
 }
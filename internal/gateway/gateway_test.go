@@ -0,0 +1,25 @@
+package gateway
+
+import "testing"
+
+func TestIncomingHeaderMatcher(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		wantOK bool
+	}{
+		{header: "X-Api-Token", want: "x-api-token", wantOK: true},
+		{header: "if-match", want: "if-match", wantOK: true},
+		{header: "Grpc-Metadata-Custom", want: "Custom", wantOK: true},
+		{header: "Grpc-Metadata-X-Client-Ip", wantOK: false},
+		{header: "grpc-metadata-x-client-ip", wantOK: false},
+		{header: "X-Client-Ip", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := incomingHeaderMatcher(tt.header)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("incomingHeaderMatcher(%q) = %q, %v, ожидалось %q, %v", tt.header, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
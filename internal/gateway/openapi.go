@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+	"gopkg.in/yaml.v3"
+
+	v1 "TODO/internal/api/v1"
+	v2 "TODO/internal/api/v2"
+)
+
+// swaggerInitializer настраивает Swagger UI на спецификации, отдаваемые gateway
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    urls: [
+      {url: "/openapi.yaml", name: "v1"},
+      {url: "/v2/openapi.yaml", name: "v2"}
+    ],
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// registerOpenAPI регистрирует спецификации OpenAPI (YAML и JSON) для v1 и v2
+// и Swagger UI по пути /docs. Секция servers формируется из serverURL.
+func registerOpenAPI(mux *http.ServeMux, serverURL string, log *slog.Logger) error {
+	specs := []struct {
+		prefix string
+		files  fs.FS
+	}{
+		{prefix: "", files: v1.OpenAPI},
+		{prefix: "/v2", files: v2.OpenAPI},
+	}
+
+	for _, spec := range specs {
+		yamlSpec, jsonSpec, err := loadOpenAPI(spec.files, serverURL)
+		if err != nil {
+			return err
+		}
+		mux.Handle(spec.prefix+"/openapi.yaml", staticHandler("application/yaml", yamlSpec, log))
+		mux.Handle(spec.prefix+"/openapi.json", staticHandler("application/json", jsonSpec, log))
+	}
+
+	mux.Handle("/docs/swagger-initializer.js", staticHandler("application/javascript", []byte(swaggerInitializer), log))
+	mux.Handle("/docs/", http.StripPrefix("/docs/", http.FileServer(http.FS(swaggerFiles.FS))))
+	mux.Handle("/docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently))
+
+	return nil
+}
+
+// loadOpenAPI читает встроенную спецификацию, добавляет в нее секцию servers
+// и возвращает ее в форматах YAML и JSON.
+func loadOpenAPI(files fs.FS, serverURL string) ([]byte, []byte, error) {
+	data, err := fs.ReadFile(files, "openapi.yaml")
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка чтения спецификации OpenAPI: %w", err)
+	}
+
+	var spec map[string]any
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, nil, fmt.Errorf("ошибка разбора спецификации OpenAPI: %w", err)
+	}
+
+	spec["servers"] = []map[string]string{{
+		"url":         strings.TrimSuffix(serverURL, "/"),
+		"description": "HTTP Gateway",
+	}}
+
+	yamlSpec, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка сериализации спецификации OpenAPI в YAML: %w", err)
+	}
+
+	jsonSpec, err := json.Marshal(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка сериализации спецификации OpenAPI в JSON: %w", err)
+	}
+
+	return yamlSpec, jsonSpec, nil
+}
+
+// staticHandler отдает заранее подготовленное содержимое с указанным Content-Type
+func staticHandler(contentType string, body []byte, log *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		if _, err := w.Write(body); err != nil {
+			log.DebugContext(r.Context(), "Ошибка записи ответа", "path", r.URL.Path, "error", err)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem описывает ошибку в формате RFC 7807 (application/problem+json)
+type Problem struct {
+	Type       string             `json:"type"`
+	Title      string             `json:"title"`
+	Status     int                `json:"status"`
+	Detail     string             `json:"detail,omitempty"`
+	Instance   string             `json:"instance,omitempty"`
+	Code       string             `json:"code,omitempty"`
+	Violations []ProblemViolation `json:"violations,omitempty"`
+}
+
+// ProblemViolation описывает нарушение в поле запроса или предусловии
+type ProblemViolation struct {
+	Field       string `json:"field,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+	Description string `json:"description"`
+}
+
+// errorHandler возвращает обработчик, отвечающий ошибкой в формате application/problem+json.
+// HTTP-статус вычисляется по коду gRPC, конфликт версий дает 412 Precondition Failed.
+func errorHandler(log *slog.Logger) runtime.ErrorHandlerFunc {
+	return func(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		writeProblem(ctx, log, w, r, err)
+	}
+}
+
+// writeProblem записывает ошибку gRPC в формате application/problem+json
+func writeProblem(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	httpStatus := 0
+	var statusErr *runtime.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		httpStatus = statusErr.HTTPStatus
+		err = statusErr.Err
+	}
+
+	st := status.Convert(err)
+	if httpStatus == 0 {
+		httpStatus = runtime.HTTPStatusFromCode(st.Code())
+		if isVersionMismatch(err) {
+			httpStatus = http.StatusPreconditionFailed
+		}
+	}
+
+	problem := Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(httpStatus),
+		Status:     httpStatus,
+		Detail:     st.Message(),
+		Instance:   r.URL.Path,
+		Code:       st.Code().String(),
+		Violations: problemViolations(st),
+	}
+
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for key, values := range md.HeaderMD {
+			if header, ok := outgoingHeaderMatcher(key); ok {
+				for _, value := range values {
+					w.Header().Add(header, value)
+				}
+			}
+		}
+	}
+
+	w.Header().Del("Trailer")
+	w.Header().Del("Transfer-Encoding")
+	w.Header().Set("Content-Type", problemContentType)
+	if st.Code() == codes.Unauthenticated {
+		w.Header().Set("WWW-Authenticate", st.Message())
+	}
+	if retryAfter, ok := retryDelay(st); ok && w.Header().Get("Retry-After") == "" {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	w.WriteHeader(httpStatus)
+
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		log.DebugContext(ctx, "Ошибка записи ответа problem+json", "error", err)
+	}
+}
+
+// retryDelay возвращает задержку из RetryInfo в деталях статуса gRPC
+func retryDelay(st *status.Status) (time.Duration, bool) {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// problemViolations собирает нарушения полей и предусловий из деталей статуса gRPC
+func problemViolations(st *status.Status) []ProblemViolation {
+	var violations []ProblemViolation
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, fv := range d.GetFieldViolations() {
+				violations = append(violations, ProblemViolation{
+					Field:       fv.GetField(),
+					Description: fv.GetDescription(),
+				})
+			}
+		case *errdetails.PreconditionFailure:
+			for _, pv := range d.GetViolations() {
+				violations = append(violations, ProblemViolation{
+					Type:        pv.GetType(),
+					Subject:     pv.GetSubject(),
+					Description: pv.GetDescription(),
+				})
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"TODO/internal/server"
+)
+
+// withDetails добавляет детали к статусу gRPC
+func withDetails(t *testing.T, st *status.Status, details ...protoadapt.MessageV1) error {
+	t.Helper()
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		t.Fatalf("WithDetails: %v", err)
+	}
+	return detailed.Err()
+}
+
+func TestWriteProblemStatusMapping(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantCode       string
+		wantViolations int
+		wantHeaders    map[string]string
+	}{
+		{
+			name:       "NotFound",
+			err:        status.Error(codes.NotFound, "задача не найдена"),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "NotFound",
+		},
+		{
+			name: "InvalidArgument с нарушениями полей",
+			err: withDetails(t, status.New(codes.InvalidArgument, "ошибка валидации запроса"), &errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{
+					{Field: "title", Description: "пустое значение"},
+					{Field: "note", Description: "пустое значение"},
+				},
+			}),
+			wantStatus:     http.StatusBadRequest,
+			wantCode:       "InvalidArgument",
+			wantViolations: 2,
+		},
+		{
+			name:       "AlreadyExists",
+			err:        status.Error(codes.AlreadyExists, "имя занято"),
+			wantStatus: http.StatusConflict,
+			wantCode:   "AlreadyExists",
+		},
+		{
+			name: "FailedPrecondition без конфликта версий",
+			err: withDetails(t, status.New(codes.FailedPrecondition, "владелец в корзине"), &errdetails.PreconditionFailure{
+				Violations: []*errdetails.PreconditionFailure_Violation{{Type: server.OwnerDeletedViolation, Subject: "tasks/1", Description: "восстановите владельца"}},
+			}),
+			wantStatus:     http.StatusBadRequest,
+			wantCode:       "FailedPrecondition",
+			wantViolations: 1,
+		},
+		{
+			name: "конфликт версий",
+			err: withDetails(t, status.New(codes.FailedPrecondition, "конфликт версий"), &errdetails.PreconditionFailure{
+				Violations: []*errdetails.PreconditionFailure_Violation{{Type: server.VersionMismatchViolation, Subject: "tasks/1", Description: "версия изменилась"}},
+			}),
+			wantStatus:     http.StatusPreconditionFailed,
+			wantCode:       "FailedPrecondition",
+			wantViolations: 1,
+		},
+		{
+			name:        "Unauthenticated",
+			err:         status.Error(codes.Unauthenticated, "неверный токен"),
+			wantStatus:  http.StatusUnauthorized,
+			wantCode:    "Unauthenticated",
+			wantHeaders: map[string]string{"WWW-Authenticate": "неверный токен"},
+		},
+		{
+			name: "ResourceExhausted с RetryInfo",
+			err: withDetails(t, status.New(codes.ResourceExhausted, "слишком много запросов"), &errdetails.RetryInfo{
+				RetryDelay: durationpb.New(1500 * time.Millisecond),
+			}),
+			wantStatus:  http.StatusTooManyRequests,
+			wantCode:    "ResourceExhausted",
+			wantHeaders: map[string]string{"Retry-After": "2"},
+		},
+		{
+			name:       "Unavailable",
+			err:        status.Error(codes.Unavailable, "сервер недоступен"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   "Unavailable",
+		},
+		{
+			name:       "HTTPStatusError gateway",
+			err:        &runtime.HTTPStatusError{HTTPStatus: http.StatusMethodNotAllowed, Err: status.Error(codes.Unimplemented, "метод не поддерживается")},
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCode:   "Unimplemented",
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v2/tasks/1", nil)
+			w := httptest.NewRecorder()
+
+			writeProblem(context.Background(), log, w, r, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("статус = %d, ожидался %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); got != problemContentType {
+				t.Errorf("Content-Type = %q, ожидался %q", got, problemContentType)
+			}
+			for header, want := range tt.wantHeaders {
+				if got := w.Header().Get(header); got != want {
+					t.Errorf("%s = %q, ожидалось %q", header, got, want)
+				}
+			}
+
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+			if problem.Status != tt.wantStatus || problem.Title != http.StatusText(tt.wantStatus) {
+				t.Errorf("status и title = %d %q, ожидалось %d %q", problem.Status, problem.Title, tt.wantStatus, http.StatusText(tt.wantStatus))
+			}
+			if problem.Code != tt.wantCode || problem.Instance != "/v2/tasks/1" || problem.Type != "about:blank" {
+				t.Errorf("problem = %+v, ожидался code %q и instance /v2/tasks/1", problem, tt.wantCode)
+			}
+			if len(problem.Violations) != tt.wantViolations {
+				t.Errorf("violations = %+v, ожидалось %d", problem.Violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestOutgoingHeaderMatcher(t *testing.T) {
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{key: "etag", want: "ETag", wantOK: true},
+		{key: "location", want: "Location", wantOK: true},
+		{key: "retry-after", want: "Retry-After", wantOK: true},
+		{key: "custom", want: runtime.MetadataHeaderPrefix + "custom", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		got, ok := outgoingHeaderMatcher(tt.key)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("outgoingHeaderMatcher(%q) = %q, %v, ожидалось %q, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"TODO/internal/auth"
+	"TODO/internal/ratelimit"
+)
+
+// rateLimitMiddleware ограничивает частоту запросов к обработчикам, которые обращаются
+// к сервисам напрямую, минуя gRPC сервер и его интерсепторы (GraphQL, WebSocket).
+// Ограничение выбирается по имени method так же, как для методов gRPC.
+func rateLimitMiddleware(limiter *ratelimit.Limiter, authenticator *auth.Authenticator, method string, log *slog.Logger, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Неверный токен учитывается по IP, отказ в доступе вернет сам обработчик
+		principal, _ := authenticator.Authenticate(auth.RequestToken(r))
+		result, err := limiter.Allow(r.Context(), ratelimit.CallerKey(principal, remoteIP(r.RemoteAddr)), method)
+		if err != nil {
+			log.WarnContext(r.Context(), "Ошибка проверки ограничения частоты", "method", method, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if result.Allowed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", problemContentType)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+		w.WriteHeader(http.StatusTooManyRequests)
+		problem := Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(http.StatusTooManyRequests),
+			Status:   http.StatusTooManyRequests,
+			Detail:   "превышено ограничение частоты запросов " + result.Limit.String(),
+			Instance: r.URL.Path,
+			Code:     "ResourceExhausted",
+		}
+		if err := json.NewEncoder(w).Encode(problem); err != nil {
+			log.DebugContext(r.Context(), "Ошибка записи ответа problem+json", "error", err)
+		}
+	})
+}
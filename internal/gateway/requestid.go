@@ -0,0 +1,20 @@
+package gateway
+
+import (
+	"net/http"
+
+	"TODO/internal/requestid"
+)
+
+// requestIDMiddleware принимает заголовок X-Request-Id или создает новый идентификатор,
+// возвращает его в ответе и сохраняет в контексте. Заголовок запроса перезаписывается,
+// чтобы gRPC сервер получил тот же идентификатор в метаданных x-request-id.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestid.Ensure(r.Header.Get(requestid.Header))
+		r.Header.Set(requestid.Header, id)
+		w.Header().Set(requestid.Header, id)
+
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"TODO/internal/requestid"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantKeep bool
+	}{
+		{name: "идентификатор клиента", header: "client-id-1", wantKeep: true},
+		{name: "без заголовка"},
+		{name: "некорректный идентификатор", header: "bad id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotHeader, gotContext string
+			handler := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotHeader = r.Header.Get(requestid.Header)
+				gotContext = requestid.FromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/v2/tasks", nil)
+			if tt.header != "" {
+				r.Header.Set(requestid.Header, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			id := w.Header().Get(requestid.Header)
+			if !requestid.Valid(id) {
+				t.Fatalf("ответ без корректного %s: %q", requestid.Header, id)
+			}
+			if tt.wantKeep && id != tt.header {
+				t.Errorf("идентификатор = %q, ожидался идентификатор клиента %q", id, tt.header)
+			}
+			if !tt.wantKeep && id == tt.header {
+				t.Errorf("некорректный идентификатор %q не заменен", id)
+			}
+			if gotHeader != id || gotContext != id {
+				t.Errorf("заголовок запроса %q и контекст %q не совпадают с ответом %q", gotHeader, gotContext, id)
+			}
+		})
+	}
+}
+
+func TestOutgoingHeaderMatcherDropsRequestID(t *testing.T) {
+	// Gateway сам возвращает X-Request-Id, метаданные gRPC не дублируют заголовок
+	if header, ok := outgoingHeaderMatcher(requestid.MetadataKey); ok {
+		t.Errorf("outgoingHeaderMatcher(%q) = %q, ожидалось отбрасывание", requestid.MetadataKey, header)
+	}
+}
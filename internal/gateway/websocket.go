@@ -0,0 +1,227 @@
+package gateway
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"TODO/internal/auth"
+	"TODO/internal/events"
+)
+
+const (
+	wsWriteWait    = 10 * time.Second // Таймаут записи одного сообщения
+	wsPongWait     = 60 * time.Second // Время ожидания pong от клиента
+	wsPingInterval = 30 * time.Second // Интервал heartbeat-пингов, меньше wsPongWait
+	wsSendBuffer   = 64               // Число событий, которое может отстать медленный клиент
+	wsMaxMessage   = 4096             // Максимальный размер сообщения от клиента
+)
+
+// wsClientMessage команда клиента WebSocket
+type wsClientMessage struct {
+	Action string `json:"action"`  // subscribe или unsubscribe
+	UserID int64  `json:"user_id"` // Пользователь, на задачи которого оформляется подписка
+}
+
+// wsServerMessage сообщение сервера WebSocket
+type wsServerMessage struct {
+	Type   string            `json:"type"`              // task.created, task.updated, task.deleted, task.restored, subscribed, unsubscribed, error
+	UserID int64             `json:"user_id,omitempty"` // Пользователь, к которому относится сообщение
+	Event  *events.TaskEvent `json:"event,omitempty"`   // Событие задачи
+	Error  string            `json:"error,omitempty"`   // Описание ошибки команды
+}
+
+// websocketHandler отдает события задач по WebSocket. Клиент проходит аутентификацию
+// при подключении и подписывается на задачи пользователей параметром user_id
+// или командами {"action": "subscribe", "user_id": 1}. Токены не привязаны к пользователям,
+// поэтому подключаться могут только клиенты из allowedClients, остальные получают 403.
+func websocketHandler(bus *events.Bus, authenticator *auth.Authenticator, allowedClients []string, cors CORSConfig, log *slog.Logger) http.Handler {
+	allowed := make(map[string]struct{}, len(allowedClients))
+	for _, name := range allowedClients {
+		allowed[name] = struct{}{}
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || originAllowed(cors.AllowedOrigins, origin) || originAllowed(cors.AllowedOrigins, "*")
+		},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := authenticator.Authenticate(auth.RequestToken(r))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if _, ok := allowed[principal.Name]; !ok || principal.Name == "" {
+			http.Error(w, "клиенту API токена не разрешена подписка на задачи пользователей", http.StatusForbidden)
+			return
+		}
+
+		userIDs := make(map[int64]struct{})
+		for _, raw := range r.URL.Query()["user_id"] {
+			userID, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || userID <= 0 {
+				http.Error(w, "некорректный user_id: "+raw, http.StatusBadRequest)
+				return
+			}
+			userIDs[userID] = struct{}{}
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.WarnContext(r.Context(), "Ошибка установки WebSocket соединения", "error", err)
+			return
+		}
+
+		session := &wsSession{
+			conn:    conn,
+			log:     log,
+			userIDs: userIDs,
+			replies: make(chan wsServerMessage, 8),
+		}
+		session.sub = bus.Subscribe(wsSendBuffer, func(event events.TaskEvent) bool {
+			return session.subscribed(event.UserID)
+		})
+		session.run(r.Context())
+	})
+}
+
+// wsSession обслуживает одно WebSocket соединение
+type wsSession struct {
+	conn    *websocket.Conn
+	log     *slog.Logger
+	sub     *events.Subscription
+	mu      sync.RWMutex
+	userIDs map[int64]struct{}
+	replies chan wsServerMessage
+}
+
+// run читает команды клиента и отправляет события, пока соединение не закроется
+func (s *wsSession) run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer s.sub.Close()
+	defer s.conn.Close()
+
+	go func() {
+		defer cancel()
+		s.readLoop(ctx)
+	}()
+
+	s.writeLoop(ctx)
+}
+
+// readLoop обрабатывает команды подписки и pong-сообщения
+func (s *wsSession) readLoop(ctx context.Context) {
+	s.conn.SetReadLimit(wsMaxMessage)
+	_ = s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var msg wsClientMessage
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.log.WarnContext(ctx, "Ошибка чтения WebSocket", "error", err)
+			}
+			return
+		}
+
+		reply := s.handleCommand(msg)
+		select {
+		case s.replies <- reply:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleCommand изменяет набор подписок сессии
+func (s *wsSession) handleCommand(msg wsClientMessage) wsServerMessage {
+	if msg.UserID <= 0 {
+		return wsServerMessage{Type: "error", Error: "user_id должен быть больше нуля"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Action {
+	case "subscribe":
+		s.userIDs[msg.UserID] = struct{}{}
+		return wsServerMessage{Type: "subscribed", UserID: msg.UserID}
+	case "unsubscribe":
+		delete(s.userIDs, msg.UserID)
+		return wsServerMessage{Type: "unsubscribed", UserID: msg.UserID}
+	default:
+		return wsServerMessage{Type: "error", Error: "неизвестное действие: " + msg.Action}
+	}
+}
+
+// subscribed проверяет подписку на задачи пользователя
+func (s *wsSession) subscribed(userID int64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.userIDs[userID]
+	return ok
+}
+
+// writeLoop единственный писатель в соединение: события, ответы на команды и heartbeat.
+// Если клиент не успевает читать, шина отключает подписку и соединение закрывается
+// с кодом 1013, чтобы клиент переподключился и перечитал задачи.
+func (s *wsSession) writeLoop(ctx context.Context) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.close(websocket.CloseNormalClosure, "")
+			return
+
+		case event, ok := <-s.sub.Events():
+			if !ok {
+				if s.sub.Overflowed() {
+					s.close(websocket.CloseTryAgainLater, "клиент не успевает получать события")
+				}
+				return
+			}
+			if err := s.write(wsServerMessage{Type: event.Type(), UserID: event.UserID, Event: &event}); err != nil {
+				return
+			}
+
+		case reply := <-s.replies:
+			if err := s.write(reply); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write отправляет сообщение с таймаутом записи
+func (s *wsSession) write(msg wsServerMessage) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	if err := s.conn.WriteJSON(msg); err != nil {
+		s.log.Debug("Ошибка записи в WebSocket", "error", err)
+		return err
+	}
+	return nil
+}
+
+// close отправляет клиенту кадр закрытия
+func (s *wsSession) close(code int, reason string) {
+	_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+}
@@ -0,0 +1,159 @@
+package gateway
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"TODO/internal/auth"
+	"TODO/internal/events"
+	"TODO/internal/model"
+)
+
+// newTestWebSocketServer запускает /ws с токенами dashboard:secret и other:token,
+// подключаться разрешено только клиенту dashboard
+func newTestWebSocketServer(t *testing.T) (*httptest.Server, *events.Bus) {
+	t.Helper()
+
+	bus := events.NewBus()
+	authenticator := auth.NewAuthenticator([]string{"dashboard:secret", "other:token"})
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	srv := httptest.NewServer(websocketHandler(bus, authenticator, []string{"dashboard"}, CORSConfig{}, log))
+	t.Cleanup(srv.Close)
+	return srv, bus
+}
+
+// dialWebSocket подключается к тестовому серверу с токеном и параметрами запроса
+func dialWebSocket(srv *httptest.Server, query string) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?" + query
+	return websocket.DefaultDialer.Dial(url, nil)
+}
+
+// readMessage читает сообщение сервера с таймаутом
+func readMessage(t *testing.T, conn *websocket.Conn) wsServerMessage {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg wsServerMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	return msg
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	srv, _ := newTestWebSocketServer(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "без токена", query: "user_id=1", wantStatus: http.StatusUnauthorized},
+		{name: "неизвестный токен", query: "token=unknown&user_id=1", wantStatus: http.StatusUnauthorized},
+		{name: "клиент без разрешения", query: "token=token&user_id=1", wantStatus: http.StatusForbidden},
+		{name: "некорректный user_id", query: "token=secret&user_id=abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, resp, err := dialWebSocket(srv, tt.query)
+			if err == nil {
+				_ = conn.Close()
+				t.Fatal("соединение установлено, ожидался отказ")
+			}
+			if resp == nil || resp.StatusCode != tt.wantStatus {
+				t.Fatalf("ответ = %v, ожидался статус %d", resp, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestWebSocketSubscribe(t *testing.T) {
+	srv, bus := newTestWebSocketServer(t)
+
+	conn, _, err := dialWebSocket(srv, "token=secret&user_id=1")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(wsClientMessage{Action: "subscribe", UserID: 2}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if reply := readMessage(t, conn); reply.Type != "subscribed" || reply.UserID != 2 {
+		t.Fatalf("ответ на подписку = %+v", reply)
+	}
+
+	bus.Publish(events.TaskEvent{Operation: events.OperationCreateTask, TaskID: 10, UserID: 1, Task: &model.Task{ID: 10, UserID: 1}})
+	bus.Publish(events.TaskEvent{Operation: events.OperationUpdateTask, TaskID: 30, UserID: 3})
+	bus.Publish(events.TaskEvent{Operation: events.OperationDeleteTask, TaskID: 20, UserID: 2})
+
+	first := readMessage(t, conn)
+	if first.Type != events.TypeTaskCreated || first.UserID != 1 || first.Event == nil || first.Event.TaskID != 10 {
+		t.Fatalf("первое событие = %+v, ожидалось создание задачи 10 пользователя 1", first)
+	}
+	second := readMessage(t, conn)
+	if second.Type != events.TypeTaskDeleted || second.UserID != 2 || second.Event == nil || second.Event.TaskID != 20 {
+		t.Fatalf("второе событие = %+v, ожидалось удаление задачи 20 пользователя 2", second)
+	}
+
+	if err := conn.WriteJSON(wsClientMessage{Action: "unsubscribe", UserID: 2}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if reply := readMessage(t, conn); reply.Type != "unsubscribed" || reply.UserID != 2 {
+		t.Fatalf("ответ на отписку = %+v", reply)
+	}
+
+	if err := conn.WriteJSON(wsClientMessage{Action: "subscribe"}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if reply := readMessage(t, conn); reply.Type != "error" {
+		t.Fatalf("ответ на подписку без user_id = %+v, ожидалась ошибка", reply)
+	}
+}
+
+// Клиент, не успевающий читать события, отключается с кодом 1013 (Try Again Later)
+func TestWebSocketOverflowClose(t *testing.T) {
+	srv, bus := newTestWebSocketServer(t)
+
+	conn, _, err := dialWebSocket(srv, "token=secret")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(wsClientMessage{Action: "subscribe", UserID: 1}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if reply := readMessage(t, conn); reply.Type != "subscribed" {
+		t.Fatalf("ответ на подписку = %+v", reply)
+	}
+
+	// Большие заметки заполняют буфер сокета, запись сервера блокируется, и очередь подписки переполняется
+	task := &model.Task{ID: 1, UserID: 1, Note: strings.Repeat("x", 256<<10)}
+	for i := 0; i < 4*wsSendBuffer; i++ {
+		bus.Publish(events.TaskEvent{Operation: events.OperationUpdateTask, TaskID: 1, UserID: 1, Task: task})
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) {
+				t.Fatalf("ReadMessage: %v, ожидалось закрытие соединения", err)
+			}
+			if closeErr.Code != websocket.CloseTryAgainLater {
+				t.Fatalf("код закрытия = %d, ожидался %d", closeErr.Code, websocket.CloseTryAgainLater)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+
+	"TODO/internal/dao"
+)
+
+// Коды ошибок в расширениях ответа GraphQL
+const (
+	codeBadUserInput    = "BAD_USER_INPUT"
+	codeNotFound        = "NOT_FOUND"
+	codeVersionMismatch = "VERSION_MISMATCH"
+	codeUserHasTasks    = "USER_HAS_TASKS"
+	codeAlreadyExists   = "ALREADY_EXISTS"
+)
+
+// resolverError ошибка резолвера с кодом в поле extensions
+type resolverError struct {
+	code    string
+	message string
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+// Extensions возвращает дополнительные поля ошибки GraphQL
+func (e *resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// badInput создает ошибку некорректных аргументов
+func badInput(message string) error {
+	return &resolverError{code: codeBadUserInput, message: message}
+}
+
+// resolveError добавляет код к ошибкам сервисов, которые клиент может обработать
+func resolveError(err error) error {
+	switch {
+	case errors.Is(err, dao.ErrVersionConflict):
+		return &resolverError{code: codeVersionMismatch, message: err.Error()}
+	case errors.Is(err, dao.ErrUserHasTasks):
+		return &resolverError{code: codeUserHasTasks, message: err.Error()}
+	case errors.Is(err, dao.ErrUsernameTaken):
+		return &resolverError{code: codeAlreadyExists, message: err.Error()}
+	case errors.Is(err, pgx.ErrNoRows):
+		return &resolverError{code: codeNotFound, message: err.Error()}
+	default:
+		return err
+	}
+}
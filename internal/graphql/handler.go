@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	_ "embed"
+	"net"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	"TODO/internal/auth"
+	"TODO/internal/ratelimit"
+	"TODO/internal/service"
+	"TODO/internal/session"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// NewHandler создает HTTP-обработчик GraphQL поверх UserService и TaskService.
+// Запросы проходят проверку API токена, для каждого запроса создаются
+// собственные загрузчики, группирующие вложенные выборки задач.
+func NewHandler(userService *service.UserService, taskService *service.TaskService, authenticator *auth.Authenticator) http.Handler {
+	resolver := &Resolver{
+		userService: userService,
+		taskService: taskService,
+	}
+	handler := &relay.Handler{
+		Schema: graphql.MustParseSchema(schema, resolver, graphql.MaxDepth(8)),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "GraphQL принимает только POST запросы", http.StatusMethodNotAllowed)
+			return
+		}
+
+		principal, err := authenticator.Authenticate(auth.RequestToken(r))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := auth.NewContext(r.Context(), principal)
+		ctx = session.NewContext(ctx, ratelimit.CallerKey(principal, remoteIP(r)))
+		ctx = withLoaders(ctx, taskService)
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// remoteIP возвращает IP адрес клиента HTTP запроса
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,218 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"TODO/internal/auth"
+	"TODO/internal/cache"
+	"TODO/internal/dao"
+	"TODO/internal/migrate"
+	"TODO/internal/model"
+	"TODO/internal/pool"
+	"TODO/internal/service"
+)
+
+// newTestServices создает сервисы поверх новой базы SQLite во временном каталоге
+func newTestServices(t *testing.T) (*service.UserService, *service.TaskService) {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "todo.db")
+
+	migrator, err := migrate.NewSQLite(path, log)
+	if err != nil {
+		t.Fatalf("migrate.NewSQLite: %v", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrator.Up: %v", err)
+	}
+	if err := migrator.Close(); err != nil {
+		t.Fatalf("migrator.Close: %v", err)
+	}
+
+	db, err := dao.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("dao.OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	wp := pool.NewWorkerPool(2)
+	cacheConfig := cache.CacheConfig{DefaultTTL: time.Minute}
+	taskService := service.NewTaskService(dao.NewSQLiteTaskRepository(db, log), dao.NewSQLiteTransactor(db, log), wp,
+		cache.NewRedisCache[string, model.Task](nil, cacheConfig), nil, nil, log)
+	userService := service.NewUserService(dao.NewSQLiteUserRepository(db, true, log), taskService, wp,
+		cache.NewRedisCache[string, model.User](nil, cacheConfig), log)
+	return userService, taskService
+}
+
+// graphqlResponse ответ GraphQL с ошибками и их кодами
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Code string `json:"code"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// execute выполняет запрос GraphQL и возвращает HTTP статус и разобранный ответ
+func execute(t *testing.T, handler http.Handler, token, query string, variables map[string]any) (int, graphqlResponse) {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	if token != "" {
+		r.Header.Set("X-Api-Token", token)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	var resp graphqlResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("json.Unmarshal: %v", err)
+		}
+	}
+	return w.Code, resp
+}
+
+func TestHandlerRejectsRequests(t *testing.T) {
+	userService, taskService := newTestServices(t)
+	handler := NewHandler(userService, taskService, auth.NewAuthenticator([]string{"dashboard:secret"}))
+
+	r := httptest.NewRequest(http.MethodGet, "/graphql?query={users{id}}", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("GET: статус %d, Allow %q, ожидалось 405 и POST", w.Code, w.Header().Get("Allow"))
+	}
+
+	if code, _ := execute(t, handler, "", "{ users { id } }", nil); code != http.StatusUnauthorized {
+		t.Errorf("без токена: статус %d, ожидался 401", code)
+	}
+	if code, _ := execute(t, handler, "secret", "{ users { id } }", nil); code != http.StatusOK {
+		t.Errorf("с токеном: статус %d, ожидался 200", code)
+	}
+}
+
+func TestHandlerUsersWithTasks(t *testing.T) {
+	userService, taskService := newTestServices(t)
+	handler := NewHandler(userService, taskService, auth.NewAuthenticator(nil))
+
+	var created struct {
+		CreateUser struct {
+			ID string `json:"id"`
+		} `json:"createUser"`
+	}
+	userIDs := make(map[string]string)
+	for _, username := range []string{"alice", "bob"} {
+		_, resp := execute(t, handler, "", `mutation($name: String!) { createUser(username: $name) { id } }`, map[string]any{"name": username})
+		if len(resp.Errors) > 0 {
+			t.Fatalf("createUser: %v", resp.Errors)
+		}
+		if err := json.Unmarshal(resp.Data, &created); err != nil {
+			t.Fatalf("json.Unmarshal: %v", err)
+		}
+		userIDs[username] = created.CreateUser.ID
+	}
+
+	for _, title := range []string{"первая", "вторая"} {
+		_, resp := execute(t, handler, "",
+			`mutation($user: ID!, $title: String!) { createTask(userId: $user, title: $title, note: "заметка") { id version } }`,
+			map[string]any{"user": userIDs["alice"], "title": title})
+		if len(resp.Errors) > 0 {
+			t.Fatalf("createTask: %v", resp.Errors)
+		}
+	}
+
+	_, resp := execute(t, handler, "", `{ users { username tasks { title } } }`, nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("users: %v", resp.Errors)
+	}
+	var data struct {
+		Users []struct {
+			Username string `json:"username"`
+			Tasks    []struct {
+				Title string `json:"title"`
+			} `json:"tasks"`
+		} `json:"users"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	tasksByUser := make(map[string]int)
+	for _, user := range data.Users {
+		tasksByUser[user.Username] = len(user.Tasks)
+	}
+	if len(data.Users) != 2 || tasksByUser["alice"] != 2 || tasksByUser["bob"] != 0 {
+		t.Fatalf("users = %+v, ожидалось две задачи у alice и ни одной у bob", data.Users)
+	}
+
+	_, resp = execute(t, handler, "", `query($id: ID!) { user(id: $id) { id } }`, map[string]any{"id": "999"})
+	if len(resp.Errors) > 0 || string(resp.Data) != `{"user":null}` {
+		t.Errorf("несуществующий пользователь: data %s, errors %v, ожидалось null без ошибок", resp.Data, resp.Errors)
+	}
+}
+
+func TestHandlerErrorCodes(t *testing.T) {
+	userService, taskService := newTestServices(t)
+	handler := NewHandler(userService, taskService, auth.NewAuthenticator(nil))
+
+	ctx := context.Background()
+	user, err := userService.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	task, err := taskService.CreateTask(ctx, user.ID, "купить", "молоко")
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		wantCode  string
+	}{
+		{
+			name:      "конфликт версий",
+			query:     `mutation($id: ID!) { updateTask(id: $id, title: "t", note: "n", done: true, expectedVersion: 5) { id } }`,
+			variables: map[string]any{"id": strconv.FormatInt(task.ID, 10)},
+			wantCode:  codeVersionMismatch,
+		},
+		{
+			name:     "занятое имя",
+			query:    `mutation { createUser(username: "alice") { id } }`,
+			wantCode: codeAlreadyExists,
+		},
+		{
+			name:     "некорректный ID",
+			query:    `{ task(id: "abc") { id } }`,
+			wantCode: codeBadUserInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, resp := execute(t, handler, "", tt.query, tt.variables)
+			if len(resp.Errors) != 1 || resp.Errors[0].Extensions.Code != tt.wantCode {
+				t.Fatalf("errors = %+v, ожидался код %s", resp.Errors, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	"github.com/graph-gophers/dataloader/v7"
+
+	"TODO/internal/controller"
+	"TODO/internal/model"
+	"TODO/internal/service"
+)
+
+type loadersKey struct{}
+
+// loaders содержит загрузчики одного GraphQL запроса
+type loaders struct {
+	tasksByUser *dataloader.Loader[int64, []model.Task]
+}
+
+// withLoaders сохраняет в контексте загрузчики для текущего запроса
+func withLoaders(ctx context.Context, taskService *service.TaskService) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		tasksByUser: dataloader.NewBatchedLoader(
+			tasksByUserBatch(taskService),
+			dataloader.WithWait[int64, []model.Task](2*time.Millisecond),
+		),
+	})
+}
+
+// loadersFromContext возвращает загрузчики текущего запроса
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// tasksByUserBatch загружает задачи сразу для всех запрошенных пользователей одним запросом
+func tasksByUserBatch(taskService *service.TaskService) dataloader.BatchFunc[int64, []model.Task] {
+	return func(ctx context.Context, userIDs []int64) []*dataloader.Result[[]model.Task] {
+		results := make([]*dataloader.Result[[]model.Task], len(userIDs))
+
+		tasks, err := controller.ListTasks(ctx, taskService, model.TaskFilter{UserIDs: userIDs})
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[[]model.Task]{Error: err}
+			}
+			return results
+		}
+
+		byUser := make(map[int64][]model.Task, len(userIDs))
+		for _, task := range tasks {
+			byUser[task.UserID] = append(byUser[task.UserID], task)
+		}
+		for i, userID := range userIDs {
+			results[i] = &dataloader.Result[[]model.Task]{Data: byUser[userID]}
+		}
+		return results
+	}
+}
@@ -0,0 +1,254 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/jackc/pgx/v4"
+
+	v1 "TODO/internal/api/v1"
+	"TODO/internal/controller"
+	"TODO/internal/model"
+	"TODO/internal/service"
+)
+
+// Resolver корневой резолвер запросов и мутаций GraphQL
+type Resolver struct {
+	userService *service.UserService
+	taskService *service.TaskService
+}
+
+// User возвращает пользователя по ID или null, если он не найден
+func (r *Resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	userID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := controller.GetUserByID(ctx, r.userService, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, resolveError(err)
+	}
+	return &userResolver{user: *user}, nil
+}
+
+// Users возвращает всех пользователей
+func (r *Resolver) Users(ctx context.Context) ([]*userResolver, error) {
+	users, err := controller.GetAllUsers(ctx, r.userService)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	resolvers := make([]*userResolver, len(users))
+	for i, user := range users {
+		resolvers[i] = &userResolver{user: user}
+	}
+	return resolvers, nil
+}
+
+// Task возвращает задачу по ID или null, если она не найдена
+func (r *Resolver) Task(ctx context.Context, args struct{ ID graphql.ID }) (*taskResolver, error) {
+	taskID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := controller.GetTask(ctx, r.taskService, taskID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, resolveError(err)
+	}
+	return &taskResolver{task: *task}, nil
+}
+
+// tasksArgs аргументы запроса tasks
+type tasksArgs struct {
+	UserID *graphql.ID
+	Done   *bool
+	Limit  *int32
+	Offset *int32
+}
+
+// Tasks возвращает задачи по фильтрам с постраничной выборкой
+func (r *Resolver) Tasks(ctx context.Context, args tasksArgs) ([]*taskResolver, error) {
+	filter := model.TaskFilter{Done: args.Done}
+	if args.UserID != nil {
+		userID, err := parseID(*args.UserID)
+		if err != nil {
+			return nil, err
+		}
+		filter.UserIDs = []int64{userID}
+	}
+	if args.Limit != nil {
+		if *args.Limit < 0 {
+			return nil, badInput("limit не может быть отрицательным")
+		}
+		filter.Limit = int(*args.Limit)
+	}
+	if args.Offset != nil {
+		if *args.Offset < 0 {
+			return nil, badInput("offset не может быть отрицательным")
+		}
+		filter.Offset = int(*args.Offset)
+	}
+
+	tasks, err := controller.ListTasks(ctx, r.taskService, filter)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	resolvers := make([]*taskResolver, len(tasks))
+	for i, task := range tasks {
+		resolvers[i] = &taskResolver{task: task}
+	}
+	return resolvers, nil
+}
+
+// CreateUser создает пользователя, аналог APIService.CreateUser
+func (r *Resolver) CreateUser(ctx context.Context, args struct{ Username string }) (*userResolver, error) {
+	if err := (&v1.CreateUserRequest{Username: args.Username}).ValidateAll(); err != nil {
+		return nil, badInput(err.Error())
+	}
+
+	user, err := controller.CreateUser(ctx, r.userService, args.Username)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+	return &userResolver{user: *user}, nil
+}
+
+// updateUserArgs аргументы мутации updateUser
+type updateUserArgs struct {
+	ID              graphql.ID
+	Username        string
+	ExpectedVersion *int32
+}
+
+// UpdateUser обновляет пользователя, аналог APIService.UpdateUser
+func (r *Resolver) UpdateUser(ctx context.Context, args updateUserArgs) (*userResolver, error) {
+	userID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	req := &v1.UpdateUserRequest{UserId: userID, Username: args.Username, ExpectedVersion: optionalVersion(args.ExpectedVersion)}
+	if err := req.ValidateAll(); err != nil {
+		return nil, badInput(err.Error())
+	}
+
+	user, err := controller.UpdateUser(ctx, r.userService, req.UserId, req.Username, req.ExpectedVersion)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+	return &userResolver{user: *user}, nil
+}
+
+// DeleteUser удаляет пользователя, аналог APIService.DeleteUser
+func (r *Resolver) DeleteUser(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	userID, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+	if err := (&v1.DeleteUserRequest{UserId: userID}).ValidateAll(); err != nil {
+		return false, badInput(err.Error())
+	}
+
+	if err := controller.DeleteUser(ctx, r.userService, userID); err != nil {
+		return false, resolveError(err)
+	}
+	return true, nil
+}
+
+// createTaskArgs аргументы мутации createTask
+type createTaskArgs struct {
+	UserID graphql.ID
+	Title  string
+	Note   string
+}
+
+// CreateTask создает задачу, аналог APIService.CreateTask
+func (r *Resolver) CreateTask(ctx context.Context, args createTaskArgs) (*taskResolver, error) {
+	userID, err := parseID(args.UserID)
+	if err != nil {
+		return nil, err
+	}
+	req := &v1.CreateTaskRequest{UserId: userID, Title: args.Title, Note: args.Note}
+	if err := req.ValidateAll(); err != nil {
+		return nil, badInput(err.Error())
+	}
+
+	task, err := controller.CreateTask(ctx, r.taskService, r.userService, req.UserId, req.Title, req.Note)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+	return &taskResolver{task: *task}, nil
+}
+
+// updateTaskArgs аргументы мутации updateTask
+type updateTaskArgs struct {
+	ID              graphql.ID
+	Title           string
+	Note            string
+	Done            bool
+	ExpectedVersion *int32
+}
+
+// UpdateTask обновляет задачу, аналог APIService.UpdateTask
+func (r *Resolver) UpdateTask(ctx context.Context, args updateTaskArgs) (*taskResolver, error) {
+	taskID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	req := &v1.UpdateTaskRequest{
+		TaskId:          taskID,
+		Title:           args.Title,
+		Note:            args.Note,
+		Done:            args.Done,
+		ExpectedVersion: optionalVersion(args.ExpectedVersion),
+	}
+	if err := req.ValidateAll(); err != nil {
+		return nil, badInput(err.Error())
+	}
+
+	task, err := controller.UpdateTask(ctx, r.taskService, req.TaskId, req.Title, req.Note, req.Done, req.ExpectedVersion)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+	return &taskResolver{task: *task}, nil
+}
+
+// deleteTaskArgs аргументы мутации deleteTask
+type deleteTaskArgs struct {
+	ID              graphql.ID
+	ExpectedVersion *int32
+}
+
+// DeleteTask удаляет задачу, аналог APIService.DeleteTask
+func (r *Resolver) DeleteTask(ctx context.Context, args deleteTaskArgs) (bool, error) {
+	taskID, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+	req := &v1.DeleteTaskRequest{TaskId: taskID, ExpectedVersion: optionalVersion(args.ExpectedVersion)}
+	if err := req.ValidateAll(); err != nil {
+		return false, badInput(err.Error())
+	}
+
+	if err := controller.DeleteTask(ctx, r.taskService, req.TaskId, req.ExpectedVersion); err != nil {
+		return false, resolveError(err)
+	}
+	return true, nil
+}
+
+// optionalVersion возвращает ожидаемую версию или ноль, если она не указана
+func optionalVersion(version *int32) int64 {
+	if version == nil {
+		return 0
+	}
+	return int64(*version)
+}
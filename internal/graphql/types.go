@@ -0,0 +1,99 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"TODO/internal/model"
+)
+
+// userResolver разрешает поля типа User
+type userResolver struct {
+	user model.User
+}
+
+func (r *userResolver) ID() graphql.ID {
+	return formatID(r.user.ID)
+}
+
+func (r *userResolver) Username() string {
+	return r.user.Username
+}
+
+func (r *userResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.user.CreatedAt}
+}
+
+func (r *userResolver) Version() int32 {
+	return int32(r.user.Version)
+}
+
+// Tasks возвращает задачи пользователя через загрузчик запроса
+func (r *userResolver) Tasks(ctx context.Context, args struct{ Done *bool }) ([]*taskResolver, error) {
+	tasks, err := loadersFromContext(ctx).tasksByUser.Load(ctx, r.user.ID)()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*taskResolver, 0, len(tasks))
+	for _, task := range tasks {
+		if args.Done != nil && task.Done != *args.Done {
+			continue
+		}
+		resolvers = append(resolvers, &taskResolver{task: task})
+	}
+	return resolvers, nil
+}
+
+// taskResolver разрешает поля типа Task
+type taskResolver struct {
+	task model.Task
+}
+
+func (r *taskResolver) ID() graphql.ID {
+	return formatID(r.task.ID)
+}
+
+func (r *taskResolver) UserID() graphql.ID {
+	return formatID(r.task.UserID)
+}
+
+func (r *taskResolver) Title() string {
+	return r.task.Title
+}
+
+func (r *taskResolver) Note() string {
+	return r.task.Note
+}
+
+func (r *taskResolver) Done() bool {
+	return r.task.Done
+}
+
+func (r *taskResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.task.CreatedAt}
+}
+
+func (r *taskResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.task.UpdatedAt}
+}
+
+func (r *taskResolver) Version() int32 {
+	return int32(r.task.Version)
+}
+
+// formatID преобразует числовой ID в GraphQL ID
+func formatID(id int64) graphql.ID {
+	return graphql.ID(strconv.FormatInt(id, 10))
+}
+
+// parseID преобразует GraphQL ID в числовой ID
+func parseID(id graphql.ID) (int64, error) {
+	value, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, badInput("некорректный ID: " + string(id))
+	}
+	return value, nil
+}
@@ -0,0 +1,156 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Check проверяет доступность одной зависимости сервиса
+type Check func(ctx context.Context) error
+
+// Checker выполняет проверки зависимостей и публикует их статус
+// через grpc.health.v1 и HTTP-эндпоинты /healthz и /readyz.
+type Checker struct {
+	mu      sync.RWMutex
+	checks  map[string]Check
+	server  *health.Server
+	timeout time.Duration
+}
+
+// NewChecker создает Checker с заданным таймаутом на одну проверку
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		checks:  make(map[string]Check),
+		server:  health.NewServer(),
+		timeout: timeout,
+	}
+}
+
+// Register добавляет проверку зависимости под указанным именем.
+// Имя используется как имя сервиса в grpc.health.v1.
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks[name] = check
+	c.server.SetServingStatus(name, healthpb.HealthCheckResponse_UNKNOWN)
+}
+
+// Server возвращает gRPC сервер проверки состояния для регистрации в grpc.Server
+func (c *Checker) Server() *health.Server {
+	return c.server
+}
+
+// Run периодически выполняет проверки и обновляет статусы до отмены контекста
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.Update(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			c.server.Shutdown()
+			return
+		case <-ticker.C:
+			c.Update(ctx)
+		}
+	}
+}
+
+// Update выполняет все проверки параллельно, обновляет статусы в grpc.health.v1
+// и возвращает результаты по каждой зависимости.
+func (c *Checker) Update(ctx context.Context) map[string]error {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	var (
+		wg      sync.WaitGroup
+		resMu   sync.Mutex
+		results = make(map[string]error, len(checks))
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			err := check(checkCtx)
+			resMu.Lock()
+			results[name] = err
+			resMu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	for name, err := range results {
+		if err != nil {
+			slog.WarnContext(ctx, "Проверка зависимости не прошла", "dependency", name, "error", err)
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+			c.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+			continue
+		}
+		c.server.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	c.server.SetServingStatus("", overall)
+
+	return results
+}
+
+// readinessResponse описывает тело ответа /readyz
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// LivenessHandler отвечает 200, пока процесс способен обрабатывать HTTP-запросы
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, readinessResponse{Status: "ok"})
+	})
+}
+
+// ReadinessHandler выполняет проверки зависимостей и отвечает 200,
+// если все они прошли, иначе 503 со статусом каждой зависимости.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := c.Update(r.Context())
+
+		response := readinessResponse{Status: "ok", Checks: make(map[string]string, len(results))}
+		code := http.StatusOK
+		for name, err := range results {
+			if err != nil {
+				response.Checks[name] = err.Error()
+				response.Status = "unavailable"
+				code = http.StatusServiceUnavailable
+				continue
+			}
+			response.Checks[name] = "ok"
+		}
+
+		writeJSON(w, code, response)
+	})
+}
+
+// writeJSON записывает ответ в формате JSON с указанным кодом
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Debug("Ошибка записи ответа проверки состояния", "error", err)
+	}
+}
@@ -0,0 +1,78 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"TODO/internal/auth"
+)
+
+// publicMethodPrefixes методы, доступные без API токена
+var publicMethodPrefixes = []string{
+	"/grpc.health.v1.",
+	"/grpc.reflection.",
+}
+
+// AuthUnaryInterceptor проверяет API токен вызова и сохраняет вызывающую сторону в контексте.
+// Токен передается в метаданных x-api-token или authorization (Bearer).
+func AuthUnaryInterceptor(authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor проверяет API токен при открытии стрима
+func AuthStreamInterceptor(authenticator *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate проверяет токен из метаданных, публичные методы пропускаются
+func authenticate(ctx context.Context, authenticator *auth.Authenticator, fullMethod string) (context.Context, error) {
+	if !authenticator.Enabled() {
+		return ctx, nil
+	}
+	for _, prefix := range publicMethodPrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return ctx, nil
+		}
+	}
+
+	principal, err := authenticator.Authenticate(tokenFromMetadata(ctx))
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return auth.NewContext(ctx, principal), nil
+}
+
+// tokenFromMetadata извлекает API токен из x-api-token или заголовка Authorization.
+// HTTP-gateway передает Authorization с префиксом grpcgateway-.
+func tokenFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("x-api-token"); len(values) > 0 {
+		return values[0]
+	}
+	for _, key := range []string{"authorization", "grpcgateway-authorization"} {
+		if values := md.Get(key); len(values) > 0 {
+			return auth.BearerToken(values[0])
+		}
+	}
+	return ""
+}
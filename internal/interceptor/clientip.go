@@ -0,0 +1,96 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"net/netip"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"TODO/internal/ratelimit"
+)
+
+// clientIPKey ключ контекста для IP адреса клиента
+type clientIPKey struct{}
+
+// ClientIPUnaryInterceptor определяет IP адрес клиента и сохраняет его в контексте.
+// Для вызовов от доверенных адресов trustedProxies, то есть от HTTP-gateway, используется адрес
+// из метаданных ratelimit.ClientIPMetadataKey. У остальных вызовов эти метаданные удаляются,
+// и адресом клиента считается адрес соединения.
+func ClientIPUnaryInterceptor(trustedProxies []netip.Prefix) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withClientIP(ctx, trustedProxies), req)
+	}
+}
+
+// ClientIPStreamInterceptor определяет IP адрес клиента для стрима
+func ClientIPStreamInterceptor(trustedProxies []netip.Prefix) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: withClientIP(ss.Context(), trustedProxies)})
+	}
+}
+
+// withClientIP сохраняет в контексте IP адрес клиента
+func withClientIP(ctx context.Context, trustedProxies []netip.Prefix) context.Context {
+	ip := peerIP(ctx)
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	forwarded := md.Get(ratelimit.ClientIPMetadataKey)
+	if len(forwarded) == 0 {
+		return context.WithValue(ctx, clientIPKey{}, ip)
+	}
+
+	if isTrustedProxy(ip, trustedProxies) {
+		if addr, err := netip.ParseAddr(forwarded[len(forwarded)-1]); err == nil {
+			return context.WithValue(ctx, clientIPKey{}, addr.Unmap().String())
+		}
+	}
+
+	// Метаданные от недоверенного адреса подставлены самим клиентом
+	md = md.Copy()
+	md.Delete(ratelimit.ClientIPMetadataKey)
+	ctx = metadata.NewIncomingContext(ctx, md)
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// peerIP возвращает IP адрес соединения или пустую строку, если он неизвестен
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// isTrustedProxy проверяет, что адрес ip входит в одну из подсетей trustedProxies
+func isTrustedProxy(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP возвращает IP адрес клиента, определенный ClientIPUnaryInterceptor или ClientIPStreamInterceptor
+func clientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	if ip == "" {
+		ip = peerIP(ctx)
+	}
+	if ip == "" {
+		return "unknown"
+	}
+	return ip
+}
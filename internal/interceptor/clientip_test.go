@@ -0,0 +1,62 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"TODO/internal/ratelimit"
+)
+
+func TestWithClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32"), netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name      string
+		peer      string
+		md        metadata.MD
+		want      string
+		wantStrip bool
+	}{
+		{name: "без метаданных", peer: "203.0.113.5:4000", want: "203.0.113.5"},
+		{name: "gateway", peer: "127.0.0.1:4000", md: metadata.Pairs(ratelimit.ClientIPMetadataKey, "198.51.100.7"), want: "198.51.100.7"},
+		{name: "gateway в доверенной подсети", peer: "10.1.2.3:4000", md: metadata.Pairs(ratelimit.ClientIPMetadataKey, "198.51.100.7"), want: "198.51.100.7"},
+		{name: "IPv4 в IPv6", peer: "[::ffff:127.0.0.1]:4000", md: metadata.Pairs(ratelimit.ClientIPMetadataKey, "::ffff:198.51.100.7"), want: "198.51.100.7"},
+		{name: "подмена клиентом", peer: "203.0.113.5:4000", md: metadata.Pairs(ratelimit.ClientIPMetadataKey, "198.51.100.7"), want: "203.0.113.5", wantStrip: true},
+		{name: "некорректный адрес от gateway", peer: "127.0.0.1:4000", md: metadata.Pairs(ratelimit.ClientIPMetadataKey, "garbage"), want: "127.0.0.1", wantStrip: true},
+		{name: "x-forwarded-for не учитывается", peer: "127.0.0.1:4000", md: metadata.Pairs("x-forwarded-for", "198.51.100.7"), want: "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tt.peer)
+			if err != nil {
+				t.Fatalf("ResolveTCPAddr: %v", err)
+			}
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			ctx = withClientIP(ctx, trusted)
+			if got := clientIP(ctx); got != tt.want {
+				t.Errorf("clientIP = %q, ожидался %q", got, tt.want)
+			}
+
+			md, _ := metadata.FromIncomingContext(ctx)
+			if stripped := len(md.Get(ratelimit.ClientIPMetadataKey)) == 0 && len(tt.md.Get(ratelimit.ClientIPMetadataKey)) > 0; stripped != tt.wantStrip {
+				t.Errorf("метаданные %s удалены: %v, ожидалось %v", ratelimit.ClientIPMetadataKey, stripped, tt.wantStrip)
+			}
+		})
+	}
+}
+
+func TestClientIPWithoutInterceptor(t *testing.T) {
+	if got := clientIP(context.Background()); got != "unknown" {
+		t.Errorf("clientIP = %q, ожидался unknown", got)
+	}
+}
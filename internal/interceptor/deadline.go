@@ -0,0 +1,41 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// DeadlineUnaryInterceptor ограничивает время выполнения вызова дедлайном метода.
+// Если клиент передал более ранний дедлайн, используется он.
+func DeadlineUnaryInterceptor(defaultTimeout time.Duration, methodTimeouts map[string]time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		timeout := methodTimeout(info.FullMethod, defaultTimeout, methodTimeouts)
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+// methodTimeout возвращает дедлайн для метода: сначала по полному имени, затем по имени метода
+func methodTimeout(fullMethod string, defaultTimeout time.Duration, methodTimeouts map[string]time.Duration) time.Duration {
+	if timeout, ok := methodTimeouts[fullMethod]; ok {
+		return timeout
+	}
+	if _, method := splitMethod(fullMethod); method != "" {
+		if timeout, ok := methodTimeouts[method]; ok {
+			return timeout
+		}
+	}
+	return defaultTimeout
+}
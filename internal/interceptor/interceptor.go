@@ -0,0 +1,81 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"net/netip"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"TODO/internal/auth"
+	"TODO/internal/ratelimit"
+)
+
+// Config содержит параметры цепочки серверных интерсепторов
+type Config struct {
+	DefaultTimeout time.Duration            // Дедлайн по умолчанию для унарных вызовов, 0 — без ограничения
+	MethodTimeouts map[string]time.Duration // Дедлайны для отдельных методов (полное имя или только имя метода)
+	Authenticator  *auth.Authenticator      // Проверка API токенов, nil или пустой список — без проверки
+	RateLimiter    *ratelimit.Limiter       // Ограничение частоты вызовов, nil — без ограничения
+	TrustedProxies []netip.Prefix           // Адреса HTTP-gateway, от которых принимается IP адрес клиента
+	Logger         *slog.Logger             // Журнал доступа, ошибок и паник
+}
+
+// ServerOptions возвращает опции gRPC сервера с цепочкой интерсепторов.
+// Порядок: восстановление после паники, трассировка, идентификатор запроса, IP адрес клиента, метрики,
+// журнал доступа, дедлайны, аутентификация, сеанс, ограничение частоты, валидация.
+// Восстановление стоит первым, чтобы паника в любом интерсепторе цепочки, а не только в обработчике,
+// превращалась в ошибку Internal, а не завершала процесс. Журнал доступа и метрики такой вызов
+// не учитывают, паника журналируется вместе со стеком самим восстановлением.
+// Сеанс и ограничение частоты стоят после аутентификации, чтобы различать вызовы по клиенту токена.
+func ServerOptions(cfg Config) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			RecoveryUnaryInterceptor(cfg.Logger),
+			TracingUnaryInterceptor(),
+			RequestIDUnaryInterceptor(cfg.Logger),
+			ClientIPUnaryInterceptor(cfg.TrustedProxies),
+			MetricsUnaryInterceptor(),
+			LoggingUnaryInterceptor(cfg.Logger),
+			DeadlineUnaryInterceptor(cfg.DefaultTimeout, cfg.MethodTimeouts),
+			AuthUnaryInterceptor(cfg.Authenticator),
+			SessionUnaryInterceptor(),
+			RateLimitUnaryInterceptor(cfg.RateLimiter, cfg.Logger),
+			ValidationUnaryInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			RecoveryStreamInterceptor(cfg.Logger),
+			TracingStreamInterceptor(),
+			RequestIDStreamInterceptor(cfg.Logger),
+			ClientIPStreamInterceptor(cfg.TrustedProxies),
+			MetricsStreamInterceptor(),
+			LoggingStreamInterceptor(cfg.Logger),
+			AuthStreamInterceptor(cfg.Authenticator),
+			SessionStreamInterceptor(),
+			RateLimitStreamInterceptor(cfg.RateLimiter, cfg.Logger),
+			ValidationStreamInterceptor(),
+		),
+	}
+}
+
+// wrappedStream позволяет подменить контекст серверного стрима
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает подмененный контекст стрима
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+// splitMethod разбивает полное имя метода "/package.Service/Method" на сервис и метод
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
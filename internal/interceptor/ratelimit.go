@@ -0,0 +1,79 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"TODO/internal/auth"
+	"TODO/internal/ratelimit"
+)
+
+// RateLimitUnaryInterceptor ограничивает частоту вызовов для каждой вызывающей стороны и метода.
+// При превышении возвращается ResourceExhausted с RetryInfo и метаданными retry-after.
+func RateLimitUnaryInterceptor(limiter *ratelimit.Limiter, log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := checkRateLimit(ctx, limiter, log, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor ограничивает частоту открытия стримов
+func RateLimitStreamInterceptor(limiter *ratelimit.Limiter, log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkRateLimit(ss.Context(), limiter, log, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// checkRateLimit берет токен для вызова. Публичные методы не ограничиваются,
+// ошибка хранилища не блокирует вызов.
+func checkRateLimit(ctx context.Context, limiter *ratelimit.Limiter, log *slog.Logger, fullMethod string) error {
+	if limiter == nil {
+		return nil
+	}
+	for _, prefix := range publicMethodPrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return nil
+		}
+	}
+
+	result, err := limiter.Allow(ctx, callerKey(ctx), fullMethod)
+	if err != nil {
+		log.WarnContext(ctx, "Ошибка проверки ограничения частоты", "method", fullMethod, "error", err)
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+
+	retryAfter := strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds())))
+	if err := grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter)); err != nil {
+		log.WarnContext(ctx, "Ошибка установки заголовка ответа", "header", "retry-after", "error", err)
+	}
+
+	st := status.New(codes.ResourceExhausted, "превышено ограничение частоты запросов "+result.Limit.String())
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// callerKey определяет вызывающую сторону по клиенту API токена или IP адресу
+func callerKey(ctx context.Context) string {
+	principal, _ := auth.FromContext(ctx)
+	return ratelimit.CallerKey(principal, clientIP(ctx))
+}
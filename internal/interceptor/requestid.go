@@ -0,0 +1,46 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"TODO/internal/requestid"
+)
+
+// RequestIDUnaryInterceptor принимает x-request-id из метаданных или создает новый,
+// сохраняет его в контексте, возвращает в метаданных ответа и записывает в спан
+func RequestIDUnaryInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withRequestID(ctx, log), req)
+	}
+}
+
+// RequestIDStreamInterceptor принимает или создает x-request-id для стрима
+func RequestIDStreamInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: withRequestID(ss.Context(), log)})
+	}
+}
+
+// withRequestID сохраняет идентификатор запроса в контексте вызова
+func withRequestID(ctx context.Context, log *slog.Logger) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestid.MetadataKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	id = requestid.Ensure(id)
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestid.MetadataKey, id)); err != nil {
+		log.WarnContext(ctx, "Ошибка установки заголовка ответа", "header", requestid.MetadataKey, "error", err)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", id))
+
+	return requestid.NewContext(ctx, id)
+}
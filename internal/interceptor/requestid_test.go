@@ -0,0 +1,55 @@
+package interceptor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"TODO/internal/requestid"
+)
+
+func TestRequestIDUnaryInterceptor(t *testing.T) {
+	interceptor := RequestIDUnaryInterceptor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.v2.APIService/GetTask"}
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		wantKeep string
+	}{
+		{name: "идентификатор gateway", md: metadata.Pairs(requestid.MetadataKey, "req-1"), wantKeep: "req-1"},
+		{name: "без метаданных"},
+		{name: "некорректный идентификатор", md: metadata.Pairs(requestid.MetadataKey, "bad id")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			var got string
+			_, err := interceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+				got = requestid.FromContext(ctx)
+				return nil, nil
+			})
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if !requestid.Valid(got) {
+				t.Fatalf("идентификатор в контексте = %q, ожидался корректный", got)
+			}
+			if tt.wantKeep != "" && got != tt.wantKeep {
+				t.Errorf("идентификатор = %q, ожидалось %q", got, tt.wantKeep)
+			}
+			if tt.md != nil && tt.wantKeep == "" && got == tt.md.Get(requestid.MetadataKey)[0] {
+				t.Errorf("некорректный идентификатор %q не заменен", got)
+			}
+		})
+	}
+}
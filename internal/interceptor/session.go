@@ -0,0 +1,25 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"TODO/internal/session"
+)
+
+// SessionUnaryInterceptor сохраняет в контексте ключ сеанса вызывающей стороны:
+// клиента API токена или IP адрес, как и для ограничения частоты вызовов
+func SessionUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(session.NewContext(ctx, callerKey(ctx)), req)
+	}
+}
+
+// SessionStreamInterceptor сохраняет ключ сеанса вызывающей стороны для стрима
+func SessionStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: session.NewContext(ctx, callerKey(ctx))})
+	}
+}
@@ -0,0 +1,130 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// allValidator реализуется сообщениями protoc-gen-validate и возвращает все нарушения сразу
+type allValidator interface {
+	ValidateAll() error
+}
+
+// validator реализуется сообщениями protoc-gen-validate и возвращает первое нарушение
+type validator interface {
+	Validate() error
+}
+
+// fieldError описывает ошибку валидации поля, сгенерированную protoc-gen-validate
+type fieldError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// multiError описывает набор ошибок валидации, сгенерированный protoc-gen-validate
+type multiError interface {
+	AllErrors() []error
+}
+
+// ValidationUnaryInterceptor проверяет запрос правилами protoc-gen-validate
+// и возвращает InvalidArgument с google.rpc.BadRequest, перечисляющим все нарушения.
+func ValidationUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := validate(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor проверяет каждое входящее сообщение стрима
+func ValidationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss})
+	}
+}
+
+// validatingStream проверяет сообщения при получении
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+// RecvMsg получает сообщение и проверяет его
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validate(m)
+}
+
+// validate вызывает ValidateAll или Validate, если сообщение их реализует
+func validate(req any) error {
+	var err error
+	switch v := req.(type) {
+	case allValidator:
+		err = v.ValidateAll()
+	case validator:
+		err = v.Validate()
+	default:
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	collectViolations(badRequest, "", err)
+
+	st := status.New(codes.InvalidArgument, "ошибка валидации запроса")
+	detailed, detailsErr := st.WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Errorf(codes.InvalidArgument, "ошибка валидации запроса: %v", err)
+	}
+	return detailed.Err()
+}
+
+// collectViolations раскладывает ошибки protoc-gen-validate в список нарушений по полям,
+// разворачивая вложенные сообщения в путь через точку.
+func collectViolations(badRequest *errdetails.BadRequest, prefix string, err error) {
+	var multi multiError
+	if errors.As(err, &multi) {
+		for _, e := range multi.AllErrors() {
+			collectViolations(badRequest, prefix, e)
+		}
+		return
+	}
+
+	var fe fieldError
+	if !errors.As(err, &fe) {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       prefix,
+			Description: err.Error(),
+		})
+		return
+	}
+
+	field := fe.Field()
+	if prefix != "" {
+		field = prefix + "." + field
+	}
+
+	if cause := fe.Cause(); cause != nil {
+		var nested fieldError
+		var nestedMulti multiError
+		if errors.As(cause, &nested) || errors.As(cause, &nestedMulti) {
+			collectViolations(badRequest, field, cause)
+			return
+		}
+	}
+
+	badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: fe.Reason(),
+	})
+}
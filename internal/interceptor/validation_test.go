@@ -0,0 +1,87 @@
+package interceptor
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	v1 "TODO/internal/api/v1"
+)
+
+func TestValidationUnaryInterceptor(t *testing.T) {
+	interceptor := ValidationUnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.v1.APIService/UpdateTask"}
+
+	tests := []struct {
+		name       string
+		req        any
+		wantCalled bool
+		wantFields []string
+	}{
+		{
+			name:       "корректный запрос",
+			req:        &v1.UpdateTaskRequest{TaskId: 1, Title: "купить", Note: "молоко"},
+			wantCalled: true,
+		},
+		{
+			name:       "все нарушения сразу",
+			req:        &v1.UpdateTaskRequest{Title: "", Note: ""},
+			wantFields: []string{"Note", "TaskId", "Title"},
+		},
+		{
+			name:       "сообщение без правил",
+			req:        &emptypb.Empty{},
+			wantCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				return req, nil
+			}
+
+			_, err := interceptor(context.Background(), tt.req, info, handler)
+			if called != tt.wantCalled {
+				t.Fatalf("обработчик вызван = %v, ожидалось %v", called, tt.wantCalled)
+			}
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("неожиданная ошибка: %v", err)
+				}
+				return
+			}
+
+			st := status.Convert(err)
+			if st.Code() != codes.InvalidArgument {
+				t.Fatalf("код = %v, ожидался InvalidArgument", st.Code())
+			}
+
+			var fields []string
+			for _, detail := range st.Details() {
+				if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+					for _, violation := range badRequest.FieldViolations {
+						fields = append(fields, violation.Field)
+					}
+				}
+			}
+			sort.Strings(fields)
+			if len(fields) != len(tt.wantFields) {
+				t.Fatalf("нарушения = %v, ожидалось %v", fields, tt.wantFields)
+			}
+			for i := range fields {
+				if fields[i] != tt.wantFields[i] {
+					t.Fatalf("нарушения = %v, ожидалось %v", fields, tt.wantFields)
+				}
+			}
+		})
+	}
+}
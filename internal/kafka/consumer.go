@@ -4,50 +4,62 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/IBM/sarama"
+
+	"TODO/internal/events"
+	"TODO/internal/requestid"
 )
 
 // NotifierHandler представляет обработчик для consumer группы
-type NotifierHandler struct{}
+type NotifierHandler struct {
+	log *slog.Logger
+}
+
+// NewNotifierHandler создает обработчик consumer группы, пишущий в журнал log
+func NewNotifierHandler(log *slog.Logger) NotifierHandler {
+	return NotifierHandler{log: log}
+}
 
 // Setup вызывается при запуске consumer группы
-func (NotifierHandler) Setup(_ sarama.ConsumerGroupSession) error {
-	log.Println("Consumer группа инициализирована")
+func (h NotifierHandler) Setup(_ sarama.ConsumerGroupSession) error {
+	h.log.Info("Consumer группа инициализирована")
 	return nil
 }
 
 // Cleanup вызывается при завершении работы consumer группы
-func (NotifierHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
-	log.Println("Consumer группа завершила работу")
+func (h NotifierHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
+	h.log.Info("Consumer группа завершила работу")
 	return nil
 }
 
 // ConsumeClaim отвечает за обработку сообщений из Kafka
-func (NotifierHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+func (h NotifierHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for message := range claim.Messages() {
-		log.Printf("Получено сообщение: Key = %s, Topic = %s, Partition = %d, Offset = %d",
-			string(message.Key), message.Topic, message.Partition, message.Offset)
+		ctx := messageContext(sess.Context(), message)
+		log := h.log.With("topic", message.Topic, "partition", message.Partition, "offset", message.Offset)
+		log.DebugContext(ctx, "Получено сообщение", "key", string(message.Key))
 
-		err := processMessage(message)
+		err := h.processMessage(ctx, message)
 		if err != nil {
-			log.Printf("Ошибка при обработке сообщения с Offset = %d: %v. Начинаем повторные попытки.", message.Offset, err)
+			log.WarnContext(ctx, "Ошибка при обработке сообщения, начинаем повторные попытки", "error", err)
 
 			for i := 1; i <= 3; i++ {
 				time.Sleep(2 * time.Second)
-				log.Printf("Повторная попытка %d для сообщения с Offset = %d", i, message.Offset)
-				err = processMessage(message)
+				log.DebugContext(ctx, "Повторная попытка обработки сообщения", "attempt", i)
+				err = h.processMessage(ctx, message)
 				if err == nil {
-					log.Printf("Сообщение успешно обработано после %d попытки. Offset = %d", i, message.Offset)
+					log.InfoContext(ctx, "Сообщение успешно обработано после повторной попытки", "attempt", i)
 					break
 				}
-				log.Printf("Ошибка при повторной обработке сообщения с Offset = %d на %d попытке: %v", message.Offset, i, err)
+				log.WarnContext(ctx, "Ошибка при повторной обработке сообщения", "attempt", i, "error", err)
 			}
 
 			if err != nil {
-				log.Printf("Сообщение не удалось обработать после 3 попыток. Offset = %d. Пропускаем сообщение.", message.Offset)
+				log.ErrorContext(ctx, "Сообщение не удалось обработать после 3 попыток, пропускаем сообщение", "error", err)
 				continue
 			}
 		}
@@ -55,13 +67,13 @@ func (NotifierHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sara
 		sess.MarkMessage(message, "")
 		sess.Commit()
 
-		log.Printf("Смещение зафиксировано и сообщение с Offset = %d успешно обработано", message.Offset)
+		log.DebugContext(ctx, "Смещение зафиксировано, сообщение успешно обработано")
 	}
 	return nil
 }
 
 // processMessage обрабатывает сообщение из Kafka
-func processMessage(message *sarama.ConsumerMessage) error {
+func (h NotifierHandler) processMessage(ctx context.Context, message *sarama.ConsumerMessage) error {
 	var taskMsg TaskMessage
 	err := json.Unmarshal(message.Value, &taskMsg)
 	if err != nil {
@@ -71,20 +83,25 @@ func processMessage(message *sarama.ConsumerMessage) error {
 	switch taskMsg.Operation {
 	case "error":
 		return fmt.Errorf("произошла ошибка во время обработки задачи ID = %d", taskMsg.TaskID)
-	case "create-task":
-		log.Printf("Обработана задача ID = %d, операция: create-task", taskMsg.TaskID)
-	case "update-task":
-		log.Printf("Обновлена задача ID = %d, операция: update-task", taskMsg.TaskID)
-	case "delete-task":
-		log.Printf("Удалена задача ID = %d, операция: delete-task", taskMsg.TaskID)
+	case events.OperationCreateTask, events.OperationUpdateTask, events.OperationDeleteTask, events.OperationRestoreTask:
 	default:
 		return fmt.Errorf("неизвестная операция: %s для задачи ID = %d", taskMsg.Operation, taskMsg.TaskID)
 	}
 
-	log.Printf("Сообщение успешно обработано: %+v", taskMsg)
+	h.log.InfoContext(ctx, "Сообщение о задаче обработано", "message", taskMsg)
 	return nil
 }
 
+// messageContext возвращает контекст с идентификатором запроса из заголовка x-request-id сообщения
+func messageContext(ctx context.Context, message *sarama.ConsumerMessage) context.Context {
+	for _, header := range message.Headers {
+		if header != nil && string(header.Key) == requestid.MetadataKey {
+			return requestid.NewContext(ctx, string(header.Value))
+		}
+	}
+	return ctx
+}
+
 // NewConsumerGroup создаёт новый Consumer Group для Kafka
 func NewConsumerGroup(brokers []string, groupID string) (sarama.ConsumerGroup, error) {
 	config := sarama.NewConfig()
@@ -111,7 +128,8 @@ func StartConsumer(consumerGroup sarama.ConsumerGroup, topics []string, handler
 	go func() {
 		for {
 			if err := consumerGroup.Consume(context.Background(), topics, handler); err != nil {
-				log.Fatalf("Ошибка при потреблении сообщений: %v", err)
+				handler.log.Error("Ошибка при потреблении сообщений", "error", err)
+				os.Exit(1)
 			}
 		}
 	}()
@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/IBM/sarama"
+
+	"TODO/internal/events"
+	"TODO/internal/requestid"
+)
+
+func TestProcessMessageOperations(t *testing.T) {
+	handler := NewNotifierHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	tests := []struct {
+		operation string
+		wantErr   bool
+	}{
+		{operation: events.OperationCreateTask},
+		{operation: events.OperationUpdateTask},
+		{operation: events.OperationDeleteTask},
+		{operation: events.OperationRestoreTask},
+		{operation: "error", wantErr: true},
+		{operation: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			value, err := json.Marshal(TaskMessage{Operation: tt.operation, TaskID: 1, UserID: 2})
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+
+			err = handler.processMessage(context.Background(), &sarama.ConsumerMessage{Value: value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processMessage(%q) error = %v, wantErr %v", tt.operation, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessMessageInvalidJSON(t *testing.T) {
+	handler := NewNotifierHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := handler.processMessage(context.Background(), &sarama.ConsumerMessage{Value: []byte("{")}); err == nil {
+		t.Fatal("processMessage: ожидалась ошибка для некорректного JSON")
+	}
+}
+
+func TestMessageHeadersRequestID(t *testing.T) {
+	if headers := messageHeaders(context.Background()); headers != nil {
+		t.Fatalf("messageHeaders без идентификатора = %v, ожидалось nil", headers)
+	}
+
+	headers := messageHeaders(requestid.NewContext(context.Background(), "req-1"))
+	message := &sarama.ConsumerMessage{}
+	for i := range headers {
+		message.Headers = append(message.Headers, &headers[i])
+	}
+
+	if got := requestid.FromContext(messageContext(context.Background(), message)); got != "req-1" {
+		t.Fatalf("идентификатор запроса из заголовков сообщения = %q, ожидалось req-1", got)
+	}
+}
@@ -1,63 +1,96 @@
 package kafka
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/IBM/sarama"
+
+	"TODO/internal/requestid"
 )
 
 // TaskMessage представляет структуру сообщения о задаче
 type TaskMessage struct {
 	TimeStamp time.Time `json:"timestamp"` // Время отправки сообщения
-	Operation string    `json:"operation"` // Тип операции (create-task, update-task, delete-task)
+	Operation string    `json:"operation"` // Тип операции (create-task, update-task, delete-task, restore-task)
 	TaskID    int64     `json:"task_id"`   // ID задачи
 	UserID    int64     `json:"user_id"`   // ID пользователя
 	Title     string    `json:"title"`     // Заголовок задачи
 	Note      string    `json:"note"`      // Описание задачи
 	Done      bool      `json:"done"`      // Статус выполнения задачи
+
+	Version   int64      `json:"version,omitempty"`    // Версия задачи после изменения, пусто при удалении
+	CreatedAt *time.Time `json:"created_at,omitempty"` // Время создания задачи, пусто при удалении
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // Время изменения задачи, пусто при удалении
+}
+
+// LogValue описывает сообщение в журнале без текста заметки
+func (m TaskMessage) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("operation", m.Operation),
+		slog.Int64("task_id", m.TaskID),
+		slog.Int64("user_id", m.UserID),
+		slog.Bool("done", m.Done),
+		slog.Time("timestamp", m.TimeStamp),
+	)
 }
 
 // Producer представляет Kafka продюсера
 type Producer struct {
+	client   sarama.Client
 	producer sarama.SyncProducer
 	topic    string
+	log      *slog.Logger
 }
 
 // NewProducer создает нового продюсера Kafka
-func NewProducer(brokers []string, topic string) (*Producer, error) {
+func NewProducer(brokers []string, topic string, log *slog.Logger) (*Producer, error) {
 	config := sarama.NewConfig()
 	config.Producer.RequiredAcks = sarama.WaitForAll
 	config.Producer.Idempotent = true
 	config.Producer.Return.Successes = true
 	config.Net.MaxOpenRequests = 1
 
-	syncProducer, err := sarama.NewSyncProducer(brokers, config)
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("sarama.NewClient: %w", err)
+	}
+
+	syncProducer, err := sarama.NewSyncProducerFromClient(client)
 	if err != nil {
-		return nil, fmt.Errorf("sarama.NewSyncProducer: %w", err)
+		_ = client.Close()
+		return nil, fmt.Errorf("sarama.NewSyncProducerFromClient: %w", err)
 	}
 
 	producer := &Producer{
+		client:   client,
 		producer: syncProducer,
 		topic:    topic,
+		log:      log,
 	}
 
 	return producer, nil
 }
 
-// SendTaskMessage отправляет сообщение о задаче в Kafka
-func (p Producer) SendTaskMessage(message TaskMessage) error {
+// SendTaskMessage отправляет сообщение о задаче в Kafka.
+// Идентификатор запроса из ctx передается в заголовке x-request-id.
+func (p Producer) SendTaskMessage(ctx context.Context, message TaskMessage) error {
 	// Сериализация сообщения
 	msg, err := json.Marshal(message)
 	if err != nil {
 		return fmt.Errorf("json.Marshal: %w", err)
 	}
 
+	// Ключ по ID задачи сохраняет порядок изменений одной задачи в партиции
 	kafkaMsg := &sarama.ProducerMessage{
-		Topic: p.topic,
-		Value: sarama.ByteEncoder(msg),
+		Topic:   p.topic,
+		Key:     sarama.StringEncoder(strconv.FormatInt(message.TaskID, 10)),
+		Value:   sarama.ByteEncoder(msg),
+		Headers: messageHeaders(ctx),
 	}
 
 	partition, offset, err := p.producer.SendMessage(kafkaMsg)
@@ -65,13 +98,13 @@ func (p Producer) SendTaskMessage(message TaskMessage) error {
 		return fmt.Errorf("p.producer.SendMessage: %w", err)
 	}
 
-	log.Printf("Сообщение успешно отправлено в Kafka. Тема: %s, Раздел: %d, Смещение: %d, Сообщение: %+v\n", p.topic, partition, offset, message)
+	p.log.DebugContext(ctx, "Сообщение отправлено в Kafka", "topic", p.topic, "partition", partition, "offset", offset, "message", message)
 
 	return nil
 }
 
 // SendKafkaErrorMessage отправляет сообщение об ошибке в Kafka
-func (p Producer) SendKafkaErrorMessage(operation string, taskID int64, userID int64, description string) error {
+func (p Producer) SendKafkaErrorMessage(ctx context.Context, operation string, taskID int64, userID int64, description string) error {
 	errorMessage := TaskMessage{
 		TimeStamp: time.Now(),
 		Operation: operation,
@@ -88,8 +121,9 @@ func (p Producer) SendKafkaErrorMessage(operation string, taskID int64, userID i
 	}
 
 	kafkaMsg := &sarama.ProducerMessage{
-		Topic: p.topic,
-		Value: sarama.ByteEncoder(msg),
+		Topic:   p.topic,
+		Value:   sarama.ByteEncoder(msg),
+		Headers: messageHeaders(ctx),
 	}
 
 	partition, offset, err := p.producer.SendMessage(kafkaMsg)
@@ -97,7 +131,29 @@ func (p Producer) SendKafkaErrorMessage(operation string, taskID int64, userID i
 		return fmt.Errorf("p.producer.SendMessage: %w", err)
 	}
 
-	log.Printf("Сообщение об ошибке успешно отправлено в Kafka. Тема: %s, Раздел: %d, Смещение: %d, Сообщение: %+v\n", p.topic, partition, offset, errorMessage)
+	p.log.DebugContext(ctx, "Сообщение об ошибке отправлено в Kafka", "topic", p.topic, "partition", partition, "offset", offset, "message", errorMessage)
+
+	return nil
+}
+
+// messageHeaders возвращает заголовки сообщения Kafka с идентификатором запроса из ctx
+func messageHeaders(ctx context.Context) []sarama.RecordHeader {
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return nil
+	}
+	return []sarama.RecordHeader{{Key: []byte(requestid.MetadataKey), Value: []byte(id)}}
+}
+
+// Ping проверяет доступность брокеров Kafka, обновляя метаданные топика
+func (p Producer) Ping(_ context.Context) error {
+	if p.client.Closed() {
+		return fmt.Errorf("клиент Kafka закрыт")
+	}
+
+	if err := p.client.RefreshMetadata(p.topic); err != nil {
+		return fmt.Errorf("p.client.RefreshMetadata: %w", err)
+	}
 
 	return nil
 }
@@ -109,5 +165,9 @@ func (p Producer) Close() error {
 		return fmt.Errorf("p.producer.Close: %w", err)
 	}
 
+	if err := p.client.Close(); err != nil {
+		return fmt.Errorf("p.client.Close: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,146 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/IBM/sarama"
+
+	"TODO/internal/events"
+	"TODO/internal/model"
+)
+
+// EventRelay читает сообщения о задачах из всех партиций топика и публикует их в шину событий.
+// В отличие от consumer группы каждый экземпляр сервиса получает все сообщения,
+// поэтому WebSocket клиенты видят изменения, сделанные на любом экземпляре.
+// Смещения не фиксируются: чтение начинается с новых сообщений.
+type EventRelay struct {
+	consumer sarama.Consumer
+	topic    string
+	bus      *events.Bus
+	log      *slog.Logger
+}
+
+// NewEventRelay создает EventRelay для топика topic
+func NewEventRelay(brokers []string, topic string, bus *events.Bus, log *slog.Logger) (*EventRelay, error) {
+	config := sarama.NewConfig()
+	config.Version = sarama.V2_6_0_0
+	config.Consumer.Return.Errors = true
+	config.Consumer.Offsets.Initial = sarama.OffsetNewest
+
+	consumer, err := sarama.NewConsumer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("sarama.NewConsumer: %w", err)
+	}
+
+	return &EventRelay{
+		consumer: consumer,
+		topic:    topic,
+		bus:      bus,
+		log:      log,
+	}, nil
+}
+
+// Run читает партиции топика, пока не отменен ctx, затем закрывает consumer.
+// Партиции, добавленные после запуска, не читаются до перезапуска сервиса.
+func (r *EventRelay) Run(ctx context.Context) error {
+	defer func() {
+		if err := r.consumer.Close(); err != nil {
+			r.log.Warn("Ошибка закрытия Kafka consumer", "error", err)
+		}
+	}()
+
+	partitions, err := r.consumer.Partitions(r.topic)
+	if err != nil {
+		return fmt.Errorf("r.consumer.Partitions: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, partition := range partitions {
+		pc, err := r.consumer.ConsumePartition(r.topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			wg.Wait()
+			return fmt.Errorf("r.consumer.ConsumePartition(%d): %w", partition, err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.consumePartition(ctx, pc)
+		}()
+	}
+
+	r.log.Info("Трансляция событий задач из Kafka запущена", "topic", r.topic, "partitions", len(partitions))
+	wg.Wait()
+	return nil
+}
+
+// consumePartition публикует сообщения партиции, пока не отменен ctx
+func (r *EventRelay) consumePartition(ctx context.Context, pc sarama.PartitionConsumer) {
+	defer pc.AsyncClose()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-pc.Messages():
+			if !ok {
+				return
+			}
+			event, err := decodeTaskEvent(message.Value)
+			if err != nil {
+				r.log.Warn("Пропущено сообщение о задаче", "partition", message.Partition, "offset", message.Offset, "error", err)
+				continue
+			}
+			r.bus.Publish(event)
+		case err, ok := <-pc.Errors():
+			if !ok {
+				return
+			}
+			r.log.Warn("Ошибка чтения партиции Kafka", "topic", err.Topic, "partition", err.Partition, "error", err.Err)
+		}
+	}
+}
+
+// errNotTaskEvent возвращается для сообщений, не описывающих изменение задачи
+var errNotTaskEvent = errors.New("сообщение не описывает изменение задачи")
+
+// decodeTaskEvent преобразует сообщение о задаче в событие шины
+func decodeTaskEvent(value []byte) (events.TaskEvent, error) {
+	var message TaskMessage
+	if err := json.Unmarshal(value, &message); err != nil {
+		return events.TaskEvent{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	event := events.TaskEvent{
+		Operation: message.Operation,
+		TaskID:    message.TaskID,
+		UserID:    message.UserID,
+		Timestamp: message.TimeStamp.UTC(),
+	}
+	if event.Type() == "" {
+		return events.TaskEvent{}, fmt.Errorf("%w: операция %q", errNotTaskEvent, message.Operation)
+	}
+
+	if message.Operation != events.OperationDeleteTask {
+		event.Task = &model.Task{
+			ID:      message.TaskID,
+			UserID:  message.UserID,
+			Title:   message.Title,
+			Note:    message.Note,
+			Done:    message.Done,
+			Version: message.Version,
+		}
+		if message.CreatedAt != nil {
+			event.Task.CreatedAt = *message.CreatedAt
+		}
+		if message.UpdatedAt != nil {
+			event.Task.UpdatedAt = *message.UpdatedAt
+		}
+	}
+	return event, nil
+}
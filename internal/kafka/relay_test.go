@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"TODO/internal/events"
+)
+
+func TestDecodeTaskEvent(t *testing.T) {
+	created := time.Date(2024, 12, 25, 9, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+
+	value, err := json.Marshal(TaskMessage{
+		TimeStamp: updated,
+		Operation: events.OperationUpdateTask,
+		TaskID:    10,
+		UserID:    2,
+		Title:     "купить",
+		Note:      "молоко",
+		Done:      true,
+		Version:   3,
+		CreatedAt: &created,
+		UpdatedAt: &updated,
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	event, err := decodeTaskEvent(value)
+	if err != nil {
+		t.Fatalf("decodeTaskEvent: %v", err)
+	}
+	if event.Type() != events.TypeTaskUpdated || event.TaskID != 10 || event.UserID != 2 || !event.Timestamp.Equal(updated) {
+		t.Fatalf("событие = %+v", event)
+	}
+	task := event.Task
+	if task == nil || task.ID != 10 || task.Title != "купить" || task.Note != "молоко" || !task.Done || task.Version != 3 {
+		t.Fatalf("задача события = %+v", task)
+	}
+	if !task.CreatedAt.Equal(created) || !task.UpdatedAt.Equal(updated) {
+		t.Errorf("даты задачи = %v, %v, ожидалось %v, %v", task.CreatedAt, task.UpdatedAt, created, updated)
+	}
+}
+
+func TestDecodeTaskEventDelete(t *testing.T) {
+	value, err := json.Marshal(TaskMessage{Operation: events.OperationDeleteTask, TaskID: 10, UserID: 2})
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	event, err := decodeTaskEvent(value)
+	if err != nil {
+		t.Fatalf("decodeTaskEvent: %v", err)
+	}
+	if event.Type() != events.TypeTaskDeleted || event.Task != nil {
+		t.Fatalf("событие удаления = %+v, ожидалось без задачи", event)
+	}
+}
+
+func TestDecodeTaskEventSkipsOtherMessages(t *testing.T) {
+	value, err := json.Marshal(TaskMessage{Operation: "error", TaskID: 10})
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	if _, err := decodeTaskEvent(value); !errors.Is(err, errNotTaskEvent) {
+		t.Fatalf("decodeTaskEvent(error) = %v, ожидалась errNotTaskEvent", err)
+	}
+	if _, err := decodeTaskEvent([]byte("{")); err == nil {
+		t.Fatal("decodeTaskEvent: ожидалась ошибка для некорректного JSON")
+	}
+}
@@ -0,0 +1,115 @@
+// Package logger создает структурированный журнал на основе log/slog.
+//
+// Записи дополняются идентификатором запроса и контекстом трассировки из context.Context,
+// а значения с чувствительными ключами (текст заметок, секреты, токены) скрываются.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"TODO/internal/requestid"
+)
+
+// Redacted значение, которое записывается вместо скрытых атрибутов
+const Redacted = "[REDACTED]"
+
+// redactedKeys ключи атрибутов, значения которых не попадают в журнал
+var redactedKeys = map[string]struct{}{
+	"note":     {},
+	"secret":   {},
+	"token":    {},
+	"password": {},
+}
+
+// Config содержит параметры журнала
+type Config struct {
+	Level  string // debug, info, warn или error
+	Format string // json или text
+}
+
+// New создает журнал, пишущий в w в формате и с уровнем из cfg
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("неизвестный формат журнала %q, ожидается json или text", cfg.Format)
+	}
+
+	return slog.New(contextHandler{Handler: handler}), nil
+}
+
+// ParseLevel разбирает уровень журнала
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if value == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("неизвестный уровень журнала %q: %w", value, err)
+	}
+	return level, nil
+}
+
+// Err возвращает атрибут с ошибкой
+func Err(err error) slog.Attr {
+	return slog.Any("error", err)
+}
+
+// redact скрывает значения атрибутов с ключами из redactedKeys, в том числе во вложенных группах
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	if _, ok := redactedKeys[strings.ToLower(attr.Key)]; ok && attr.Value.Kind() != slog.KindGroup {
+		return slog.String(attr.Key, Redacted)
+	}
+	return attr
+}
+
+// contextHandler дополняет записи идентификатором запроса, trace_id и span_id из контекста
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle добавляет атрибуты контекста и передает запись дальше
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if id := requestid.FromContext(ctx); id != "" {
+			record.AddAttrs(slog.String("request_id", id))
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			record.AddAttrs(
+				slog.String("trace_id", spanContext.TraceID().String()),
+				slog.String("span_id", spanContext.SpanID().String()),
+			)
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs возвращает обработчик с дополнительными атрибутами
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup возвращает обработчик с группой атрибутов
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"TODO/internal/model"
+	"TODO/internal/requestid"
+)
+
+// newTestLogger создает JSON журнал уровня debug, пишущий в buf
+func newTestLogger(t *testing.T, buf *bytes.Buffer) *slog.Logger {
+	t.Helper()
+	log, err := New(buf, Config{Level: "debug", Format: "json"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return log
+}
+
+// decodeRecord разбирает единственную запись JSON журнала
+func decodeRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("json.Unmarshal(%q): %v", buf.String(), err)
+	}
+	return record
+}
+
+func TestNewConfig(t *testing.T) {
+	tests := []struct {
+		cfg     Config
+		wantErr bool
+	}{
+		{cfg: Config{}},
+		{cfg: Config{Level: "warn", Format: "text"}},
+		{cfg: Config{Level: "DEBUG", Format: "JSON"}},
+		{cfg: Config{Level: "verbose"}, wantErr: true},
+		{cfg: Config{Format: "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if _, err := New(&bytes.Buffer{}, tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("New(%+v) error = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(&buf, Config{Level: "warn"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	log.Info("не попадает в журнал")
+	if buf.Len() != 0 {
+		t.Fatalf("запись уровня info при уровне warn: %s", buf.String())
+	}
+	log.Warn("попадает в журнал")
+	if buf.Len() == 0 {
+		t.Fatal("запись уровня warn отсутствует")
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	log := newTestLogger(t, &buf)
+
+	log.Info("сообщение",
+		"note", "личная заметка",
+		"Token", "abc",
+		slog.Group("webhook", "secret", "s3cr3t", "url", "https://example.com"),
+		"task", model.Task{ID: 1, Note: "текст задачи"},
+	)
+
+	if out := buf.String(); strings.Contains(out, "личная заметка") || strings.Contains(out, "abc") ||
+		strings.Contains(out, "s3cr3t") || strings.Contains(out, "текст задачи") {
+		t.Fatalf("чувствительные значения попали в журнал: %s", out)
+	}
+
+	record := decodeRecord(t, &buf)
+	if record["note"] != Redacted || record["Token"] != Redacted {
+		t.Errorf("note и Token = %v, %v, ожидалось %s", record["note"], record["Token"], Redacted)
+	}
+	webhook, _ := record["webhook"].(map[string]any)
+	if webhook["secret"] != Redacted || webhook["url"] != "https://example.com" {
+		t.Errorf("группа webhook = %v, ожидался скрытый secret и открытый url", webhook)
+	}
+}
+
+func TestContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	log := newTestLogger(t, &buf).With("component", "test")
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = requestid.NewContext(ctx, "req-1")
+
+	log.InfoContext(ctx, "сообщение")
+
+	record := decodeRecord(t, &buf)
+	if record["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, ожидалось req-1", record["request_id"])
+	}
+	if record["trace_id"] != traceID.String() || record["span_id"] != spanID.String() {
+		t.Errorf("trace_id и span_id = %v, %v, ожидалось %s, %s", record["trace_id"], record["span_id"], traceID, spanID)
+	}
+	if record["component"] != "test" {
+		t.Errorf("атрибут With потерян: %v", record)
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// Счетчик обработанных gRPC вызовов по методу и коду ответа
+	grpcHandledCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, by method and status code",
+		},
+		[]string{"method", "code"},
+	)
+	// Гистограмма длительности gRPC вызовов по методу и коду ответа
+	grpcHandlingSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Histogram of RPC handling latency on the server, by method and status code",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "code"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(grpcHandledCounter)
+	prometheus.MustRegister(grpcHandlingSeconds)
+}
+
+// ObserveGRPCRequest учитывает завершенный gRPC вызов: количество, код ответа и длительность
+func ObserveGRPCRequest(method, code string, duration time.Duration) {
+	grpcHandledCounter.WithLabelValues(method, code).Inc()
+	grpcHandlingSeconds.WithLabelValues(method, code).Observe(duration.Seconds())
+}
@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -52,41 +52,41 @@ func init() {
 	prometheus.MustRegister(taskDeletedCounter)
 
 	if err := LoadMetrics(); err != nil {
-		log.Printf("Ошибка при загрузке метрик: %v", err)
+		slog.Error("Ошибка при загрузке метрик", "file", metricsFile, "error", err)
 	}
 }
 
 // LoadMetrics загружает метрики из JSON-файла или создает файл с начальными значениями, если его нет
 func LoadMetrics() error {
 	if _, err := os.Stat(metricsFile); os.IsNotExist(err) {
-		log.Println("Файл метрик не найден. Создание нового файла с начальными значениями.")
+		slog.Info("Файл метрик не найден, создаем новый файл с начальными значениями", "file", metricsFile)
 
 		metricsData["tasks_created_total_created"] = &Metric{Name: "tasks_created_total", Value: 0, Status: "created"}
 		metricsData["tasks_updated_total_updated"] = &Metric{Name: "tasks_updated_total", Value: 0, Status: "updated"}
 		metricsData["tasks_deleted_total_deleted"] = &Metric{Name: "tasks_deleted_total", Value: 0, Status: "deleted"}
 
 		if err := SaveMetrics(); err != nil {
-			log.Printf("Ошибка при сохранении метрик: %v", err)
+			slog.Error("Ошибка при сохранении метрик", "error", err)
 			return err
 		}
-		log.Println("Файл метрик успешно создан и заполнен начальными значениями.")
+		slog.Debug("Файл метрик создан и заполнен начальными значениями")
 		return nil
 	}
 
 	file, err := os.Open(metricsFile)
 	if err != nil {
-		log.Printf("Ошибка при открытии файла метрик: %v", err)
+		slog.Error("Ошибка при открытии файла метрик", "file", metricsFile, "error", err)
 		return err
 	}
 	defer file.Close()
 
 	err = json.NewDecoder(file).Decode(&metricsData)
 	if err != nil {
-		log.Printf("Ошибка при декодировании метрик из файла: %v", err)
+		slog.Error("Ошибка при декодировании метрик из файла", "file", metricsFile, "error", err)
 		return err
 	}
 
-	log.Println("Файл метрик успешно загружен.")
+	slog.Debug("Файл метрик загружен", "file", metricsFile)
 
 	for _, metric := range metricsData {
 		switch metric.Name {
@@ -99,29 +99,25 @@ func LoadMetrics() error {
 		}
 	}
 
-	log.Println("Метрики успешно восстановлены в Prometheus из файла.")
+	slog.Info("Метрики восстановлены в Prometheus из файла", "file", metricsFile)
 	return nil
 }
 
 // SaveMetrics сохраняет метрики в JSON-файл
 func SaveMetrics() error {
-	log.Println("Попытка создания и записи в файл метрик...")
-
 	file, err := os.Create(metricsFile)
 	if err != nil {
-		log.Printf("Ошибка при создании файла метрик: %v", err)
+		slog.Error("Ошибка при создании файла метрик", "file", metricsFile, "error", err)
 		return err
 	}
 	defer file.Close()
 
-	log.Println("Файл метрик успешно создан, начинаем запись данных...")
-
 	err = json.NewEncoder(file).Encode(metricsData)
 	if err != nil {
-		log.Printf("Ошибка при кодировании метрик в JSON: %v", err)
+		slog.Error("Ошибка при кодировании метрик в JSON", "error", err)
 		return err
 	}
-	log.Println("Метрики успешно сохранены в файл.")
+	slog.Debug("Метрики сохранены в файл", "file", metricsFile)
 	return nil
 }
 
@@ -135,7 +131,7 @@ func IncrementTaskCreated(status string) {
 	}
 	taskCreatedCounter.WithLabelValues(status).Inc()
 	if err := SaveMetrics(); err != nil {
-		log.Printf("Ошибка при сохранении метрик после IncrementTaskCreated: %v", err)
+		slog.Error("Ошибка при сохранении метрик после IncrementTaskCreated", "error", err)
 	}
 }
 
@@ -144,7 +140,7 @@ func StartMetricsServer(addr string) {
 	http.Handle("/metrics", promhttp.Handler())
 	go func() {
 		if err := http.ListenAndServe(addr, nil); err != nil {
-			log.Printf("Ошибка при запуске сервера метрик: %v", err)
+			slog.Error("Ошибка при запуске сервера метрик", "addr", addr, "error", err)
 		}
 	}()
 }
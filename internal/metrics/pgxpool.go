@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector снимает статистику пула соединений PostgreSQL в момент запроса метрик.
+// Метрики всех пулов имеют метку pool с именем пула (primary, replica).
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+}
+
+// RegisterPoolStats регистрирует метрики пула соединений PostgreSQL под именем name
+func RegisterPoolStats(name string, pool *pgxpool.Pool) error {
+	labels := prometheus.Labels{"pool": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(metric, help, nil, labels)
+	}
+
+	return prometheus.Register(&poolCollector{
+		pool:                 pool,
+		acquiredConns:        desc("pgxpool_acquired_conns", "Number of currently acquired connections in the pool"),
+		idleConns:            desc("pgxpool_idle_conns", "Number of currently idle connections in the pool"),
+		constructingConns:    desc("pgxpool_constructing_conns", "Number of connections with construction in progress in the pool"),
+		totalConns:           desc("pgxpool_total_conns", "Total number of connections currently in the pool"),
+		maxConns:             desc("pgxpool_max_conns", "Maximum size of the pool"),
+		acquireCount:         desc("pgxpool_acquire_count_total", "Cumulative count of successful acquires from the pool"),
+		acquireDuration:      desc("pgxpool_acquire_duration_seconds_total", "Total duration of all successful acquires from the pool"),
+		canceledAcquireCount: desc("pgxpool_canceled_acquire_count_total", "Cumulative count of acquires from the pool that were canceled by a context"),
+		emptyAcquireCount:    desc("pgxpool_empty_acquire_count_total", "Cumulative count of successful acquires that waited for a connection to be released or constructed"),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.constructingConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.canceledAcquireCount
+	ch <- c.emptyAcquireCount
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+}
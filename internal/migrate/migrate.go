@@ -0,0 +1,185 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path"
+	"text/tabwriter"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	"TODO/db"
+	"TODO/internal/dao"
+)
+
+// Commands команды подкоманды migrate
+const Commands = "up|down|redo|reset|status"
+
+// Migrator применяет миграции, встроенные в бинарный файл.
+// Все изменения схемы PostgreSQL выполняются под advisory lock, поэтому
+// реплики, запущенные одновременно с автоматической миграцией, не мешают друг другу.
+type Migrator struct {
+	provider *goose.Provider
+	log      *slog.Logger
+}
+
+// New создает Migrator, подключаясь к базе с настройками пула pool
+func New(pool *pgxpool.Pool, log *slog.Logger) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания блокировки миграций: %w", err)
+	}
+
+	sqlDB := stdlib.OpenDB(*pool.Config().ConnConfig.Copy())
+	return newMigrator(goose.DialectPostgres, sqlDB, db.Migrations, "migrations", log, goose.WithSessionLocker(locker))
+}
+
+// NewSQLite создает Migrator для хранилища SQLite в файле path.
+// Схема SQLite описывается собственными миграциями из db/sqlite.
+func NewSQLite(path string, log *slog.Logger) (*Migrator, error) {
+	sqlDB, err := dao.OpenSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(goose.DialectSQLite3, sqlDB, db.SQLiteMigrations, "sqlite", log)
+}
+
+// newMigrator создает Migrator для миграций из каталога dir встроенной файловой системы.
+// Соединение sqlDB закрывается вместе с Migrator.
+func newMigrator(dialect goose.Dialect, sqlDB *sql.DB, fsys fs.FS, dir string, log *slog.Logger, opts ...goose.ProviderOption) (*Migrator, error) {
+	migrations, err := fs.Sub(fsys, dir)
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("ошибка чтения встроенных миграций: %w", err)
+	}
+
+	provider, err := goose.NewProvider(dialect, sqlDB, migrations, opts...)
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("ошибка инициализации миграций: %w", err)
+	}
+
+	return &Migrator{provider: provider, log: log}, nil
+}
+
+// Close закрывает соединение с базой
+func (m *Migrator) Close() error {
+	return m.provider.Close()
+}
+
+// Run выполняет команду подкоманды migrate, вывод status пишется в w
+func (m *Migrator) Run(ctx context.Context, command string, w io.Writer) error {
+	switch command {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "redo":
+		return m.Redo(ctx)
+	case "reset":
+		return m.Reset(ctx)
+	case "status":
+		return m.Status(ctx, w)
+	default:
+		return fmt.Errorf("неизвестная команда миграций %q, ожидается %s", command, Commands)
+	}
+}
+
+// Up применяет все ожидающие миграции
+func (m *Migrator) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	m.logResults(ctx, results...)
+	if err != nil {
+		return fmt.Errorf("ошибка применения миграций: %w", err)
+	}
+	if len(results) == 0 {
+		m.log.InfoContext(ctx, "Схема базы данных актуальна")
+	}
+	return nil
+}
+
+// Down откатывает последнюю примененную миграцию
+func (m *Migrator) Down(ctx context.Context) error {
+	result, err := m.provider.Down(ctx)
+	m.logResults(ctx, result)
+	if err != nil {
+		return fmt.Errorf("ошибка отката миграции: %w", err)
+	}
+	return nil
+}
+
+// Redo откатывает и заново применяет последнюю миграцию
+func (m *Migrator) Redo(ctx context.Context) error {
+	if err := m.Down(ctx); err != nil {
+		return err
+	}
+	result, err := m.provider.UpByOne(ctx)
+	m.logResults(ctx, result)
+	if err != nil {
+		return fmt.Errorf("ошибка повторного применения миграции: %w", err)
+	}
+	return nil
+}
+
+// Reset откатывает все миграции
+func (m *Migrator) Reset(ctx context.Context) error {
+	results, err := m.provider.DownTo(ctx, 0)
+	m.logResults(ctx, results...)
+	if err != nil {
+		return fmt.Errorf("ошибка отката миграций: %w", err)
+	}
+	return nil
+}
+
+// Status выводит состояние всех миграций
+func (m *Migrator) Status(ctx context.Context, w io.Writer) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка получения состояния миграций: %w", err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ВЕРСИЯ\tСОСТОЯНИЕ\tПРИМЕНЕНА\tМИГРАЦИЯ")
+	for _, st := range statuses {
+		appliedAt := "-"
+		if st.State == goose.StateApplied {
+			appliedAt = st.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", st.Source.Version, st.State, appliedAt, path.Base(st.Source.Path))
+	}
+	return tw.Flush()
+}
+
+// logResults пишет в журнал результаты выполненных миграций
+func (m *Migrator) logResults(ctx context.Context, results ...*goose.MigrationResult) {
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		attrs := []any{
+			"version", result.Source.Version,
+			"file", path.Base(result.Source.Path),
+			"direction", result.Direction,
+			"duration", result.Duration,
+		}
+		if result.Error != nil {
+			// Подсказка PostgreSQL (HINT) не входит в текст ошибки, а миграции объясняют в ней, как исправить данные
+			var pgErr *pgconn.PgError
+			if errors.As(result.Error, &pgErr) && pgErr.Hint != "" {
+				attrs = append(attrs, "hint", pgErr.Hint)
+			}
+			m.log.ErrorContext(ctx, "Ошибка миграции", append(attrs, "error", result.Error)...)
+			continue
+		}
+		m.log.InfoContext(ctx, "Миграция выполнена", attrs...)
+	}
+}
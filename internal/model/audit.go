@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Типы записей журнала аудита
+const (
+	AuditEntityTask = "task"
+	AuditEntityUser = "user"
+)
+
+// AuditEvent запись журнала аудита об одном изменении задачи или пользователя.
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	EntityType string          `json:"entity_type"` // task или user
+	EntityID   int64           `json:"entity_id"`
+	Operation  string          `json:"operation"` // create, update, delete, restore, purge
+	Actor      string          `json:"actor"`     // Клиент API токена, anonymous или system для фоновых задач
+	RequestID  string          `json:"request_id"`
+	Before     json.RawMessage `json:"before,omitempty"` // Состояние до изменения, пусто при создании
+	After      json.RawMessage `json:"after,omitempty"`  // Состояние после изменения, пусто при окончательном удалении
+	Diff       json.RawMessage `json:"diff"`             // Измененные поля: {"поле": {"old": ..., "new": ...}}
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter задает условия выборки журнала аудита. Пустые поля не ограничивают выборку.
+type AuditFilter struct {
+	EntityType string
+	EntityID   int64
+	Actor      string
+	Operation  string
+	RequestID  string
+	Since      *time.Time // Записи не раньше
+	Until      *time.Time // Записи раньше
+	Limit      int
+	Offset     int
+}
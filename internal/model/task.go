@@ -1,14 +1,54 @@
 package model
 
-import "time"
+import (
+	"log/slog"
+	"time"
+)
 
 // Task представляет задачу в системе.
 type Task struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	Title     string    `json:"title"`
-	Note      string    `json:"note"`
-	Done      bool      `json:"done"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Title     string     `json:"title"`
+	Note      string     `json:"note"`
+	Done      bool       `json:"done"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Version   int64      `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // Время перемещения в корзину, nil для действующих задач
+}
+
+// LogValue описывает задачу в журнале без текста заметки
+func (t Task) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("id", t.ID),
+		slog.Int64("user_id", t.UserID),
+		slog.Bool("done", t.Done),
+		slog.Int64("version", t.Version),
+	)
+}
+
+// TaskFilter задает условия выборки списка задач.
+// Пустые поля не ограничивают выборку, Limit равный нулю снимает ограничение на количество.
+type TaskFilter struct {
+	UserIDs []int64 // Владельцы задач
+	Done    *bool   // Статус выполнения
+	Limit   int     // Максимальное количество задач
+	Offset  int     // Количество пропускаемых задач
+}
+
+// TaskSearch задает полнотекстовый поиск задач.
+type TaskSearch struct {
+	Query  string // Запрос в синтаксисе websearch: слова, "фраза", or, -слово
+	UserID int64  // Владелец задач, 0 — задачи всех пользователей
+	Limit  int    // Максимальное количество результатов
+	Offset int    // Количество пропускаемых результатов
+}
+
+// TaskSearchResult задача, найденная полнотекстовым поиском.
+type TaskSearchResult struct {
+	Task           Task
+	Rank           float64 // Релевантность задачи запросу
+	TitleHighlight string  // Название с выделенными тегами <b></b> словами запроса
+	NoteHighlight  string  // Фрагменты заметки с выделенными словами запроса
 }
@@ -4,7 +4,15 @@ import "time"
 
 // User представляет пользователя системы
 type User struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	CreatedAt time.Time  `json:"created_at"`
+	Version   int64      `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // Время перемещения в корзину, nil для действующих пользователей
+}
+
+// Trash содержимое корзины: удаленные пользователи и задачи, которые еще не очищены окончательно
+type Trash struct {
+	Users []User
+	Tasks []Task
 }
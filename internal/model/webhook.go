@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Webhook представляет подписку внешней системы на события задач.
+type Webhook struct {
+	ID           int64      `json:"id"`
+	URL          string     `json:"url"`
+	Secret       string     `json:"-"`
+	EventTypes   []string   `json:"event_types"`
+	UserID       int64      `json:"user_id"` // 0 — события задач всех пользователей
+	Active       bool       `json:"active"`
+	FailureCount int        `json:"failure_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+}
+
+// WebhookDelivery представляет одну попытку доставки события подписчику.
+type WebhookDelivery struct {
+	ID         int64     `json:"id"`
+	WebhookID  int64     `json:"webhook_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,155 @@
+// Package ratelimit ограничивает частоту вызовов по алгоритму token bucket.
+//
+// Каждая пара (вызывающая сторона, метод) получает собственное ведро емкостью Burst,
+// которое пополняется со скоростью Rate токенов в секунду. Ведра хранятся в Redis,
+// чтобы все реплики сервиса видели общие счетчики, а при недоступности Redis —
+// в памяти процесса.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"TODO/internal/auth"
+)
+
+// Limit описывает ограничение: Rate запросов в секунду с допустимым всплеском Burst.
+// Нулевая скорость снимает ограничение.
+type Limit struct {
+	Rate  float64 // Скорость пополнения ведра, запросов в секунду
+	Burst int     // Емкость ведра
+}
+
+// Unlimited сообщает, что ограничение отключено
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// refillTime возвращает время, за которое пустое ведро наполняется до Burst
+func (l Limit) refillTime() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// String возвращает ограничение в формате ParseLimit
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "unlimited"
+	}
+	return strconv.FormatFloat(l.Rate, 'f', -1, 64) + ":" + strconv.Itoa(l.Burst)
+}
+
+// ParseLimit разбирает ограничение вида "rate:burst", например "10:20" или "0.5:3".
+// Если burst не указан, он равен округленной вверх скорости. Значение "0" снимает ограничение.
+func ParseLimit(value string) (Limit, error) {
+	rawRate, rawBurst, hasBurst := strings.Cut(strings.TrimSpace(value), ":")
+	rate, err := strconv.ParseFloat(strings.TrimSpace(rawRate), 64)
+	if err != nil || rate < 0 {
+		return Limit{}, fmt.Errorf("некорректная скорость в ограничении %q", value)
+	}
+	if rate == 0 {
+		return Limit{}, nil
+	}
+
+	burst := int(rate)
+	if float64(burst) < rate {
+		burst++
+	}
+	if hasBurst {
+		burst, err = strconv.Atoi(strings.TrimSpace(rawBurst))
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("некорректный всплеск в ограничении %q", value)
+		}
+	}
+	return Limit{Rate: rate, Burst: burst}, nil
+}
+
+// Result результат попытки взять токен из ведра
+type Result struct {
+	Allowed    bool          // Запрос разрешен
+	Limit      Limit         // Примененное ограничение
+	Remaining  int           // Оставшиеся токены
+	RetryAfter time.Duration // Через сколько появится токен, если запрос отклонен
+}
+
+// Store хранит ведра токенов
+type Store interface {
+	// Take пытается взять один токен из ведра key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Config содержит ограничения по умолчанию и переопределения
+type Config struct {
+	Default Limit            // Ограничение по умолчанию
+	Methods map[string]Limit // Ограничения методов (полное имя или только имя метода)
+	Callers map[string]Limit // Ограничения вызывающих сторон (имя клиента API токена или IP)
+}
+
+// Limiter выбирает ограничение для вызова и проверяет его в хранилище
+type Limiter struct {
+	store Store
+	cfg   Config
+}
+
+// New создает Limiter
+func New(store Store, cfg Config) *Limiter {
+	return &Limiter{store: store, cfg: cfg}
+}
+
+// Allow проверяет, может ли вызывающая сторона caller выполнить метод method.
+// Ограничение вызывающей стороны имеет приоритет над ограничением метода,
+// ограничение метода — над ограничением по умолчанию.
+func (l *Limiter) Allow(ctx context.Context, caller, method string) (Result, error) {
+	if l == nil {
+		return Result{Allowed: true}, nil
+	}
+
+	limit := l.limitFor(caller, method)
+	if limit.Unlimited() {
+		return Result{Allowed: true, Limit: limit}, nil
+	}
+
+	result, err := l.store.Take(ctx, "ratelimit:"+caller+":"+method, limit)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Limit = limit
+	return result, nil
+}
+
+// limitFor возвращает ограничение для пары вызывающая сторона и метод
+func (l *Limiter) limitFor(caller, method string) Limit {
+	if limit, ok := l.cfg.Callers[caller]; ok {
+		return limit
+	}
+	if limit, ok := l.cfg.Methods[method]; ok {
+		return limit
+	}
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		if limit, ok := l.cfg.Methods[method[i+1:]]; ok {
+			return limit
+		}
+	}
+	return l.cfg.Default
+}
+
+// ClientIPMetadataKey метаданные, в которых HTTP-gateway передает gRPC серверу IP адрес клиента.
+// Сервер принимает их только от доверенных адресов, см. interceptor.ClientIPUnaryInterceptor.
+const ClientIPMetadataKey = "x-client-ip"
+
+// CallerKey возвращает ключ вызывающей стороны: имя клиента API токена или IP адрес.
+// Безымянные токены учитываются по хэшу, чтобы сам токен не попадал в ключи Redis.
+func CallerKey(principal auth.Principal, ip string) string {
+	if principal.Token == "" {
+		return ip
+	}
+	if principal.Name != "" {
+		return principal.Name
+	}
+	sum := sha256.Sum256([]byte(principal.Token))
+	return "token-" + hex.EncodeToString(sum[:8])
+}
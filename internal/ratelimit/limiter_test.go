@@ -0,0 +1,75 @@
+package ratelimit
+
+import "testing"
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Limit
+		wantErr bool
+	}{
+		{value: "10:20", want: Limit{Rate: 10, Burst: 20}},
+		{value: " 0.5 : 3 ", want: Limit{Rate: 0.5, Burst: 3}},
+		{value: "5", want: Limit{Rate: 5, Burst: 5}},
+		{value: "2.5", want: Limit{Rate: 2.5, Burst: 3}},
+		{value: "0.1", want: Limit{Rate: 0.1, Burst: 1}},
+		{value: "0", want: Limit{}},
+		{value: "0:10", want: Limit{}},
+		{value: "", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "-1:5", wantErr: true},
+		{value: "10:0", wantErr: true},
+		{value: "10:-2", wantErr: true},
+		{value: "10:x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q): ошибка %v, ожидалась ошибка: %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, ожидалось %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLimitFor(t *testing.T) {
+	limiter := New(nil, Config{
+		Default: Limit{Rate: 1, Burst: 1},
+		Methods: map[string]Limit{
+			"/todo.v1.APIService/CreateTask": {Rate: 2, Burst: 2},
+			"GetTask":                        {Rate: 3, Burst: 3},
+			"ListTasks":                      {Rate: 4, Burst: 4},
+			"/todo.v1.APIService/ListTasks":  {Rate: 5, Burst: 5},
+		},
+		Callers: map[string]Limit{
+			"batch": {Rate: 100, Burst: 100},
+			"admin": {},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		caller string
+		method string
+		want   Limit
+	}{
+		{name: "по умолчанию", caller: "1.2.3.4", method: "/todo.v1.APIService/DeleteTask", want: Limit{Rate: 1, Burst: 1}},
+		{name: "полное имя метода", caller: "1.2.3.4", method: "/todo.v1.APIService/CreateTask", want: Limit{Rate: 2, Burst: 2}},
+		{name: "имя метода в любом сервисе", caller: "1.2.3.4", method: "/todo.v2.APIService/GetTask", want: Limit{Rate: 3, Burst: 3}},
+		{name: "полное имя важнее короткого", caller: "1.2.3.4", method: "/todo.v1.APIService/ListTasks", want: Limit{Rate: 5, Burst: 5}},
+		{name: "короткое имя для другого сервиса", caller: "1.2.3.4", method: "/todo.v2.APIService/ListTasks", want: Limit{Rate: 4, Burst: 4}},
+		{name: "вызывающая сторона важнее метода", caller: "batch", method: "/todo.v1.APIService/CreateTask", want: Limit{Rate: 100, Burst: 100}},
+		{name: "снятое ограничение вызывающей стороны", caller: "admin", method: "/todo.v1.APIService/CreateTask", want: Limit{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limiter.limitFor(tt.caller, tt.method); got != tt.want {
+				t.Errorf("limitFor(%q, %q) = %+v, ожидалось %+v", tt.caller, tt.method, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval период проверки ведер на полное восстановление
+const sweepInterval = time.Minute
+
+// bucket ведро токенов в памяти
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit // Ограничение последнего вызова, по нему определяется время восстановления
+}
+
+// MemoryStore хранит ведра в памяти процесса. Счетчики не разделяются между репликами.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore создает хранилище ведер в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Take пытается взять один токен из ведра key
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+	b.limit = limit
+
+	if b.tokens < 1 {
+		retryAfter := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return Result{Remaining: 0, RetryAfter: retryAfter}, nil
+	}
+
+	b.tokens--
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// sweep раз в sweepInterval удаляет ведра, которые не использовались дольше Burst/Rate.
+// За это время даже пустое ведро наполняется заново, поэтому удаление не ослабляет
+// ограничение: новое ведро создается полным.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.limit.refillTime() {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock подменяет время MemoryStore
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestMemoryStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = func() time.Time { return clock.now }
+	store.lastSweep = clock.now
+	return store, clock
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	type step struct {
+		wait          time.Duration // Пауза перед вызовом
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		limit Limit
+		steps []step
+	}{
+		{
+			name:  "всплеск до емкости ведра",
+			limit: Limit{Rate: 1, Burst: 3},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2},
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{wantAllowed: false, wantRetry: time.Second},
+			},
+		},
+		{
+			name:  "пополнение со скоростью Rate",
+			limit: Limit{Rate: 2, Burst: 1},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 0},
+				{wait: 250 * time.Millisecond, wantAllowed: false, wantRetry: 250 * time.Millisecond},
+				{wait: 250 * time.Millisecond, wantAllowed: true, wantRemaining: 0},
+			},
+		},
+		{
+			name:  "ведро не наполняется выше Burst",
+			limit: Limit{Rate: 10, Burst: 2},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 1},
+				{wait: time.Hour, wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{wantAllowed: false, wantRetry: 100 * time.Millisecond},
+			},
+		},
+		{
+			name:  "медленное ограничение переживает очистку",
+			limit: Limit{Rate: 1.0 / 600, Burst: 1},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 0},
+				{wait: 2 * sweepInterval, wantAllowed: false, wantRetry: 8 * time.Minute},
+				{wait: 8 * time.Minute, wantAllowed: true, wantRemaining: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := newTestMemoryStore()
+			for i, st := range tt.steps {
+				clock.advance(st.wait)
+				result, err := store.Take(context.Background(), "key", tt.limit)
+				if err != nil {
+					t.Fatalf("шаг %d: Take: %v", i, err)
+				}
+				if result.Allowed != st.wantAllowed || result.Remaining != st.wantRemaining {
+					t.Errorf("шаг %d: Allowed = %v, Remaining = %d, ожидалось %v, %d",
+						i, result.Allowed, result.Remaining, st.wantAllowed, st.wantRemaining)
+				}
+				if diff := result.RetryAfter - st.wantRetry; diff < -time.Millisecond || diff > time.Millisecond {
+					t.Errorf("шаг %d: RetryAfter = %v, ожидалось %v", i, result.RetryAfter, st.wantRetry)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store, clock := newTestMemoryStore()
+	ctx := context.Background()
+
+	fast := Limit{Rate: 10, Burst: 5}       // Восстанавливается за 0.5 с
+	slow := Limit{Rate: 1.0 / 60, Burst: 5} // Восстанавливается за 5 минут
+	for _, key := range []string{"fast", "slow"} {
+		limit := fast
+		if key == "slow" {
+			limit = slow
+		}
+		if _, err := store.Take(ctx, key, limit); err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+	}
+
+	clock.advance(sweepInterval)
+	if _, err := store.Take(ctx, "other", fast); err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+
+	if _, ok := store.buckets["fast"]; ok {
+		t.Error("восстановившееся ведро fast не удалено")
+	}
+	if _, ok := store.buckets["slow"]; !ok {
+		t.Error("ведро slow удалено до полного восстановления")
+	}
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// takeScript атомарно пополняет ведро и берет из него токен.
+// Время берется из Redis, чтобы реплики с расходящимися часами считали одинаково.
+// Возвращает {разрешено, оставшиеся токены, задержка до следующего токена в мс}.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(bucket[1])
+local updated = tonumber(bucket[2])
+if tokens == nil or updated == nil then
+	tokens = burst
+	updated = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate / 1000)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, math.floor(tokens), retry}
+`)
+
+// RedisStore хранит ведра в Redis, общие для всех реплик.
+// Если Redis недоступен, используются ведра в памяти процесса.
+type RedisStore struct {
+	client   *redis.Client
+	fallback Store
+	degraded atomic.Bool
+	log      *slog.Logger
+}
+
+// NewRedisStore создает хранилище ведер в Redis с запасным хранилищем fallback
+func NewRedisStore(client *redis.Client, fallback Store, log *slog.Logger) *RedisStore {
+	return &RedisStore{client: client, fallback: fallback, log: log}
+}
+
+// Take пытается взять один токен из ведра key
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	result, err := s.take(ctx, key, limit)
+	if err == nil {
+		if s.degraded.CompareAndSwap(true, false) {
+			s.log.Info("Ограничение частоты снова использует Redis")
+		}
+		return result, nil
+	}
+
+	if s.fallback == nil {
+		return Result{}, err
+	}
+	if s.degraded.CompareAndSwap(false, true) {
+		s.log.Warn("Redis недоступен, ограничение частоты работает в памяти процесса", "error", err)
+	}
+	return s.fallback.Take(ctx, key, limit)
+}
+
+// take выполняет скрипт token bucket в Redis
+func (s *RedisStore) take(ctx context.Context, key string, limit Limit) (Result, error) {
+	values, err := takeScript.Run(ctx, s.client, []string{key}, limit.Rate, limit.Burst).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ошибка ограничения частоты в Redis: %w", err)
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("неожиданный ответ Redis при ограничении частоты: %v", values)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
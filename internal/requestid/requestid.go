@@ -0,0 +1,67 @@
+// Package requestid связывает записи журнала, спаны и сообщения Kafka одного запроса
+// общим идентификатором x-request-id.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+const (
+	// Header заголовок HTTP с идентификатором запроса
+	Header = "X-Request-Id"
+	// MetadataKey ключ gRPC метаданных и заголовка Kafka с идентификатором запроса
+	MetadataKey = "x-request-id"
+	// maxLength максимальная длина идентификатора, принятого от клиента
+	maxLength = 128
+)
+
+type requestIDKey struct{}
+
+// New создает случайный идентификатор запроса в формате UUID v4
+func New() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	buf[6] = buf[6]&0x0f | 0x40
+	buf[8] = buf[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16])
+}
+
+// Valid проверяет идентификатор, полученный от клиента: непустая строка
+// из печатных ASCII символов без пробелов длиной не более 128
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Ensure возвращает id, если он корректен, иначе новый идентификатор
+func Ensure(id string) string {
+	if Valid(id) {
+		return id
+	}
+	return New()
+}
+
+// NewContext сохраняет идентификатор запроса в контексте
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromContext возвращает идентификатор запроса из контекста или пустую строку
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,60 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNew(t *testing.T) {
+	first, second := New(), New()
+	if !uuidV4.MatchString(first) {
+		t.Fatalf("New() = %q, ожидался UUID v4", first)
+	}
+	if first == second {
+		t.Fatalf("New() вернул одинаковые идентификаторы %q", first)
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "abc-123", want: true},
+		{id: "req_1:2/3", want: true},
+		{id: strings.Repeat("a", maxLength), want: true},
+		{id: "", want: false},
+		{id: strings.Repeat("a", maxLength+1), want: false},
+		{id: "with space", want: false},
+		{id: "line\nbreak", want: false},
+		{id: "кириллица", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, ожидалось %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	if got := Ensure("abc-123"); got != "abc-123" {
+		t.Errorf("Ensure сохраняет корректный идентификатор: получено %q", got)
+	}
+	if got := Ensure("bad id"); !uuidV4.MatchString(got) {
+		t.Errorf("Ensure заменяет некорректный идентификатор новым: получено %q", got)
+	}
+}
+
+func TestContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != "" {
+		t.Errorf("FromContext без идентификатора = %q", got)
+	}
+	if got := FromContext(NewContext(context.Background(), "abc")); got != "abc" {
+		t.Errorf("FromContext = %q, ожидалось abc", got)
+	}
+}
@@ -10,15 +10,19 @@ type APIServiceServer struct {
 	v1.UnimplementedAPIServiceServer // Встраиваем не реализованный сервер
 	userService                      *service.UserService
 	taskService                      *service.TaskService
+	auditService                     *service.AuditService
 }
 
-// NewAPIServiceServer создает новый APIServiceServer
+// NewAPIServiceServer создает новый APIServiceServer.
+// Если auditService равен nil, методы журнала аудита возвращают codes.Unimplemented.
 func NewAPIServiceServer(
 	userService *service.UserService,
 	taskService *service.TaskService,
+	auditService *service.AuditService,
 ) *APIServiceServer {
 	return &APIServiceServer{
-		userService: userService,
-		taskService: taskService,
+		userService:  userService,
+		taskService:  taskService,
+		auditService: auditService,
 	}
 }
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/service"
+)
+
+// APIServiceV2Server представляет реализацию интерфейса v2.APIServiceServer
+type APIServiceV2Server struct {
+	v2.UnimplementedAPIServiceServer // Встраиваем не реализованный сервер
+	userService                      *service.UserService
+	taskService                      *service.TaskService
+	auditService                     *service.AuditService
+}
+
+// NewAPIServiceV2Server создает новый APIServiceV2Server.
+// Если auditService равен nil, методы журнала аудита возвращают codes.Unimplemented.
+func NewAPIServiceV2Server(
+	userService *service.UserService,
+	taskService *service.TaskService,
+	auditService *service.AuditService,
+) *APIServiceV2Server {
+	return &APIServiceV2Server{
+		userService:  userService,
+		taskService:  taskService,
+		auditService: auditService,
+	}
+}
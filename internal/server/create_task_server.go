@@ -6,22 +6,20 @@ import (
 	"context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"strconv"
 )
 
 // CreateTask создает новую задачу
 func (s *APIServiceServer) CreateTask(ctx context.Context, req *v1.CreateTaskRequest) (*v1.CreateTaskResponse, error) {
-
-	if err := req.Validate(); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "ошибка валидации: %v", err)
-	}
-
-	taskID, err := controller.CreateTask(ctx, s.taskService, s.userService, req.UserId, req.Title, req.Note)
+	task, err := controller.CreateTask(ctx, s.taskService, s.userService, req.UserId, req.Title, req.Note)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "ошибка создания задачи: %v", err)
 	}
 
+	setLocation(ctx, "/tasks/"+strconv.FormatInt(task.ID, 10))
+
 	return &v1.CreateTaskResponse{
-		TaskId:  taskID,
+		TaskId:  task.ID,
 		Message: "Задача успешно создана",
 	}, nil
 }
@@ -3,25 +3,28 @@ package server
 import (
 	"TODO/internal/api/v1"
 	"TODO/internal/controller"
+	"TODO/internal/dao"
 	"context"
+	"errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"strconv"
 )
 
 // CreateUser создает нового пользователя
 func (s *APIServiceServer) CreateUser(ctx context.Context, req *v1.CreateUserRequest) (*v1.CreateUserResponse, error) {
-
-	if err := req.Validate(); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "ошибка валидации запроса: %v", err)
-	}
-
-	userID, err := controller.CreateUser(ctx, s.userService, req.Username)
+	user, err := controller.CreateUser(ctx, s.userService, req.Username)
 	if err != nil {
+		if errors.Is(err, dao.ErrUsernameTaken) {
+			return nil, status.Errorf(codes.AlreadyExists, "ошибка создания пользователя: %v", err)
+		}
 		return nil, status.Errorf(codes.Internal, "ошибка создания пользователя: %v", err)
 	}
 
+	setLocation(ctx, "/users/"+strconv.FormatInt(user.ID, 10))
+
 	return &v1.CreateUserResponse{
-		UserId:  userID,
+		UserId:  user.ID,
 		Message: "Пользователь успешно создан",
 	}, nil
 }
@@ -2,27 +2,35 @@ package server
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	v1 "TODO/internal/api/v1"
 	"TODO/internal/controller"
+	"TODO/internal/dao"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // DeleteTask удаляет задачу
 func (s *APIServiceServer) DeleteTask(ctx context.Context, req *v1.DeleteTaskRequest) (*emptypb.Empty, error) {
-
-	if err := req.Validate(); err != nil {
-		log.Printf("Валидация DeleteTaskRequest не прошла: %v", err)
-		return nil, status.Errorf(codes.InvalidArgument, "ошибка валидации запроса: %v", err)
+	version, err := expectedVersion(ctx, req.ExpectedVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	err := controller.DeleteTask(ctx, s.taskService, req.TaskId) // Передаем только taskID
+	err = controller.DeleteTask(ctx, s.taskService, req.TaskId, version)
 	if err != nil {
-		log.Printf("Ошибка удаления задачи: %v", err)
+		slog.ErrorContext(ctx, "Ошибка удаления задачи", "task_id", req.TaskId, "error", err)
+		switch {
+		case errors.Is(err, dao.ErrNotFound):
+			return nil, status.Errorf(codes.NotFound, "задача не найдена: %v", err)
+		case errors.Is(err, dao.ErrVersionConflict):
+			return nil, versionConflictError(fmt.Sprintf("tasks/%d", req.TaskId), err)
+		}
 		return nil, status.Errorf(codes.Internal, "ошибка удаления задачи: %v", err)
 	}
 
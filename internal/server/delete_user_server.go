@@ -2,30 +2,52 @@ package server
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	v1 "TODO/internal/api/v1"
 	"TODO/internal/controller"
+	"TODO/internal/dao"
 )
 
+// UserHasTasksViolation тип нарушения предусловия при удалении пользователя, у которого есть задачи
+const UserHasTasksViolation = "USER_HAS_TASKS"
+
 // DeleteUser удаляет пользователя
 func (s *APIServiceServer) DeleteUser(ctx context.Context, req *v1.DeleteUserRequest) (*emptypb.Empty, error) {
-
-	if err := req.Validate(); err != nil {
-		log.Printf("Валидация DeleteUserRequest не прошла: %v", err)
-		return nil, status.Errorf(codes.InvalidArgument, "ошибка валидации запроса: %v", err)
-	}
-
 	userID := int64(req.UserId)
 
 	if err := controller.DeleteUser(ctx, s.userService, userID); err != nil {
-		log.Printf("Ошибка удаления пользователя: %v", err)
+		slog.ErrorContext(ctx, "Ошибка удаления пользователя", "user_id", userID, "error", err)
+		if errors.Is(err, dao.ErrUserHasTasks) {
+			return nil, userHasTasksError(userID, err)
+		}
 		return nil, status.Errorf(codes.Internal, "ошибка удаления пользователя: %v", err)
 	}
 
 	return &emptypb.Empty{}, nil
 }
+
+// userHasTasksError возвращает FailedPrecondition для удаления пользователя с задачами
+// без каскадного удаления (USER_DELETE_CASCADE)
+func userHasTasksError(userID int64, err error) error {
+	st := status.New(codes.FailedPrecondition, fmt.Sprintf("пользователь не может быть удален: %v", err))
+
+	detailed, detailsErr := st.WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        UserHasTasksViolation,
+			Subject:     fmt.Sprintf("users/%d", userID),
+			Description: "удалите задачи пользователя и повторите запрос",
+		}},
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// VersionMismatchViolation тип нарушения предусловия при конфликте версий.
+// HTTP-gateway по нему отличает конфликт версий от прочих FailedPrecondition и отвечает 412.
+const VersionMismatchViolation = "VERSION_MISMATCH"
+
+const (
+	etagMetadataKey    = "etag"
+	ifMatchMetadataKey = "if-match"
+)
+
+// setETag отправляет версию ресурса в заголовке ответа ETag
+func setETag(ctx context.Context, version int64) {
+	if err := grpc.SetHeader(ctx, metadata.Pairs(etagMetadataKey, strconv.Quote(strconv.FormatInt(version, 10)))); err != nil {
+		slog.WarnContext(ctx, "Ошибка установки заголовка ответа", "header", "ETag", "error", err)
+	}
+}
+
+// expectedVersion возвращает ожидаемую версию ресурса из поля запроса или из заголовка If-Match.
+// Ноль означает, что проверка версии не требуется.
+func expectedVersion(ctx context.Context, requestVersion int64) (int64, error) {
+	if requestVersion > 0 {
+		return requestVersion, nil
+	}
+
+	values := metadata.ValueFromIncomingContext(ctx, ifMatchMetadataKey)
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	etag := strings.TrimSpace(values[0])
+	if etag == "*" {
+		return 0, nil
+	}
+	etag = strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+
+	version, err := strconv.ParseInt(etag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "некорректное значение If-Match: %s", values[0])
+	}
+	return version, nil
+}
+
+// versionConflictError формирует ошибку FailedPrecondition с описанием конфликта версий
+func versionConflictError(resource string, err error) error {
+	st := status.New(codes.FailedPrecondition, fmt.Sprintf("конфликт версий: %v", err))
+
+	detailed, detailsErr := st.WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        VersionMismatchViolation,
+			Subject:     resource,
+			Description: "версия ресурса изменилась, получите его заново и повторите запрос",
+		}},
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -25,8 +25,10 @@ func (s *APIServiceServer) GetAllTasks(ctx context.Context, _ *emptypb.Empty) (*
 			UserId:    task.UserID,
 			Title:     task.Title,
 			Note:      task.Note,
+			Done:      task.Done,
 			CreatedAt: task.CreatedAt.Format(time.RFC3339),
 			UpdatedAt: task.UpdatedAt.Format(time.RFC3339),
+			Version:   task.Version,
 		})
 	}
 	return response, nil
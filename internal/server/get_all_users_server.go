@@ -22,6 +22,7 @@ func (s *APIServiceServer) GetAllUsers(ctx context.Context, _ *emptypb.Empty) (*
 			UserId:    user.ID,
 			Username:  user.Username,
 			CreatedAt: user.CreatedAt.String(),
+			Version:   user.Version,
 		})
 	}
 	return response, nil
@@ -11,22 +11,21 @@ import (
 
 // GetTask возвращает задачу по ID
 func (s *APIServiceServer) GetTask(ctx context.Context, req *v1.GetTaskRequest) (*v1.GetTaskResponse, error) {
-
-	if err := req.Validate(); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "ошибка валидации: %v", err)
-	}
-
 	task, err := controller.GetTask(ctx, s.taskService, req.TaskId)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "задача не найдена: %v", err)
 	}
 
+	setETag(ctx, task.Version)
+
 	return &v1.GetTaskResponse{
 		TaskId:    task.ID,
 		Title:     task.Title,
 		Note:      task.Note,
+		Done:      task.Done,
 		UserId:    task.UserID,
 		CreatedAt: task.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: task.UpdatedAt.Format(time.RFC3339),
+		Version:   task.Version,
 	}, nil
 }
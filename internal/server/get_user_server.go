@@ -10,18 +10,17 @@ import (
 
 // GetUser возвращает информацию о пользователе по ID
 func (s *APIServiceServer) GetUser(ctx context.Context, req *v1.GetUserRequest) (*v1.GetUserResponse, error) {
-	if err := req.Validate(); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "ошибка валидации: %v", err)
-	}
-
 	user, err := controller.GetUserByID(ctx, s.userService, req.UserId)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "пользователь не найден: %v", err)
 	}
 
+	setETag(ctx, user.Version)
+
 	return &v1.GetUserResponse{
 		UserId:    user.ID,
 		Username:  user.Username,
 		CreatedAt: user.CreatedAt.String(),
+		Version:   user.Version,
 	}, nil
 }
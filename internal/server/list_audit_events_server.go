@@ -0,0 +1,110 @@
+package server
+
+import (
+	"TODO/internal/api/v1"
+	"TODO/internal/controller"
+	"TODO/internal/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"time"
+)
+
+// errAuditUnavailable возвращается, когда сервер работает без журнала аудита (хранилище SQLite)
+var errAuditUnavailable = status.Error(codes.Unimplemented, "журнал аудита доступен только с базой данных PostgreSQL")
+
+// ListAuditEvents возвращает журнал изменений пользователей и задач
+func (s *APIServiceServer) ListAuditEvents(ctx context.Context, req *v1.ListAuditEventsRequest) (*v1.ListAuditEventsResponse, error) {
+	if s.auditService == nil {
+		return nil, errAuditUnavailable
+	}
+
+	filter := model.AuditFilter{
+		EntityType: req.EntityType,
+		EntityID:   req.EntityId,
+		Actor:      req.Actor,
+		Operation:  req.Operation,
+		RequestID:  req.RequestId,
+		Limit:      int(req.Limit),
+		Offset:     int(req.Offset),
+	}
+
+	var err error
+	if filter.Since, err = parseTime("since", req.Since); err != nil {
+		return nil, err
+	}
+	if filter.Until, err = parseTime("until", req.Until); err != nil {
+		return nil, err
+	}
+
+	events, err := controller.ListAuditEvents(ctx, s.auditService, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка получения журнала аудита: %v", err)
+	}
+
+	response := &v1.ListAuditEventsResponse{}
+	for _, event := range events {
+		converted, err := auditEventToProto(event)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "ошибка преобразования записи журнала аудита: %v", err)
+		}
+		response.Events = append(response.Events, converted)
+	}
+	return response, nil
+}
+
+// parseTime разбирает необязательное время в формате RFC 3339
+func parseTime(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "поле %s должно быть временем в формате RFC 3339: %v", field, err)
+	}
+	return &parsed, nil
+}
+
+// auditEventToProto преобразует запись журнала аудита в сообщение API
+func auditEventToProto(event model.AuditEvent) (*v1.AuditEvent, error) {
+	before, err := jsonToStruct(event.Before)
+	if err != nil {
+		return nil, fmt.Errorf("поле before: %w", err)
+	}
+	after, err := jsonToStruct(event.After)
+	if err != nil {
+		return nil, fmt.Errorf("поле after: %w", err)
+	}
+	diff, err := jsonToStruct(event.Diff)
+	if err != nil {
+		return nil, fmt.Errorf("поле diff: %w", err)
+	}
+
+	return &v1.AuditEvent{
+		Id:         event.ID,
+		EntityType: event.EntityType,
+		EntityId:   event.EntityID,
+		Operation:  event.Operation,
+		Actor:      event.Actor,
+		RequestId:  event.RequestID,
+		Before:     before,
+		After:      after,
+		Diff:       diff,
+		CreatedAt:  event.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// jsonToStruct преобразует JSON объект в google.protobuf.Struct, пустое значение или null — в nil
+func jsonToStruct(data json.RawMessage) (*structpb.Struct, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
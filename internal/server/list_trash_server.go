@@ -0,0 +1,43 @@
+package server
+
+import (
+	"TODO/internal/api/v1"
+	"TODO/internal/controller"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// ListTrash возвращает удаленных пользователей и задачи, которые еще не очищены окончательно
+func (s *APIServiceServer) ListTrash(ctx context.Context, req *v1.ListTrashRequest) (*v1.ListTrashResponse, error) {
+	trash, err := controller.ListTrash(ctx, s.taskService, s.userService, req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка получения корзины: %v", err)
+	}
+
+	response := &v1.ListTrashResponse{}
+	for _, user := range trash.Users {
+		response.Users = append(response.Users, &v1.User{
+			UserId:    user.ID,
+			Username:  user.Username,
+			CreatedAt: user.CreatedAt.Format(time.RFC3339),
+			Version:   user.Version,
+			DeletedAt: user.DeletedAt.Format(time.RFC3339),
+		})
+	}
+	for _, task := range trash.Tasks {
+		response.Tasks = append(response.Tasks, &v1.Task{
+			TaskId:    task.ID,
+			UserId:    task.UserID,
+			Title:     task.Title,
+			Note:      task.Note,
+			Done:      task.Done,
+			CreatedAt: task.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: task.UpdatedAt.Format(time.RFC3339),
+			Version:   task.Version,
+			DeletedAt: task.DeletedAt.Format(time.RFC3339),
+		})
+	}
+	return response, nil
+}
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const locationMetadataKey = "location"
+
+// setLocation отправляет путь созданного ресурса, HTTP-gateway возвращает его в заголовке Location
+func setLocation(ctx context.Context, path string) {
+	if err := grpc.SetHeader(ctx, metadata.Pairs(locationMetadataKey, path)); err != nil {
+		slog.WarnContext(ctx, "Ошибка установки заголовка ответа", "header", "Location", "error", err)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "TODO/internal/api/v1"
+	"TODO/internal/controller"
+	"TODO/internal/dao"
+)
+
+// OwnerDeletedViolation тип нарушения предусловия при восстановлении задачи, владелец которой находится в корзине
+const OwnerDeletedViolation = "OWNER_DELETED"
+
+// RestoreTask восстанавливает задачу из корзины
+func (s *APIServiceServer) RestoreTask(ctx context.Context, req *v1.RestoreTaskRequest) (*v1.RestoreTaskResponse, error) {
+	task, err := controller.RestoreTask(ctx, s.taskService, s.userService, req.TaskId)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка восстановления задачи", "task_id", req.TaskId, "error", err)
+		switch {
+		case errors.Is(err, dao.ErrNotFound):
+			return nil, status.Errorf(codes.NotFound, "задача не найдена в корзине: %v", err)
+		case errors.Is(err, dao.ErrOwnerDeleted):
+			return nil, ownerDeletedError(req.TaskId, err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка восстановления задачи: %v", err)
+	}
+
+	setETag(ctx, task.Version)
+
+	return &v1.RestoreTaskResponse{
+		Task: &v1.Task{
+			TaskId:    task.ID,
+			UserId:    task.UserID,
+			Title:     task.Title,
+			Note:      task.Note,
+			Done:      task.Done,
+			CreatedAt: task.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: task.UpdatedAt.Format(time.RFC3339),
+			Version:   task.Version,
+		},
+	}, nil
+}
+
+// ownerDeletedError возвращает FailedPrecondition для восстановления задачи удаленного пользователя
+func ownerDeletedError(taskID int64, err error) error {
+	st := status.New(codes.FailedPrecondition, fmt.Sprintf("задача не может быть восстановлена: %v", err))
+
+	detailed, detailsErr := st.WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        OwnerDeletedViolation,
+			Subject:     fmt.Sprintf("tasks/%d", taskID),
+			Description: "восстановите владельца задачи и повторите запрос",
+		}},
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
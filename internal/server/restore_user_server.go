@@ -0,0 +1,37 @@
+package server
+
+import (
+	"TODO/internal/api/v1"
+	"TODO/internal/controller"
+	"TODO/internal/dao"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// RestoreUser восстанавливает пользователя из корзины вместе с задачами, удаленными вместе с ним
+func (s *APIServiceServer) RestoreUser(ctx context.Context, req *v1.RestoreUserRequest) (*v1.RestoreUserResponse, error) {
+	user, err := controller.RestoreUser(ctx, s.userService, req.UserId)
+	if err != nil {
+		switch {
+		case errors.Is(err, dao.ErrNotFound):
+			return nil, status.Errorf(codes.NotFound, "пользователь не найден в корзине: %v", err)
+		case errors.Is(err, dao.ErrUsernameTaken):
+			return nil, status.Errorf(codes.AlreadyExists, "ошибка восстановления пользователя: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка восстановления пользователя: %v", err)
+	}
+
+	setETag(ctx, user.Version)
+
+	return &v1.RestoreUserResponse{
+		User: &v1.User{
+			UserId:    user.ID,
+			Username:  user.Username,
+			CreatedAt: user.CreatedAt.Format(time.RFC3339),
+			Version:   user.Version,
+		},
+	}, nil
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"TODO/internal/api/v1"
+	"TODO/internal/controller"
+	"TODO/internal/model"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// SearchTasks ищет задачи по тексту в названии и заметке
+func (s *APIServiceServer) SearchTasks(ctx context.Context, req *v1.SearchTasksRequest) (*v1.SearchTasksResponse, error) {
+	results, total, err := controller.SearchTasks(ctx, s.taskService, model.TaskSearch{
+		Query:  req.Query,
+		UserID: req.UserId,
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка поиска задач: %v", err)
+	}
+
+	response := &v1.SearchTasksResponse{Total: total}
+	for _, result := range results {
+		task := result.Task
+		response.Results = append(response.Results, &v1.TaskSearchResult{
+			Task: &v1.Task{
+				TaskId:    task.ID,
+				UserId:    task.UserID,
+				Title:     task.Title,
+				Note:      task.Note,
+				Done:      task.Done,
+				CreatedAt: task.CreatedAt.Format(time.RFC3339),
+				UpdatedAt: task.UpdatedAt.Format(time.RFC3339),
+				Version:   task.Version,
+			},
+			Rank:           result.Rank,
+			TitleHighlight: result.TitleHighlight,
+			NoteHighlight:  result.NoteHighlight,
+		})
+	}
+	return response, nil
+}
@@ -2,26 +2,39 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	v1 "TODO/internal/api/v1"
 	"TODO/internal/controller"
+	"TODO/internal/dao"
 )
 
 // UpdateTask обновляет данные задачи
 func (s *APIServiceServer) UpdateTask(ctx context.Context, req *v1.UpdateTaskRequest) (*v1.UpdateTaskResponse, error) {
-
-	if err := req.Validate(); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "ошибка валидации: %v", err)
+	version, err := expectedVersion(ctx, req.ExpectedVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	err := controller.UpdateTask(ctx, s.taskService, req.TaskId, req.Title, req.Note, req.Done)
+	task, err := controller.UpdateTask(ctx, s.taskService, req.TaskId, req.Title, req.Note, req.Done, version)
 	if err != nil {
+		switch {
+		case errors.Is(err, dao.ErrNotFound):
+			return nil, status.Errorf(codes.NotFound, "задача не найдена: %v", err)
+		case errors.Is(err, dao.ErrVersionConflict):
+			return nil, versionConflictError(fmt.Sprintf("tasks/%d", req.TaskId), err)
+		}
 		return nil, status.Errorf(codes.Internal, "ошибка обновления задачи: %v", err)
 	}
 
+	setETag(ctx, task.Version)
+
 	return &v1.UpdateTaskResponse{
 		Message: "Задача успешно обновлена",
+		Version: task.Version,
 	}, nil
 }
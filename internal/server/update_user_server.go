@@ -3,21 +3,33 @@ package server
 import (
 	"TODO/internal/api/v1"
 	"TODO/internal/controller"
+	"TODO/internal/dao"
 	"context"
+	"errors"
+	"fmt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 // UpdateUser обновляет данные пользователя
 func (s *APIServiceServer) UpdateUser(ctx context.Context, req *v1.UpdateUserRequest) (*v1.UpdateUserResponse, error) {
-
-	if err := req.Validate(); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "ошибка валидации: %v", err)
+	version, err := expectedVersion(ctx, req.ExpectedVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := controller.UpdateUser(ctx, s.userService, req.UserId, req.Username); err != nil {
+	user, err := controller.UpdateUser(ctx, s.userService, req.UserId, req.Username, version)
+	if err != nil {
+		if errors.Is(err, dao.ErrVersionConflict) {
+			return nil, versionConflictError(fmt.Sprintf("users/%d", req.UserId), err)
+		}
+		if errors.Is(err, dao.ErrUsernameTaken) {
+			return nil, status.Errorf(codes.AlreadyExists, "ошибка обновления пользователя: %v", err)
+		}
 		return nil, status.Errorf(codes.Internal, "ошибка обновления пользователя: %v", err)
 	}
 
-	return &v1.UpdateUserResponse{Message: "Пользователь успешно обновлен"}, nil
+	setETag(ctx, user.Version)
+
+	return &v1.UpdateUserResponse{Message: "Пользователь успешно обновлен", Version: user.Version}, nil
 }
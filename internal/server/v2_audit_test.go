@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v2 "TODO/internal/api/v2"
+	"TODO/internal/model"
+)
+
+func TestAuditEventToV2(t *testing.T) {
+	occurred := time.Date(2024, 12, 25, 9, 0, 0, 0, time.UTC)
+	event := model.AuditEvent{
+		ID:         7,
+		EntityType: model.AuditEntityTask,
+		EntityID:   10,
+		Operation:  "update",
+		Actor:      "dashboard",
+		RequestID:  "req-1",
+		Before:     json.RawMessage(`{"title": "купить"}`),
+		After:      json.RawMessage(`{"title": "продать"}`),
+		Diff:       json.RawMessage(`{"title": {"old": "купить", "new": "продать"}}`),
+		CreatedAt:  occurred,
+	}
+
+	converted, err := auditEventToV2(event)
+	if err != nil {
+		t.Fatalf("auditEventToV2: %v", err)
+	}
+	if converted.Id != 7 || converted.EntityType != "task" || converted.EntityId != 10 || converted.Operation != "update" ||
+		converted.Actor != "dashboard" || converted.RequestId != "req-1" {
+		t.Fatalf("запись журнала = %+v", converted)
+	}
+	if converted.OccurredAt == nil || !converted.OccurredAt.AsTime().Equal(occurred) {
+		t.Errorf("occurred_at = %v, ожидалось %v", converted.OccurredAt, occurred)
+	}
+	if got := converted.After.GetFields()["title"].GetStringValue(); got != "продать" {
+		t.Errorf("after.title = %q, ожидалось продать", got)
+	}
+	diff := converted.Diff.GetFields()["title"].GetStructValue().GetFields()
+	if diff["old"].GetStringValue() != "купить" || diff["new"].GetStringValue() != "продать" {
+		t.Errorf("diff.title = %v", diff)
+	}
+
+	v1Event, err := auditEventToProto(event)
+	if err != nil {
+		t.Fatalf("auditEventToProto: %v", err)
+	}
+	if v1Event.CreatedAt != "2024-12-25T09:00:00Z" {
+		t.Errorf("created_at v1 = %q, ожидалось время в формате RFC 3339", v1Event.CreatedAt)
+	}
+
+	// При создании состояния до изменения нет
+	event.Before = nil
+	if converted, err = auditEventToV2(event); err != nil || converted.Before != nil {
+		t.Errorf("auditEventToV2 без before = %+v, %v, ожидалось пустое before", converted, err)
+	}
+
+	event.Diff = json.RawMessage(`[1, 2]`)
+	if _, err := auditEventToV2(event); err == nil {
+		t.Error("auditEventToV2: ожидалась ошибка для diff, который не является объектом")
+	}
+}
+
+func TestAuditTimeFilters(t *testing.T) {
+	since := time.Date(2024, 12, 25, 9, 0, 0, 0, time.UTC)
+
+	got, err := timestampToTime("since", timestamppb.New(since))
+	if err != nil || got == nil || !got.Equal(since) {
+		t.Errorf("timestampToTime = %v, %v, ожидалось %v", got, err, since)
+	}
+	if got, err := timestampToTime("since", nil); got != nil || err != nil {
+		t.Errorf("timestampToTime(nil) = %v, %v, ожидалось пустое значение", got, err)
+	}
+	if _, err := timestampToTime("since", &timestamppb.Timestamp{Seconds: 1, Nanos: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("timestampToTime с некорректным временем = %v, ожидался InvalidArgument", err)
+	}
+
+	got, err = parseTime("since", "2024-12-25T09:00:00Z")
+	if err != nil || got == nil || !got.Equal(since) {
+		t.Errorf("parseTime = %v, %v, ожидалось %v", got, err, since)
+	}
+	if got, err := parseTime("since", ""); got != nil || err != nil {
+		t.Errorf("parseTime(\"\") = %v, %v, ожидалось пустое значение", got, err)
+	}
+	if _, err := parseTime("since", "25.12.2024"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("parseTime с некорректным временем = %v, ожидался InvalidArgument", err)
+	}
+}
+
+// Без журнала аудита (хранилище SQLite) методы аудита v2 не реализованы
+func TestV2AuditUnavailable(t *testing.T) {
+	client := newTestClientV2(t)
+	ctx := context.Background()
+
+	if _, err := client.GetTaskHistory(ctx, &v2.GetTaskHistoryRequest{TaskId: 1}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("GetTaskHistory = %v, ожидался Unimplemented", err)
+	}
+	if _, err := client.ListAuditEvents(ctx, &v2.ListAuditEventsRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("ListAuditEvents = %v, ожидался Unimplemented", err)
+	}
+}
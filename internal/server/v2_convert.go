@@ -0,0 +1,103 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/model"
+	"fmt"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// taskToV2 преобразует модель задачи в сообщение API v2
+func taskToV2(task *model.Task) *v2.Task {
+	result := &v2.Task{
+		TaskId:    task.ID,
+		UserId:    task.UserID,
+		Title:     task.Title,
+		Note:      task.Note,
+		Done:      task.Done,
+		CreatedAt: timestamppb.New(task.CreatedAt),
+		UpdatedAt: timestamppb.New(task.UpdatedAt),
+		Version:   task.Version,
+	}
+	if task.DeletedAt != nil {
+		result.DeletedAt = timestamppb.New(*task.DeletedAt)
+	}
+	return result
+}
+
+// userToV2 преобразует модель пользователя в сообщение API v2
+func userToV2(user *model.User) *v2.User {
+	result := &v2.User{
+		UserId:    user.ID,
+		Username:  user.Username,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		Version:   user.Version,
+	}
+	if user.DeletedAt != nil {
+		result.DeletedAt = timestamppb.New(*user.DeletedAt)
+	}
+	return result
+}
+
+// webhookToV2 преобразует модель подписки в сообщение API v2 без секрета
+func webhookToV2(webhook *model.Webhook) *v2.Webhook {
+	result := &v2.Webhook{
+		WebhookId:    webhook.ID,
+		Url:          webhook.URL,
+		EventTypes:   webhook.EventTypes,
+		UserId:       webhook.UserID,
+		Active:       webhook.Active,
+		FailureCount: int32(webhook.FailureCount),
+		CreatedAt:    timestamppb.New(webhook.CreatedAt),
+		UpdatedAt:    timestamppb.New(webhook.UpdatedAt),
+	}
+	if webhook.DisabledAt != nil {
+		result.DisabledAt = timestamppb.New(*webhook.DisabledAt)
+	}
+	return result
+}
+
+// deliveryToV2 преобразует попытку доставки в сообщение API v2
+func deliveryToV2(delivery *model.WebhookDelivery) *v2.WebhookDelivery {
+	return &v2.WebhookDelivery{
+		DeliveryId: delivery.ID,
+		WebhookId:  delivery.WebhookID,
+		EventId:    delivery.EventID,
+		EventType:  delivery.EventType,
+		Attempt:    int32(delivery.Attempt),
+		StatusCode: int32(delivery.StatusCode),
+		Success:    delivery.Success,
+		Error:      delivery.Error,
+		DurationMs: delivery.DurationMs,
+		CreatedAt:  timestamppb.New(delivery.CreatedAt),
+	}
+}
+
+// auditEventToV2 преобразует запись журнала аудита в сообщение API v2
+func auditEventToV2(event model.AuditEvent) (*v2.AuditEvent, error) {
+	before, err := jsonToStruct(event.Before)
+	if err != nil {
+		return nil, fmt.Errorf("поле before: %w", err)
+	}
+	after, err := jsonToStruct(event.After)
+	if err != nil {
+		return nil, fmt.Errorf("поле after: %w", err)
+	}
+	diff, err := jsonToStruct(event.Diff)
+	if err != nil {
+		return nil, fmt.Errorf("поле diff: %w", err)
+	}
+
+	return &v2.AuditEvent{
+		Id:         event.ID,
+		EntityType: event.EntityType,
+		EntityId:   event.EntityID,
+		Operation:  event.Operation,
+		Actor:      event.Actor,
+		RequestId:  event.RequestID,
+		Before:     before,
+		After:      after,
+		Diff:       diff,
+		OccurredAt: timestamppb.New(event.CreatedAt),
+	}, nil
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+// CreateTask создает новую задачу и возвращает ее целиком
+func (s *APIServiceV2Server) CreateTask(ctx context.Context, req *v2.CreateTaskRequest) (*v2.Task, error) {
+	task, err := controller.CreateTask(ctx, s.taskService, s.userService, req.UserId, req.Title, req.Note)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка создания задачи: %v", err)
+	}
+
+	setETag(ctx, task.Version)
+	setLocation(ctx, "/v2/tasks/"+strconv.FormatInt(task.ID, 10))
+
+	return taskToV2(task), nil
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/dao"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+// CreateUser создает нового пользователя и возвращает его целиком
+func (s *APIServiceV2Server) CreateUser(ctx context.Context, req *v2.CreateUserRequest) (*v2.User, error) {
+	user, err := controller.CreateUser(ctx, s.userService, req.Username)
+	if err != nil {
+		if errors.Is(err, dao.ErrUsernameTaken) {
+			return nil, status.Errorf(codes.AlreadyExists, "ошибка создания пользователя: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка создания пользователя: %v", err)
+	}
+
+	setETag(ctx, user.Version)
+	setLocation(ctx, "/v2/users/"+strconv.FormatInt(user.ID, 10))
+
+	return userToV2(user), nil
+}
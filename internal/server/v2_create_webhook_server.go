@@ -0,0 +1,30 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/model"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+// CreateWebhook создает подписку и возвращает ее вместе с секретом подписи
+func (s *WebhookServiceV2Server) CreateWebhook(ctx context.Context, req *v2.CreateWebhookRequest) (*v2.Webhook, error) {
+	webhook, err := controller.CreateWebhook(ctx, s.webhookService, model.Webhook{
+		URL:        req.Url,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		UserID:     req.UserId,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка создания подписки: %v", err)
+	}
+
+	setLocation(ctx, "/v2/webhooks/"+strconv.FormatInt(webhook.ID, 10))
+
+	result := webhookToV2(webhook)
+	result.Secret = webhook.Secret
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	v2 "TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/dao"
+)
+
+// DeleteTask удаляет задачу
+func (s *APIServiceV2Server) DeleteTask(ctx context.Context, req *v2.DeleteTaskRequest) (*emptypb.Empty, error) {
+	version, err := expectedVersion(ctx, req.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := controller.DeleteTask(ctx, s.taskService, req.TaskId, version); err != nil {
+		slog.ErrorContext(ctx, "Ошибка удаления задачи", "task_id", req.TaskId, "error", err)
+		switch {
+		case errors.Is(err, dao.ErrNotFound):
+			return nil, status.Errorf(codes.NotFound, "задача не найдена: %v", err)
+		case errors.Is(err, dao.ErrVersionConflict):
+			return nil, versionConflictError(fmt.Sprintf("tasks/%d", req.TaskId), err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка удаления задачи: %v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
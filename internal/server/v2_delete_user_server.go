@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	v2 "TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/dao"
+)
+
+// DeleteUser удаляет пользователя
+func (s *APIServiceV2Server) DeleteUser(ctx context.Context, req *v2.DeleteUserRequest) (*emptypb.Empty, error) {
+	if err := controller.DeleteUser(ctx, s.userService, req.UserId); err != nil {
+		slog.ErrorContext(ctx, "Ошибка удаления пользователя", "user_id", req.UserId, "error", err)
+		if errors.Is(err, dao.ErrUserHasTasks) {
+			return nil, userHasTasksError(req.UserId, err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка удаления пользователя: %v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// DeleteWebhook удаляет подписку вместе с журналом доставок
+func (s *WebhookServiceV2Server) DeleteWebhook(ctx context.Context, req *v2.DeleteWebhookRequest) (*emptypb.Empty, error) {
+	if err := controller.DeleteWebhook(ctx, s.webhookService, req.WebhookId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "подписка не найдена: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка удаления подписки: %v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
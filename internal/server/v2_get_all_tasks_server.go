@@ -0,0 +1,25 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// GetAllTasks возвращает все задачи
+func (s *APIServiceV2Server) GetAllTasks(ctx context.Context, _ *emptypb.Empty) (*v2.GetAllTasksResponse, error) {
+
+	tasks, err := controller.GetAllTasks(ctx, s.taskService)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка получения задач: %v", err)
+	}
+
+	response := &v2.GetAllTasksResponse{}
+	for i := range tasks {
+		response.Tasks = append(response.Tasks, taskToV2(&tasks[i]))
+	}
+	return response, nil
+}
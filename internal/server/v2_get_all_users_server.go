@@ -0,0 +1,24 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// GetAllUsers возвращает всех пользователей
+func (s *APIServiceV2Server) GetAllUsers(ctx context.Context, _ *emptypb.Empty) (*v2.GetAllUsersResponse, error) {
+	users, err := controller.GetAllUsers(ctx, s.userService)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка получения пользователей: %v", err)
+	}
+
+	response := &v2.GetAllUsersResponse{}
+	for i := range users {
+		response.Users = append(response.Users, userToV2(&users[i]))
+	}
+	return response, nil
+}
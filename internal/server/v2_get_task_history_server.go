@@ -0,0 +1,31 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetTaskHistory возвращает историю изменений задачи
+func (s *APIServiceV2Server) GetTaskHistory(ctx context.Context, req *v2.GetTaskHistoryRequest) (*v2.GetTaskHistoryResponse, error) {
+	if s.auditService == nil {
+		return nil, errAuditUnavailable
+	}
+
+	events, err := controller.GetTaskHistory(ctx, s.auditService, req.TaskId, int(req.Limit), int(req.Offset))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка получения истории задачи: %v", err)
+	}
+
+	response := &v2.GetTaskHistoryResponse{}
+	for _, event := range events {
+		converted, err := auditEventToV2(event)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "ошибка преобразования записи журнала аудита: %v", err)
+		}
+		response.Events = append(response.Events, converted)
+	}
+	return response, nil
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetTask возвращает задачу по ID
+func (s *APIServiceV2Server) GetTask(ctx context.Context, req *v2.GetTaskRequest) (*v2.Task, error) {
+	task, err := controller.GetTask(ctx, s.taskService, req.TaskId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "задача не найдена: %v", err)
+	}
+
+	setETag(ctx, task.Version)
+
+	return taskToV2(task), nil
+}
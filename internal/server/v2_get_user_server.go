@@ -0,0 +1,21 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetUser возвращает пользователя по ID
+func (s *APIServiceV2Server) GetUser(ctx context.Context, req *v2.GetUserRequest) (*v2.User, error) {
+	user, err := controller.GetUserByID(ctx, s.userService, req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "пользователь не найден: %v", err)
+	}
+
+	setETag(ctx, user.Version)
+
+	return userToV2(user), nil
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetWebhook возвращает подписку по ID
+func (s *WebhookServiceV2Server) GetWebhook(ctx context.Context, req *v2.GetWebhookRequest) (*v2.Webhook, error) {
+	webhook, err := controller.GetWebhook(ctx, s.webhookService, req.WebhookId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "подписка не найдена: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка получения подписки: %v", err)
+	}
+
+	return webhookToV2(webhook), nil
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/model"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// ListAuditEvents возвращает журнал изменений пользователей и задач
+func (s *APIServiceV2Server) ListAuditEvents(ctx context.Context, req *v2.ListAuditEventsRequest) (*v2.ListAuditEventsResponse, error) {
+	if s.auditService == nil {
+		return nil, errAuditUnavailable
+	}
+
+	filter := model.AuditFilter{
+		EntityType: req.EntityType,
+		EntityID:   req.EntityId,
+		Actor:      req.Actor,
+		Operation:  req.Operation,
+		RequestID:  req.RequestId,
+		Limit:      int(req.Limit),
+		Offset:     int(req.Offset),
+	}
+
+	var err error
+	if filter.Since, err = timestampToTime("since", req.Since); err != nil {
+		return nil, err
+	}
+	if filter.Until, err = timestampToTime("until", req.Until); err != nil {
+		return nil, err
+	}
+
+	events, err := controller.ListAuditEvents(ctx, s.auditService, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка получения журнала аудита: %v", err)
+	}
+
+	response := &v2.ListAuditEventsResponse{}
+	for _, event := range events {
+		converted, err := auditEventToV2(event)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "ошибка преобразования записи журнала аудита: %v", err)
+		}
+		response.Events = append(response.Events, converted)
+	}
+	return response, nil
+}
+
+// timestampToTime преобразует необязательный Timestamp во время
+func timestampToTime(field string, value *timestamppb.Timestamp) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if err := value.CheckValid(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "поле %s содержит некорректное время: %v", field, err)
+	}
+	converted := value.AsTime()
+	return &converted, nil
+}
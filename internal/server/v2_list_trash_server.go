@@ -0,0 +1,26 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListTrash возвращает удаленных пользователей и задачи, которые еще не очищены окончательно
+func (s *APIServiceV2Server) ListTrash(ctx context.Context, req *v2.ListTrashRequest) (*v2.ListTrashResponse, error) {
+	trash, err := controller.ListTrash(ctx, s.taskService, s.userService, req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка получения корзины: %v", err)
+	}
+
+	response := &v2.ListTrashResponse{}
+	for i := range trash.Users {
+		response.Users = append(response.Users, userToV2(&trash.Users[i]))
+	}
+	for i := range trash.Tasks {
+		response.Tasks = append(response.Tasks, taskToV2(&trash.Tasks[i]))
+	}
+	return response, nil
+}
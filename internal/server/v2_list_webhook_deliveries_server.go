@@ -0,0 +1,29 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListWebhookDeliveries возвращает журнал доставок подписки, начиная с последних попыток
+func (s *WebhookServiceV2Server) ListWebhookDeliveries(ctx context.Context, req *v2.ListWebhookDeliveriesRequest) (*v2.ListWebhookDeliveriesResponse, error) {
+	deliveries, err := controller.GetWebhookDeliveries(ctx, s.webhookService, req.WebhookId, int(req.Limit), int(req.Offset))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "подписка не найдена: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка получения доставок подписки: %v", err)
+	}
+
+	response := &v2.ListWebhookDeliveriesResponse{}
+	for i := range deliveries {
+		response.Deliveries = append(response.Deliveries, deliveryToV2(&deliveries[i]))
+	}
+
+	return response, nil
+}
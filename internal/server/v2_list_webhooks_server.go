@@ -0,0 +1,25 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ListWebhooks возвращает все подписки
+func (s *WebhookServiceV2Server) ListWebhooks(ctx context.Context, _ *emptypb.Empty) (*v2.ListWebhooksResponse, error) {
+	webhooks, err := controller.GetAllWebhooks(ctx, s.webhookService)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка получения подписок: %v", err)
+	}
+
+	response := &v2.ListWebhooksResponse{}
+	for i := range webhooks {
+		response.Webhooks = append(response.Webhooks, webhookToV2(&webhooks[i]))
+	}
+
+	return response, nil
+}
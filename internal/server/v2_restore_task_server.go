@@ -0,0 +1,32 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/dao"
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RestoreTask восстанавливает задачу из корзины
+func (s *APIServiceV2Server) RestoreTask(ctx context.Context, req *v2.RestoreTaskRequest) (*v2.Task, error) {
+	task, err := controller.RestoreTask(ctx, s.taskService, s.userService, req.TaskId)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка восстановления задачи", "task_id", req.TaskId, "error", err)
+		switch {
+		case errors.Is(err, dao.ErrNotFound):
+			return nil, status.Errorf(codes.NotFound, "задача не найдена в корзине: %v", err)
+		case errors.Is(err, dao.ErrOwnerDeleted):
+			return nil, ownerDeletedError(req.TaskId, err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка восстановления задачи: %v", err)
+	}
+
+	setETag(ctx, task.Version)
+
+	return taskToV2(task), nil
+}
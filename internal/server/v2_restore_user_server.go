@@ -0,0 +1,29 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/dao"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RestoreUser восстанавливает пользователя из корзины вместе с задачами, удаленными вместе с ним
+func (s *APIServiceV2Server) RestoreUser(ctx context.Context, req *v2.RestoreUserRequest) (*v2.User, error) {
+	user, err := controller.RestoreUser(ctx, s.userService, req.UserId)
+	if err != nil {
+		switch {
+		case errors.Is(err, dao.ErrNotFound):
+			return nil, status.Errorf(codes.NotFound, "пользователь не найден в корзине: %v", err)
+		case errors.Is(err, dao.ErrUsernameTaken):
+			return nil, status.Errorf(codes.AlreadyExists, "ошибка восстановления пользователя: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка восстановления пользователя: %v", err)
+	}
+
+	setETag(ctx, user.Version)
+
+	return userToV2(user), nil
+}
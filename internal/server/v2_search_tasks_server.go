@@ -0,0 +1,34 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/model"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SearchTasks ищет задачи по тексту в названии и заметке
+func (s *APIServiceV2Server) SearchTasks(ctx context.Context, req *v2.SearchTasksRequest) (*v2.SearchTasksResponse, error) {
+	results, total, err := controller.SearchTasks(ctx, s.taskService, model.TaskSearch{
+		Query:  req.Query,
+		UserID: req.UserId,
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка поиска задач: %v", err)
+	}
+
+	response := &v2.SearchTasksResponse{Total: total}
+	for _, result := range results {
+		response.Results = append(response.Results, &v2.TaskSearchResult{
+			Task:           taskToV2(&result.Task),
+			Rank:           result.Rank,
+			TitleHighlight: result.TitleHighlight,
+			NoteHighlight:  result.NoteHighlight,
+		})
+	}
+	return response, nil
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	v2 "TODO/internal/api/v2"
+	"TODO/internal/cache"
+	"TODO/internal/dao"
+	"TODO/internal/migrate"
+	"TODO/internal/model"
+	"TODO/internal/pool"
+	"TODO/internal/service"
+)
+
+// newTestServices создает сервисы поверх новой базы SQLite во временном каталоге
+func newTestServices(t *testing.T) (*service.UserService, *service.TaskService) {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "todo.db")
+
+	migrator, err := migrate.NewSQLite(path, log)
+	if err != nil {
+		t.Fatalf("migrate.NewSQLite: %v", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrator.Up: %v", err)
+	}
+	if err := migrator.Close(); err != nil {
+		t.Fatalf("migrator.Close: %v", err)
+	}
+
+	db, err := dao.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("dao.OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	wp := pool.NewWorkerPool(2)
+	cacheConfig := cache.CacheConfig{DefaultTTL: time.Minute}
+	taskService := service.NewTaskService(dao.NewSQLiteTaskRepository(db, log), dao.NewSQLiteTransactor(db, log), wp,
+		cache.NewRedisCache[string, model.Task](nil, cacheConfig), nil, nil, log)
+	userService := service.NewUserService(dao.NewSQLiteUserRepository(db, true, log), taskService, wp,
+		cache.NewRedisCache[string, model.User](nil, cacheConfig), log)
+	return userService, taskService
+}
+
+// newTestClientV2 запускает APIServiceV2Server в памяти и возвращает клиента к нему
+func newTestClientV2(t *testing.T) v2.APIServiceClient {
+	t.Helper()
+
+	userService, taskService := newTestServices(t)
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	v2.RegisterAPIServiceServer(grpcServer, NewAPIServiceV2Server(userService, taskService, nil))
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return v2.NewAPIServiceClient(conn)
+}
+
+// Методы создания и обновления v2 возвращают ресурс целиком с датами Timestamp,
+// версию в заголовке ETag и путь созданного ресурса в Location
+func TestV2CreateAndUpdateTaskReturnFullTask(t *testing.T) {
+	client := newTestClientV2(t)
+	ctx := context.Background()
+
+	user, err := client.CreateUser(ctx, &v2.CreateUserRequest{Username: "alice"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if user.UserId <= 0 || user.Username != "alice" || user.CreatedAt == nil || user.Version != 1 {
+		t.Fatalf("CreateUser вернул неполного пользователя: %+v", user)
+	}
+
+	var header metadata.MD
+	created, err := client.CreateTask(ctx, &v2.CreateTaskRequest{UserId: user.UserId, Title: "купить", Note: "молоко"}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if created.TaskId <= 0 || created.UserId != user.UserId || created.Title != "купить" || created.Note != "молоко" || created.Done {
+		t.Fatalf("CreateTask вернул неверную задачу: %+v", created)
+	}
+	if created.CreatedAt == nil || created.UpdatedAt == nil {
+		t.Fatalf("CreateTask: ожидались created_at и updated_at, получено %+v", created)
+	}
+	if got := header.Get(etagMetadataKey); len(got) != 1 || got[0] != `"1"` {
+		t.Errorf("ETag = %v, ожидалось \"1\"", got)
+	}
+	if got := header.Get(locationMetadataKey); len(got) != 1 || got[0] == "" {
+		t.Errorf("Location = %v, ожидался путь задачи", got)
+	}
+
+	updated, err := client.UpdateTask(ctx, &v2.UpdateTaskRequest{
+		TaskId: created.TaskId, Title: "купить", Note: "кефир", Done: true, ExpectedVersion: created.Version,
+	})
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if updated.Note != "кефир" || !updated.Done || updated.Version != created.Version+1 {
+		t.Fatalf("UpdateTask вернул неверную задачу: %+v", updated)
+	}
+	if !updated.CreatedAt.AsTime().Equal(created.CreatedAt.AsTime()) {
+		t.Errorf("created_at изменился при обновлении: %v -> %v", created.CreatedAt.AsTime(), updated.CreatedAt.AsTime())
+	}
+
+	got, err := client.GetTask(ctx, &v2.GetTaskRequest{TaskId: created.TaskId})
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.Version != updated.Version || got.Note != updated.Note || got.Done != updated.Done {
+		t.Errorf("GetTask = %+v, ожидалось состояние после обновления %+v", got, updated)
+	}
+}
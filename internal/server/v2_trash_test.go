@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v2 "TODO/internal/api/v2"
+	"TODO/internal/model"
+)
+
+func TestTaskToV2DeletedAt(t *testing.T) {
+	created := time.Date(2024, 12, 25, 9, 0, 0, 0, time.UTC)
+	deleted := created.Add(time.Hour)
+
+	task := taskToV2(&model.Task{ID: 1, UserID: 2, CreatedAt: created, UpdatedAt: created})
+	if task.DeletedAt != nil {
+		t.Errorf("deleted_at активной задачи = %v, ожидалось пустое значение", task.DeletedAt)
+	}
+	task = taskToV2(&model.Task{ID: 1, UserID: 2, CreatedAt: created, UpdatedAt: created, DeletedAt: &deleted})
+	if task.DeletedAt == nil || !task.DeletedAt.AsTime().Equal(deleted) {
+		t.Errorf("deleted_at задачи в корзине = %v, ожидалось %v", task.DeletedAt, deleted)
+	}
+
+	user := userToV2(&model.User{ID: 2, Username: "alice", CreatedAt: created, DeletedAt: &deleted})
+	if user.DeletedAt == nil || !user.DeletedAt.AsTime().Equal(deleted) {
+		t.Errorf("deleted_at пользователя в корзине = %v, ожидалось %v", user.DeletedAt, deleted)
+	}
+}
+
+// Пользователь попадает в корзину вместе с задачами и восстанавливается вместе с ними,
+// отдельная задача удаленного владельца не восстанавливается
+func TestV2TrashRestore(t *testing.T) {
+	client := newTestClientV2(t)
+	ctx := context.Background()
+
+	user, err := client.CreateUser(ctx, &v2.CreateUserRequest{Username: "alice"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	task, err := client.CreateTask(ctx, &v2.CreateTaskRequest{UserId: user.UserId, Title: "купить", Note: "молоко"})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	if _, err := client.DeleteUser(ctx, &v2.DeleteUserRequest{UserId: user.UserId}); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	trash, err := client.ListTrash(ctx, &v2.ListTrashRequest{UserId: user.UserId})
+	if err != nil {
+		t.Fatalf("ListTrash: %v", err)
+	}
+	if len(trash.Users) != 1 || trash.Users[0].UserId != user.UserId || trash.Users[0].DeletedAt == nil {
+		t.Fatalf("пользователи в корзине = %+v, ожидался удаленный alice с deleted_at", trash.Users)
+	}
+	if len(trash.Tasks) != 1 || trash.Tasks[0].TaskId != task.TaskId || trash.Tasks[0].DeletedAt == nil {
+		t.Fatalf("задачи в корзине = %+v, ожидалась задача пользователя с deleted_at", trash.Tasks)
+	}
+
+	_, err = client.RestoreTask(ctx, &v2.RestoreTaskRequest{TaskId: task.TaskId})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("RestoreTask при удаленном владельце = %v, ожидался FailedPrecondition", err)
+	}
+	var violation *errdetails.PreconditionFailure
+	for _, detail := range status.Convert(err).Details() {
+		if d, ok := detail.(*errdetails.PreconditionFailure); ok {
+			violation = d
+		}
+	}
+	if violation == nil || len(violation.Violations) != 1 || violation.Violations[0].Type != OwnerDeletedViolation {
+		t.Errorf("детали ошибки = %+v, ожидалось нарушение %s", violation, OwnerDeletedViolation)
+	}
+
+	restored, err := client.RestoreUser(ctx, &v2.RestoreUserRequest{UserId: user.UserId})
+	if err != nil {
+		t.Fatalf("RestoreUser: %v", err)
+	}
+	if restored.UserId != user.UserId || restored.DeletedAt != nil {
+		t.Fatalf("RestoreUser вернул %+v, ожидался пользователь без deleted_at", restored)
+	}
+	got, err := client.GetTask(ctx, &v2.GetTaskRequest{TaskId: task.TaskId})
+	if err != nil {
+		t.Fatalf("GetTask после восстановления владельца: %v", err)
+	}
+	if got.DeletedAt != nil {
+		t.Errorf("deleted_at восстановленной задачи = %v", got.DeletedAt)
+	}
+
+	trash, err = client.ListTrash(ctx, &v2.ListTrashRequest{})
+	if err != nil {
+		t.Fatalf("ListTrash: %v", err)
+	}
+	if len(trash.Users) != 0 || len(trash.Tasks) != 0 {
+		t.Errorf("корзина после восстановления = %+v, ожидалась пустая", trash)
+	}
+
+	if _, err := client.RestoreTask(ctx, &v2.RestoreTaskRequest{TaskId: task.TaskId}); status.Code(err) != codes.NotFound {
+		t.Errorf("RestoreTask задачи вне корзины = %v, ожидался NotFound", err)
+	}
+	if _, err := client.RestoreUser(ctx, &v2.RestoreUserRequest{UserId: user.UserId}); status.Code(err) != codes.NotFound {
+		t.Errorf("RestoreUser пользователя вне корзины = %v, ожидался NotFound", err)
+	}
+}
+
+// Задача, удаленная отдельно, восстанавливается без владельца
+func TestV2RestoreTask(t *testing.T) {
+	client := newTestClientV2(t)
+	ctx := context.Background()
+
+	user, err := client.CreateUser(ctx, &v2.CreateUserRequest{Username: "alice"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	task, err := client.CreateTask(ctx, &v2.CreateTaskRequest{UserId: user.UserId, Title: "купить", Note: "молоко"})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := client.DeleteTask(ctx, &v2.DeleteTaskRequest{TaskId: task.TaskId}); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := client.GetTask(ctx, &v2.GetTaskRequest{TaskId: task.TaskId}); status.Code(err) != codes.NotFound {
+		t.Fatalf("GetTask удаленной задачи = %v, ожидался NotFound", err)
+	}
+
+	restored, err := client.RestoreTask(ctx, &v2.RestoreTaskRequest{TaskId: task.TaskId})
+	if err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	if restored.TaskId != task.TaskId || restored.Title != "купить" || restored.DeletedAt != nil {
+		t.Errorf("RestoreTask вернул %+v, ожидалась задача без deleted_at", restored)
+	}
+}
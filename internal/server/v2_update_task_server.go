@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v2 "TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/dao"
+)
+
+// UpdateTask обновляет данные задачи и возвращает ее целиком
+func (s *APIServiceV2Server) UpdateTask(ctx context.Context, req *v2.UpdateTaskRequest) (*v2.Task, error) {
+	version, err := expectedVersion(ctx, req.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := controller.UpdateTask(ctx, s.taskService, req.TaskId, req.Title, req.Note, req.Done, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, dao.ErrNotFound):
+			return nil, status.Errorf(codes.NotFound, "задача не найдена: %v", err)
+		case errors.Is(err, dao.ErrVersionConflict):
+			return nil, versionConflictError(fmt.Sprintf("tasks/%d", req.TaskId), err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка обновления задачи: %v", err)
+	}
+
+	setETag(ctx, task.Version)
+
+	return taskToV2(task), nil
+}
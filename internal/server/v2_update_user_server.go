@@ -0,0 +1,35 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/dao"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UpdateUser обновляет данные пользователя и возвращает его целиком
+func (s *APIServiceV2Server) UpdateUser(ctx context.Context, req *v2.UpdateUserRequest) (*v2.User, error) {
+	version, err := expectedVersion(ctx, req.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := controller.UpdateUser(ctx, s.userService, req.UserId, req.Username, version)
+	if err != nil {
+		if errors.Is(err, dao.ErrVersionConflict) {
+			return nil, versionConflictError(fmt.Sprintf("users/%d", req.UserId), err)
+		}
+		if errors.Is(err, dao.ErrUsernameTaken) {
+			return nil, status.Errorf(codes.AlreadyExists, "ошибка обновления пользователя: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка обновления пользователя: %v", err)
+	}
+
+	setETag(ctx, user.Version)
+
+	return userToV2(user), nil
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/controller"
+	"TODO/internal/model"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UpdateWebhook обновляет подписку и возвращает ее целиком
+func (s *WebhookServiceV2Server) UpdateWebhook(ctx context.Context, req *v2.UpdateWebhookRequest) (*v2.Webhook, error) {
+	webhook, err := controller.UpdateWebhook(ctx, s.webhookService, model.Webhook{
+		ID:         req.WebhookId,
+		URL:        req.Url,
+		EventTypes: req.EventTypes,
+		UserID:     req.UserId,
+		Active:     req.Active,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "подписка не найдена: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "ошибка обновления подписки: %v", err)
+	}
+
+	return webhookToV2(webhook), nil
+}
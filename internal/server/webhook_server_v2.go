@@ -0,0 +1,19 @@
+package server
+
+import (
+	"TODO/internal/api/v2"
+	"TODO/internal/service"
+)
+
+// WebhookServiceV2Server представляет реализацию интерфейса v2.WebhookServiceServer
+type WebhookServiceV2Server struct {
+	v2.UnimplementedWebhookServiceServer // Встраиваем не реализованный сервер
+	webhookService                       *service.WebhookService
+}
+
+// NewWebhookServiceV2Server создает новый WebhookServiceV2Server
+func NewWebhookServiceV2Server(webhookService *service.WebhookService) *WebhookServiceV2Server {
+	return &WebhookServiceV2Server{
+		webhookService: webhookService,
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"TODO/internal/dao"
+	"TODO/internal/model"
+	"context"
+	"fmt"
+
+	"TODO/internal/tracing"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultAuditLimit количество записей журнала аудита по умолчанию
+const defaultAuditLimit = 50
+
+// AuditService читает журнал изменений пользователей и задач.
+// Записи добавляют триггеры базы данных в транзакциях изменений.
+type AuditService struct {
+	pool   *pgxpool.Pool
+	tracer trace.Tracer
+}
+
+// NewAuditService создает новый сервис журнала аудита
+func NewAuditService(dbPool *pgxpool.Pool) *AuditService {
+	return &AuditService{
+		pool:   dbPool,
+		tracer: tracing.GetTracer(),
+	}
+}
+
+// GetTaskHistory получает историю изменений задачи, в том числе удаленной
+func (s *AuditService) GetTaskHistory(ctx context.Context, taskID int64, limit, offset int) ([]model.AuditEvent, error) {
+	ctx, span := s.tracer.Start(ctx, "GetTaskHistory")
+	defer span.End()
+
+	events, err := s.ListAuditEvents(ctx, model.AuditFilter{
+		EntityType: model.AuditEntityTask,
+		EntityID:   taskID,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории задачи с ID %d: %w", taskID, err)
+	}
+
+	return events, nil
+}
+
+// ListAuditEvents получает записи журнала аудита по фильтру
+func (s *AuditService) ListAuditEvents(ctx context.Context, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	ctx, span := s.tracer.Start(ctx, "ListAuditEvents")
+	defer span.End()
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+
+	events, err := dao.ListAuditEvents(ctx, filter, s.pool)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения журнала аудита: %w", err)
+	}
+
+	return events, nil
+}
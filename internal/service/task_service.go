@@ -3,67 +3,157 @@ package service
 import (
 	"TODO/internal/cache"
 	"TODO/internal/dao"
+	"TODO/internal/events"
 	"TODO/internal/kafka"
 	"TODO/internal/model"
 	"TODO/internal/pool"
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"TODO/internal/tracing"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v4"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// defaultSearchLimit количество результатов поиска задач по умолчанию
+const defaultSearchLimit = 20
+
 // TaskService управляет задачами
 type TaskService struct {
-	pool          *pgxpool.Pool
+	tasks         dao.TaskRepository
+	tx            dao.Transactor
 	wp            *pool.WorkerPool
 	taskCache     *cache.RedisCache[string, model.Task]
 	tracer        trace.Tracer
 	kafkaProducer *kafka.Producer
+	events        *events.Bus
+	log           *slog.Logger
 }
 
-// NewTaskService создаёт новый TaskService с необходимыми зависимостями
-func NewTaskService(dbPool *pgxpool.Pool, wp *pool.WorkerPool, taskCache *cache.RedisCache[string, model.Task], kafkaProducer *kafka.Producer) *TaskService {
+// NewTaskService создаёт новый TaskService с необходимыми зависимостями.
+// Задачи хранятся в репозитории tasks, единицы работы выполняет tx.
+// Шина events получает события об изменении задач, nil отключает публикацию.
+// Если kafkaProducer равен nil, сообщения в Kafka не отправляются.
+func NewTaskService(tasks dao.TaskRepository, tx dao.Transactor, wp *pool.WorkerPool, taskCache *cache.RedisCache[string, model.Task], kafkaProducer *kafka.Producer, eventBus *events.Bus, log *slog.Logger) *TaskService {
 	return &TaskService{
-		pool:          dbPool,
+		tasks:         tasks,
+		tx:            tx,
 		wp:            wp,
 		taskCache:     taskCache,
 		tracer:        tracing.GetTracer(),
 		kafkaProducer: kafkaProducer,
+		events:        eventBus,
+		log:           log,
 	}
 }
 
-// sendKafkaMessage отправляет сообщение о задаче в Kafka
-func (s *TaskService) sendKafkaMessage(operation string, taskID int64, userID int64, title, note string, done bool) error {
+// sendKafkaMessage отправляет сообщение о задаче в Kafka. При удалении task равен nil.
+func (s *TaskService) sendKafkaMessage(ctx context.Context, operation string, taskID int64, userID int64, task *model.Task) error {
+	if s.kafkaProducer == nil {
+		return nil
+	}
+
 	orderMessage := kafka.TaskMessage{
 		TimeStamp: time.Now(),
 		Operation: operation,
 		TaskID:    taskID,
 		UserID:    userID,
-		Title:     title,
-		Note:      note,
-		Done:      done,
+	}
+	if task != nil {
+		orderMessage.Title = task.Title
+		orderMessage.Note = task.Note
+		orderMessage.Done = task.Done
+		orderMessage.Version = task.Version
+		orderMessage.CreatedAt = &task.CreatedAt
+		orderMessage.UpdatedAt = &task.UpdatedAt
 	}
 
-	if err := s.kafkaProducer.SendTaskMessage(orderMessage); err != nil {
+	if err := s.kafkaProducer.SendTaskMessage(ctx, orderMessage); err != nil {
 		return fmt.Errorf("ошибка отправки сообщения о задаче в Kafka: %v", err)
 	}
 
 	return nil
 }
 
+// publishEvent публикует событие об изменении задачи во внутреннюю шину
+func (s *TaskService) publishEvent(operation string, taskID, userID int64, task *model.Task) {
+	if task != nil {
+		snapshot := *task
+		task = &snapshot
+	}
+	s.events.Publish(events.TaskEvent{
+		Operation: operation,
+		TaskID:    taskID,
+		UserID:    userID,
+		Task:      task,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// invalidateCache сбрасывает кэш задачи
+func (s *TaskService) invalidateCache(ctx context.Context, taskID int64) {
+	cacheKey := fmt.Sprintf("task_%d", taskID)
+	if err := s.taskCache.Delete(ctx, cacheKey); err != nil {
+		s.log.WarnContext(ctx, "Ошибка удаления кэша задачи", "key", cacheKey, "error", err)
+	}
+}
+
+// notifyDeleted после подтверждения транзакции отправляет в Kafka сообщение об удалении задачи,
+// публикует событие и сбрасывает кэш задачи
+func (s *TaskService) notifyDeleted(ctx context.Context, taskID, userID int64) {
+	dao.AfterCommit(ctx, func() {
+		if err := s.sendKafkaMessage(ctx, events.OperationDeleteTask, taskID, userID, nil); err != nil {
+			s.log.ErrorContext(ctx, "Ошибка отправки сообщения о задаче в Kafka", "task_id", taskID, "error", err)
+		}
+		s.publishEvent(events.OperationDeleteTask, taskID, userID, nil)
+		s.invalidateCache(ctx, taskID)
+	})
+}
+
+// notifyRestored после подтверждения транзакции отправляет в Kafka сообщение о восстановлении задачи,
+// публикует событие и сбрасывает кэш задачи
+func (s *TaskService) notifyRestored(ctx context.Context, task model.Task) {
+	dao.AfterCommit(ctx, func() {
+		if err := s.sendKafkaMessage(ctx, events.OperationRestoreTask, task.ID, task.UserID, &task); err != nil {
+			s.log.ErrorContext(ctx, "Ошибка отправки сообщения о задаче в Kafka", "task_id", task.ID, "error", err)
+		}
+		s.publishEvent(events.OperationRestoreTask, task.ID, task.UserID, &task)
+		s.invalidateCache(ctx, task.ID)
+	})
+}
+
+// NotifyTasksDeleted сообщает об удалении задач taskIDs вместе с их владельцем userID
+// так же, как DeleteTask: сообщениями в Kafka, событиями и сбросом кэша после подтверждения транзакции
+func (s *TaskService) NotifyTasksDeleted(ctx context.Context, userID int64, taskIDs []int64) {
+	for _, taskID := range taskIDs {
+		s.notifyDeleted(ctx, taskID, userID)
+	}
+}
+
+// NotifyTasksRestored сообщает о восстановлении задач вместе с их владельцем так же, как RestoreTask
+func (s *TaskService) NotifyTasksRestored(ctx context.Context, tasks []model.Task) {
+	for _, task := range tasks {
+		s.notifyRestored(ctx, task)
+	}
+}
+
+// WithinTx выполняет fn в одной сериализуемой транзакции. Вызовы сервисов внутри fn
+// присоединяются к ней, а сообщения в Kafka, события и сброс кэша откладываются до подтверждения.
+func (s *TaskService) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.tx.WithinTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, fn)
+}
+
 // CreateTask создаёт новую задачу через общий worker pool и отправляет сообщение в Kafka
-func (s *TaskService) CreateTask(ctx context.Context, userID int64, title, note string) (int64, error) {
+func (s *TaskService) CreateTask(ctx context.Context, userID int64, title, note string) (*model.Task, error) {
 	ctx, span := s.tracer.Start(ctx, "CreateTask")
 	defer span.End()
 
-	var taskID int64
+	var task *model.Task
 	errCh := make(chan error, 1)
 
-	s.wp.SubmitTask(func() {
+	submit(ctx, s.wp, func() {
 		newTask := model.Task{
 			UserID:    userID,
 			Title:     title,
@@ -74,88 +164,97 @@ func (s *TaskService) CreateTask(ctx context.Context, userID int64, title, note
 		}
 
 		var err error
-		taskID, err = dao.CreateTask(ctx, newTask, s.pool)
+		task, err = s.tasks.CreateTask(ctx, newTask)
 		if err != nil {
 			errCh <- fmt.Errorf("ошибка создания задачи: %w", err)
 			return
 		}
 
-		if err := s.sendKafkaMessage("create-task", taskID, userID, title, note, false); err != nil {
-			log.Printf("Ошибка отправки сообщения о задаче в Kafka: %v", err)
-		}
-
-		cacheKey := fmt.Sprintf("task_%d", taskID)
-		if err := s.taskCache.Delete(ctx, cacheKey); err != nil {
-			log.Printf("Ошибка удаления кэша задачи с ключом %s: %v", cacheKey, err)
-		}
+		created := *task
+		dao.AfterCommit(ctx, func() {
+			if err := s.sendKafkaMessage(ctx, events.OperationCreateTask, created.ID, userID, &created); err != nil {
+				s.log.ErrorContext(ctx, "Ошибка отправки сообщения о задаче в Kafka", "task_id", created.ID, "error", err)
+			}
+			s.publishEvent(events.OperationCreateTask, created.ID, userID, &created)
+			s.invalidateCache(ctx, created.ID)
+		})
 
 		errCh <- nil
 	})
 
-	err := <-errCh
-	return taskID, err
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
-// UpdateTask обновляет задачу и сбрасывает кэш, отправляя обновление в Kafka
-func (s *TaskService) UpdateTask(ctx context.Context, taskID int64, title, note string, done bool) error {
+// UpdateTask обновляет задачу и сбрасывает кэш, отправляя обновление в Kafka.
+// Если expectedVersion больше нуля, задача обновляется только при совпадении версии.
+func (s *TaskService) UpdateTask(ctx context.Context, taskID int64, title, note string, done bool, expectedVersion int64) (*model.Task, error) {
 	ctx, span := s.tracer.Start(ctx, "UpdateTask")
 	defer span.End()
 
+	var task *model.Task
 	errCh := make(chan error, 1)
 
-	s.wp.SubmitTask(func() {
-		task, err := dao.GetTaskByID(ctx, taskID, s.pool)
+	submit(ctx, s.wp, func() {
+		var err error
+		task, err = s.tasks.GetTaskByID(ctx, taskID)
 		if err != nil {
 			errCh <- fmt.Errorf("ошибка получения задачи с ID %d: %w", taskID, err)
 			return
 		}
 
+		if expectedVersion > 0 && task.Version != expectedVersion {
+			errCh <- fmt.Errorf("задача с ID %d имеет версию %d, ожидалась %d: %w", taskID, task.Version, expectedVersion, dao.ErrVersionConflict)
+			return
+		}
+
 		task.Title = title
 		task.Note = note
 		task.Done = done
 		task.UpdatedAt = time.Now()
 
-		if err := dao.UpdateTask(ctx, *task, s.pool); err != nil {
+		task.Version, err = s.tasks.UpdateTask(ctx, *task)
+		if err != nil {
 			errCh <- fmt.Errorf("ошибка обновления данных задачи с ID %d: %w", taskID, err)
 			return
 		}
 
-		if err := s.sendKafkaMessage("update-task", taskID, task.UserID, title, note, done); err != nil {
-			log.Printf("Ошибка отправки сообщения о задаче в Kafka: %v", err)
-		}
-
-		cacheKey := fmt.Sprintf("task_%d", taskID)
-		if err := s.taskCache.Delete(ctx, cacheKey); err != nil {
-			log.Printf("Ошибка удаления кэша задачи с ключом %s: %v", cacheKey, err)
-		}
+		updated := *task
+		dao.AfterCommit(ctx, func() {
+			if err := s.sendKafkaMessage(ctx, events.OperationUpdateTask, taskID, updated.UserID, &updated); err != nil {
+				s.log.ErrorContext(ctx, "Ошибка отправки сообщения о задаче в Kafka", "task_id", taskID, "error", err)
+			}
+			s.publishEvent(events.OperationUpdateTask, taskID, updated.UserID, &updated)
+			s.invalidateCache(ctx, taskID)
+		})
 
 		errCh <- nil
 	})
 
-	return <-errCh
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
-// DeleteTask удаляет задачу и сбрасывает кэш, отправляя удаление в Kafka
-func (s *TaskService) DeleteTask(ctx context.Context, taskID int64) error {
+// DeleteTask удаляет задачу и сбрасывает кэш, отправляя удаление в Kafka.
+// Если expectedVersion больше нуля, задача удаляется только при совпадении версии.
+func (s *TaskService) DeleteTask(ctx context.Context, taskID, expectedVersion int64) error {
 	ctx, span := s.tracer.Start(ctx, "DeleteTask")
 	defer span.End()
 
 	errCh := make(chan error, 1)
 
-	s.wp.SubmitTask(func() {
-		if err := dao.DeleteTask(ctx, taskID, s.pool); err != nil {
+	submit(ctx, s.wp, func() {
+		userID, err := s.tasks.DeleteTask(ctx, taskID, expectedVersion)
+		if err != nil {
 			errCh <- fmt.Errorf("ошибка удаления задачи с ID %d: %w", taskID, err)
 			return
 		}
 
-		if err := s.sendKafkaMessage("delete-task", taskID, 0, "", "", false); err != nil {
-			log.Printf("Ошибка отправки сообщения о задаче в Kafka: %v", err)
-		}
-
-		cacheKey := fmt.Sprintf("task_%d", taskID)
-		if err := s.taskCache.Delete(ctx, cacheKey); err != nil {
-			log.Printf("Ошибка удаления кэша задачи с ключом %s: %v", cacheKey, err)
-		}
+		s.notifyDeleted(ctx, taskID, userID)
 
 		errCh <- nil
 	})
@@ -168,7 +267,7 @@ func (s *TaskService) GetTask(ctx context.Context, taskID int64) (*model.Task, e
 	ctx, span := s.tracer.Start(ctx, "GetTask")
 	defer span.End()
 
-	task, err := dao.GetTaskByID(ctx, taskID, s.pool)
+	task, err := s.tasks.GetTaskByID(ctx, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения задачи с ID %d: %w", taskID, err)
 	}
@@ -181,10 +280,93 @@ func (s *TaskService) GetAllTasks(ctx context.Context) ([]model.Task, error) {
 	ctx, span := s.tracer.Start(ctx, "GetAllTasks")
 	defer span.End()
 
-	tasks, err := dao.GetAllTasks(ctx, s.pool)
+	tasks, err := s.tasks.GetAllTasks(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения всех задач: %w", err)
 	}
 
 	return tasks, nil
 }
+
+// ListTasks получает задачи, подходящие под фильтр
+func (s *TaskService) ListTasks(ctx context.Context, filter model.TaskFilter) ([]model.Task, error) {
+	ctx, span := s.tracer.Start(ctx, "ListTasks")
+	defer span.End()
+
+	tasks, err := s.tasks.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка задач: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины
+func (s *TaskService) RestoreTask(ctx context.Context, taskID int64) (*model.Task, error) {
+	ctx, span := s.tracer.Start(ctx, "RestoreTask")
+	defer span.End()
+
+	var task *model.Task
+	errCh := make(chan error, 1)
+
+	submit(ctx, s.wp, func() {
+		var err error
+		task, err = s.tasks.RestoreTask(ctx, taskID)
+		if err != nil {
+			errCh <- fmt.Errorf("ошибка восстановления задачи с ID %d: %w", taskID, err)
+			return
+		}
+
+		s.notifyRestored(ctx, *task)
+
+		errCh <- nil
+	})
+
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// ListDeletedTasks получает задачи из корзины, userID больше нуля оставляет только задачи пользователя
+func (s *TaskService) ListDeletedTasks(ctx context.Context, userID int64) ([]model.Task, error) {
+	ctx, span := s.tracer.Start(ctx, "ListDeletedTasks")
+	defer span.End()
+
+	tasks, err := s.tasks.ListDeletedTasks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения задач из корзины: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// PurgeTasks окончательно удаляет задачи, находящиеся в корзине дольше retention
+func (s *TaskService) PurgeTasks(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "PurgeTasks")
+	defer span.End()
+
+	purged, err := s.tasks.PurgeTasks(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки корзины задач: %w", err)
+	}
+
+	return purged, nil
+}
+
+// SearchTasks ищет задачи по тексту в названии и заметке
+func (s *TaskService) SearchTasks(ctx context.Context, search model.TaskSearch) ([]model.TaskSearchResult, int64, error) {
+	ctx, span := s.tracer.Start(ctx, "SearchTasks")
+	defer span.End()
+
+	if search.Limit <= 0 {
+		search.Limit = defaultSearchLimit
+	}
+
+	results, total, err := s.tasks.SearchTasks(ctx, search)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска задач: %w", err)
+	}
+
+	return results, total, nil
+}
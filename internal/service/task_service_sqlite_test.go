@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"TODO/internal/cache"
+	"TODO/internal/dao"
+	"TODO/internal/migrate"
+	"TODO/internal/model"
+	"TODO/internal/pool"
+)
+
+// newSQLiteServices создает сервисы поверх новой базы SQLite во временном каталоге
+func newSQLiteServices(t *testing.T, workers int) (*UserService, *TaskService) {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "todo.db")
+
+	migrator, err := migrate.NewSQLite(path, log)
+	if err != nil {
+		t.Fatalf("migrate.NewSQLite: %v", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrator.Up: %v", err)
+	}
+	if err := migrator.Close(); err != nil {
+		t.Fatalf("migrator.Close: %v", err)
+	}
+
+	db, err := dao.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("dao.OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	wp := pool.NewWorkerPool(workers)
+	cacheConfig := cache.CacheConfig{DefaultTTL: time.Minute}
+	taskService := NewTaskService(dao.NewSQLiteTaskRepository(db, log), dao.NewSQLiteTransactor(db, log), wp,
+		cache.NewRedisCache[string, model.Task](nil, cacheConfig), nil, nil, log)
+	userService := NewUserService(dao.NewSQLiteUserRepository(db, true, log), taskService, wp,
+		cache.NewRedisCache[string, model.User](nil, cacheConfig), log)
+	return userService, taskService
+}
+
+// Единица работы удерживает единственное соединение SQLite. Вызовы сервисов внутри нее
+// не должны ждать воркеров, занятых запросами, которые ждут это соединение.
+func TestTaskServiceConcurrentUnitsOfWorkSQLite(t *testing.T) {
+	userService, taskService := newSQLiteServices(t, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := userService.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	// Пока единица работы удерживает соединение, оба воркера занимают запросы, ожидающие его
+	const workers = 2
+	var wg sync.WaitGroup
+	errs := make(chan error, workers+1)
+	done := make(chan struct{})
+	go func() {
+		errs <- taskService.WithinTx(ctx, func(txCtx context.Context) error {
+			if _, err := userService.GetUserByID(txCtx, user.ID); err != nil {
+				return err
+			}
+
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := taskService.CreateTask(ctx, user.ID, "задача", "")
+					errs <- err
+				}()
+			}
+			time.Sleep(100 * time.Millisecond)
+
+			_, err := taskService.CreateTask(txCtx, user.ID, "задача в транзакции", "")
+			return err
+		})
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("запросы не завершились: взаимная блокировка воркеров и соединения SQLite")
+	}
+
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("запрос завершился с ошибкой: %v", err)
+		}
+	}
+
+	tasks, err := taskService.GetAllTasks(ctx)
+	if err != nil {
+		t.Fatalf("GetAllTasks: %v", err)
+	}
+	if len(tasks) != workers+1 {
+		t.Fatalf("GetAllTasks вернул %d задач, ожидалось %d", len(tasks), workers+1)
+	}
+}
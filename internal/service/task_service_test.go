@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"TODO/internal/cache"
+	"TODO/internal/dao"
+	"TODO/internal/events"
+	"TODO/internal/model"
+	"TODO/internal/pool"
+)
+
+// newMemoryServices создает сервисы поверх хранилищ в памяти и шину событий, на которую подписан тест
+func newMemoryServices(t *testing.T, cascadeTasks bool) (*UserService, *TaskService, *events.Subscription) {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tasks := dao.NewMemoryTaskRepository()
+	users := dao.NewMemoryUserRepository(tasks, cascadeTasks)
+
+	wp := pool.NewWorkerPool(2)
+	bus := events.NewBus()
+	sub := bus.Subscribe(100, nil)
+	t.Cleanup(sub.Close)
+
+	cacheConfig := cache.CacheConfig{DefaultTTL: time.Minute}
+	taskService := NewTaskService(tasks, dao.NewMemoryTransactor(tasks, users), wp,
+		cache.NewRedisCache[string, model.Task](nil, cacheConfig), nil, bus, log)
+	userService := NewUserService(users, taskService, wp,
+		cache.NewRedisCache[string, model.User](nil, cacheConfig), log)
+	return userService, taskService, sub
+}
+
+// receivedEvents возвращает события, уже опубликованные в шину
+func receivedEvents(sub *events.Subscription) []events.TaskEvent {
+	var received []events.TaskEvent
+	for {
+		select {
+		case event := <-sub.Events():
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func TestTaskServiceLifecycle(t *testing.T) {
+	userService, taskService, sub := newMemoryServices(t, false)
+	ctx := context.Background()
+
+	user, err := userService.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	task, err := taskService.CreateTask(ctx, user.ID, "купить молоко", "")
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	updated, err := taskService.UpdateTask(ctx, task.ID, "купить молоко", "2 литра", true, task.Version)
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if !updated.Done || updated.Version != task.Version+1 {
+		t.Fatalf("UpdateTask вернул %+v, ожидалась выполненная задача версии %d", updated, task.Version+1)
+	}
+
+	if _, err := taskService.UpdateTask(ctx, task.ID, "другое", "", false, task.Version); !errors.Is(err, dao.ErrVersionConflict) {
+		t.Fatalf("UpdateTask со старой версией: ошибка %v, ожидалась ErrVersionConflict", err)
+	}
+
+	if err := taskService.DeleteTask(ctx, task.ID, updated.Version); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := taskService.GetTask(ctx, task.ID); !errors.Is(err, dao.ErrNotFound) {
+		t.Fatalf("GetTask удаленной задачи: ошибка %v, ожидалась ErrNotFound", err)
+	}
+
+	restored, err := taskService.RestoreTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	if restored.Note != "2 литра" {
+		t.Fatalf("RestoreTask вернул заметку %q, ожидалась %q", restored.Note, "2 литра")
+	}
+
+	want := []string{events.OperationCreateTask, events.OperationUpdateTask, events.OperationDeleteTask, events.OperationRestoreTask}
+	got := receivedEvents(sub)
+	if len(got) != len(want) {
+		t.Fatalf("получено %d событий, ожидалось %d: %+v", len(got), len(want), got)
+	}
+	for i, event := range got {
+		if event.Operation != want[i] || event.TaskID != task.ID || event.UserID != user.ID {
+			t.Errorf("событие %d: %+v, ожидалась операция %s над задачей %d пользователя %d", i, event, want[i], task.ID, user.ID)
+		}
+	}
+}
+
+func TestTaskServiceWithinTxRollback(t *testing.T) {
+	userService, taskService, sub := newMemoryServices(t, false)
+	ctx := context.Background()
+
+	user, err := userService.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	errRollback := errors.New("откат")
+	err = taskService.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := taskService.CreateTask(ctx, user.ID, "не сохранится", ""); err != nil {
+			return err
+		}
+		if _, err := userService.UpdateUser(ctx, user.ID, "bob", 0); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithinTx: ошибка %v, ожидалась %v", err, errRollback)
+	}
+
+	tasks, err := taskService.GetAllTasks(ctx)
+	if err != nil {
+		t.Fatalf("GetAllTasks: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Fatalf("после отката осталось %d задач", len(tasks))
+	}
+
+	got, err := userService.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if got.Username != "alice" || got.Version != user.Version {
+		t.Fatalf("после отката пользователь %+v, ожидался %+v", got, user)
+	}
+
+	if events := receivedEvents(sub); len(events) != 0 {
+		t.Fatalf("после отката опубликованы события: %+v", events)
+	}
+}
+
+func TestTaskServiceWithinTxCommit(t *testing.T) {
+	userService, taskService, sub := newMemoryServices(t, false)
+	ctx := context.Background()
+
+	user, err := userService.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	err = taskService.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := taskService.CreateTask(ctx, user.ID, "первая", ""); err != nil {
+			return err
+		}
+		if _, err := taskService.CreateTask(ctx, user.ID, "вторая", ""); err != nil {
+			return err
+		}
+		if events := receivedEvents(sub); len(events) != 0 {
+			t.Errorf("события опубликованы до подтверждения: %+v", events)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+
+	if events := receivedEvents(sub); len(events) != 2 {
+		t.Fatalf("после подтверждения опубликовано %d событий, ожидалось 2", len(events))
+	}
+}
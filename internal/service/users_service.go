@@ -7,63 +7,77 @@ import (
 	"TODO/internal/pool"
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"TODO/internal/tracing"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // UserService представляет сервис для работы с пользователями
 type UserService struct {
-	pool   *pgxpool.Pool
+	users  dao.UserRepository
+	tasks  *TaskService
 	wp     *pool.WorkerPool
 	cache  *cache.RedisCache[string, model.User]
 	tracer trace.Tracer
+	log    *slog.Logger
 }
 
-// NewUserService создает новый сервис для работы с пользователями
-func NewUserService(dbPool *pgxpool.Pool, workerPool *pool.WorkerPool, cache *cache.RedisCache[string, model.User]) *UserService {
+// NewUserService создает новый сервис для работы с пользователями,
+// хранящимися в репозитории users. Через tasks сервис сообщает о задачах,
+// удаленных и восстановленных вместе с пользователем, nil отключает уведомления.
+func NewUserService(users dao.UserRepository, tasks *TaskService, workerPool *pool.WorkerPool, cache *cache.RedisCache[string, model.User], log *slog.Logger) *UserService {
 	return &UserService{
-		pool:   dbPool,
+		users:  users,
+		tasks:  tasks,
 		wp:     workerPool,
 		cache:  cache,
 		tracer: tracing.GetTracer(),
+		log:    log,
+	}
+}
+
+// invalidateCache сбрасывает кэш пользователя и его имени
+func (s *UserService) invalidateCache(ctx context.Context, userID int64) {
+	for _, cacheKey := range []string{fmt.Sprintf("user_%d", userID), fmt.Sprintf("user_name_%d", userID)} {
+		if err := s.cache.Delete(ctx, cacheKey); err != nil {
+			s.log.WarnContext(ctx, "Ошибка удаления кэша пользователя", "key", cacheKey, "error", err)
+		}
 	}
 }
 
 // CreateUser создает нового пользователя через общий worker pool
-func (s *UserService) CreateUser(ctx context.Context, username string) (int64, error) {
+func (s *UserService) CreateUser(ctx context.Context, username string) (*model.User, error) {
 	ctx, span := s.tracer.Start(ctx, "CreateUser")
 	defer span.End()
 
-	var userID int64
+	var user *model.User
 	errCh := make(chan error, 1)
 
-	s.wp.SubmitTask(func() {
+	submit(ctx, s.wp, func() {
 		newUser := model.User{
 			Username:  username,
 			CreatedAt: time.Now().UTC(),
 		}
 
 		var err error
-		userID, err = dao.CreateUser(ctx, newUser, s.pool)
+		user, err = s.users.CreateUser(ctx, newUser)
 		if err != nil {
 			errCh <- fmt.Errorf("ошибка создания пользователя: %w", err)
 			return
 		}
 
-		cacheKey := fmt.Sprintf("user_%d", userID)
-		if err := s.cache.Delete(ctx, cacheKey); err != nil {
-			log.Printf("Ошибка удаления кэша пользователя с ключом %s: %v", cacheKey, err)
-		}
+		createdID := user.ID
+		dao.AfterCommit(ctx, func() { s.invalidateCache(ctx, createdID) })
 
 		errCh <- nil
 	})
 
-	err := <-errCh
-	return userID, err
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 // GetUserByID возвращает пользователя по его ID с использованием кэша
@@ -71,25 +85,34 @@ func (s *UserService) GetUserByID(ctx context.Context, userID int64) (*model.Use
 	ctx, span := s.tracer.Start(ctx, "GetUserByID")
 	defer span.End()
 
+	// Внутри транзакции пользователь читается из базы, чтобы проверка попала в ту же транзакцию
+	if dao.InTx(ctx) {
+		user, err := s.users.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка получения пользователя с ID %d: %w", userID, err)
+		}
+		return user, nil
+	}
+
 	cacheKey := fmt.Sprintf("user_%d", userID)
 
 	var cachedUser model.User
 	err := s.cache.Get(ctx, cacheKey, &cachedUser)
 	if err == nil {
-		log.Printf("Пользователь с ID %d получен из кэша", userID)
+		s.log.DebugContext(ctx, "Пользователь получен из кэша", "user_id", userID)
 		return &cachedUser, nil
 	} else {
-		log.Printf("Пользователь с ID %d не найден в кэше, получаем из базы данных", userID)
+		s.log.DebugContext(ctx, "Пользователь не найден в кэше, получаем из базы данных", "user_id", userID)
 	}
 
-	user, err := dao.GetUserByID(ctx, userID, s.pool)
+	user, err := s.users.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения пользователя с ID %d: %w", userID, err)
 	}
 
 	err = s.cache.Set(ctx, cacheKey, *user, 10*time.Minute)
 	if err != nil {
-		log.Printf("Ошибка сохранения пользователя в кэш: %v", err)
+		s.log.WarnContext(ctx, "Ошибка сохранения пользователя в кэш", "user_id", userID, "error", err)
 	}
 
 	return user, nil
@@ -100,63 +123,74 @@ func (s *UserService) GetAllUsers(ctx context.Context) ([]model.User, error) {
 	ctx, span := s.tracer.Start(ctx, "GetAllUsers")
 	defer span.End()
 
-	users, err := dao.GetAllUsers(ctx, s.pool)
+	users, err := s.users.GetAllUsers(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения всех пользователей: %w", err)
 	}
 
-	log.Printf("Получены все пользователи: %+v", users)
+	s.log.DebugContext(ctx, "Получены все пользователи", "count", len(users))
 	return users, nil
 }
 
-// UpdateUser обновляет данные пользователя и сбрасывает кэш
-func (s *UserService) UpdateUser(ctx context.Context, userID int64, username string) error {
+// UpdateUser обновляет данные пользователя и сбрасывает кэш.
+// Если expectedVersion больше нуля, пользователь обновляется только при совпадении версии.
+func (s *UserService) UpdateUser(ctx context.Context, userID int64, username string, expectedVersion int64) (*model.User, error) {
 	ctx, span := s.tracer.Start(ctx, "UpdateUser")
 	defer span.End()
 
+	var user *model.User
 	errCh := make(chan error, 1)
 
-	s.wp.SubmitTask(func() {
-		user, err := dao.GetUserByID(ctx, userID, s.pool)
+	submit(ctx, s.wp, func() {
+		var err error
+		user, err = s.users.GetUserByID(ctx, userID)
 		if err != nil {
 			errCh <- fmt.Errorf("ошибка получения пользователя с ID %d: %w", userID, err)
 			return
 		}
 
+		if expectedVersion > 0 && user.Version != expectedVersion {
+			errCh <- fmt.Errorf("пользователь с ID %d имеет версию %d, ожидалась %d: %w", userID, user.Version, expectedVersion, dao.ErrVersionConflict)
+			return
+		}
+
 		user.Username = username
 
-		if err := dao.UpdateUser(ctx, *user, s.pool); err != nil {
+		user.Version, err = s.users.UpdateUser(ctx, *user)
+		if err != nil {
 			errCh <- fmt.Errorf("ошибка обновления данных пользователя с ID %d: %w", userID, err)
 			return
 		}
 
-		cacheKey := fmt.Sprintf("user_%d", userID)
-		if err := s.cache.Delete(ctx, cacheKey); err != nil {
-			log.Printf("Ошибка удаления кэша пользователя с ключом %s: %v", cacheKey, err)
-		}
+		dao.AfterCommit(ctx, func() { s.invalidateCache(ctx, userID) })
 
 		errCh <- nil
 	})
 
-	return <-errCh
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
-// DeleteUser удаляет пользователя и сбрасывает кэш
+// DeleteUser удаляет пользователя и сбрасывает кэш. О задачах, удаленных вместе с ним,
+// сообщается так же, как при удалении отдельной задачи.
 func (s *UserService) DeleteUser(ctx context.Context, userID int64) error {
 	ctx, span := s.tracer.Start(ctx, "DeleteUser")
 	defer span.End()
 
 	errCh := make(chan error, 1)
 
-	s.wp.SubmitTask(func() {
-		if err := dao.DeleteUser(ctx, userID, s.pool); err != nil {
+	submit(ctx, s.wp, func() {
+		taskIDs, err := s.users.DeleteUser(ctx, userID)
+		if err != nil {
 			errCh <- fmt.Errorf("ошибка удаления пользователя с ID %d: %w", userID, err)
 			return
 		}
 
-		cacheKey := fmt.Sprintf("user_%d", userID)
-		if err := s.cache.Delete(ctx, cacheKey); err != nil {
-			log.Printf("Ошибка удаления кэша пользователя с ключом %s: %v", cacheKey, err)
+		dao.AfterCommit(ctx, func() { s.invalidateCache(ctx, userID) })
+		if s.tasks != nil {
+			s.tasks.NotifyTasksDeleted(ctx, userID, taskIDs)
 		}
 
 		errCh <- nil
@@ -165,6 +199,64 @@ func (s *UserService) DeleteUser(ctx context.Context, userID int64) error {
 	return <-errCh
 }
 
+// RestoreUser возвращает пользователя из корзины вместе с задачами, удаленными вместе с ним, и сбрасывает кэш.
+// О восстановленных задачах сообщается так же, как при восстановлении отдельной задачи.
+func (s *UserService) RestoreUser(ctx context.Context, userID int64) (*model.User, error) {
+	ctx, span := s.tracer.Start(ctx, "RestoreUser")
+	defer span.End()
+
+	var user *model.User
+	errCh := make(chan error, 1)
+
+	submit(ctx, s.wp, func() {
+		var tasks []model.Task
+		var err error
+		user, tasks, err = s.users.RestoreUser(ctx, userID)
+		if err != nil {
+			errCh <- fmt.Errorf("ошибка восстановления пользователя с ID %d: %w", userID, err)
+			return
+		}
+
+		dao.AfterCommit(ctx, func() { s.invalidateCache(ctx, userID) })
+		if s.tasks != nil {
+			s.tasks.NotifyTasksRestored(ctx, tasks)
+		}
+
+		errCh <- nil
+	})
+
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListDeletedUsers возвращает пользователей из корзины, userID больше нуля оставляет только этого пользователя
+func (s *UserService) ListDeletedUsers(ctx context.Context, userID int64) ([]model.User, error) {
+	ctx, span := s.tracer.Start(ctx, "ListDeletedUsers")
+	defer span.End()
+
+	users, err := s.users.ListDeletedUsers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей из корзины: %w", err)
+	}
+
+	return users, nil
+}
+
+// PurgeUsers окончательно удаляет пользователей, находящихся в корзине дольше retention, вместе с их задачами
+func (s *UserService) PurgeUsers(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "PurgeUsers")
+	defer span.End()
+
+	purged, err := s.users.PurgeUsers(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки корзины пользователей: %w", err)
+	}
+
+	return purged, nil
+}
+
 // GetUserNameByID возвращает имя пользователя по его ID через worker pool
 func (s *UserService) GetUserNameByID(ctx context.Context, userID int64) (string, error) {
 	ctx, span := s.tracer.Start(ctx, "GetUserNameByID")
@@ -175,20 +267,20 @@ func (s *UserService) GetUserNameByID(ctx context.Context, userID int64) (string
 	var cachedUsername string
 	err := s.cache.Get(ctx, cacheKey, &cachedUsername)
 	if err == nil {
-		log.Printf("Имя пользователя с ID %d получено из кэша", userID)
+		s.log.DebugContext(ctx, "Имя пользователя получено из кэша", "user_id", userID)
 		return cachedUsername, nil
 	} else {
-		log.Printf("Имя пользователя с ID %d не найдено в кэше, получаем из базы данных", userID)
+		s.log.DebugContext(ctx, "Имя пользователя не найдено в кэше, получаем из базы данных", "user_id", userID)
 	}
 
-	username, err := dao.GetUserNameByID(ctx, userID, s.pool)
+	username, err := s.users.GetUserNameByID(ctx, userID)
 	if err != nil {
 		return "", fmt.Errorf("ошибка получения имени пользователя с ID %d: %w", userID, err)
 	}
 
 	err = s.cache.SetString(ctx, cacheKey, username, 10*time.Minute)
 	if err != nil {
-		log.Printf("Ошибка сохранения имени пользователя в кэш: %v", err)
+		s.log.WarnContext(ctx, "Ошибка сохранения имени пользователя в кэш", "user_id", userID, "error", err)
 	}
 
 	return username, nil
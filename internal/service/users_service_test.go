@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"TODO/internal/dao"
+	"TODO/internal/events"
+)
+
+func TestUserServiceDeleteWithTasks(t *testing.T) {
+	userService, taskService, _ := newMemoryServices(t, false)
+	ctx := context.Background()
+
+	user, err := userService.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := taskService.CreateTask(ctx, user.ID, "задача", ""); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	if err := userService.DeleteUser(ctx, user.ID); !errors.Is(err, dao.ErrUserHasTasks) {
+		t.Fatalf("DeleteUser без каскада: ошибка %v, ожидалась ErrUserHasTasks", err)
+	}
+	if _, err := userService.GetUserByID(ctx, user.ID); err != nil {
+		t.Fatalf("GetUserByID после отклоненного удаления: %v", err)
+	}
+}
+
+func TestUserServiceCascadeEvents(t *testing.T) {
+	userService, taskService, sub := newMemoryServices(t, true)
+	ctx := context.Background()
+
+	user, err := userService.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	other, err := userService.CreateUser(ctx, "bob")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	taskIDs := make(map[int64]bool)
+	for _, title := range []string{"первая", "вторая"} {
+		task, err := taskService.CreateTask(ctx, user.ID, title, "")
+		if err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+		taskIDs[task.ID] = true
+	}
+	if _, err := taskService.CreateTask(ctx, other.ID, "чужая", ""); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	receivedEvents(sub)
+
+	if err := userService.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	assertTaskEvents(t, receivedEvents(sub), events.OperationDeleteTask, user.ID, taskIDs)
+
+	for taskID := range taskIDs {
+		if _, err := taskService.GetTask(ctx, taskID); !errors.Is(err, dao.ErrNotFound) {
+			t.Fatalf("GetTask задачи удаленного пользователя: ошибка %v, ожидалась ErrNotFound", err)
+		}
+	}
+
+	restored, err := userService.RestoreUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("RestoreUser: %v", err)
+	}
+	if restored.Username != "alice" {
+		t.Fatalf("RestoreUser вернул %+v", restored)
+	}
+	assertTaskEvents(t, receivedEvents(sub), events.OperationRestoreTask, user.ID, taskIDs)
+}
+
+// assertTaskEvents проверяет, что получено по одному событию operation для каждой задачи из taskIDs
+func assertTaskEvents(t *testing.T, got []events.TaskEvent, operation string, userID int64, taskIDs map[int64]bool) {
+	t.Helper()
+
+	if len(got) != len(taskIDs) {
+		t.Fatalf("получено %d событий, ожидалось %d: %+v", len(got), len(taskIDs), got)
+	}
+	for _, event := range got {
+		if event.Operation != operation || event.UserID != userID || !taskIDs[event.TaskID] {
+			t.Errorf("неожиданное событие %+v, ожидалась операция %s над задачами пользователя %d", event, operation, userID)
+		}
+	}
+}
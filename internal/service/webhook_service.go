@@ -0,0 +1,133 @@
+package service
+
+import (
+	"TODO/internal/dao"
+	"TODO/internal/model"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"TODO/internal/tracing"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultDeliveriesLimit количество записей журнала доставок по умолчанию
+const defaultDeliveriesLimit = 50
+
+// WebhookService управляет подписками на события задач
+type WebhookService struct {
+	pool   *pgxpool.Pool
+	tracer trace.Tracer
+}
+
+// NewWebhookService создает новый сервис подписок
+func NewWebhookService(dbPool *pgxpool.Pool) *WebhookService {
+	return &WebhookService{
+		pool:   dbPool,
+		tracer: tracing.GetTracer(),
+	}
+}
+
+// CreateWebhook создает подписку. Если секрет не задан, он генерируется.
+func (s *WebhookService) CreateWebhook(ctx context.Context, webhook model.Webhook) (*model.Webhook, error) {
+	ctx, span := s.tracer.Start(ctx, "CreateWebhook")
+	defer span.End()
+
+	if webhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = secret
+	}
+
+	created, err := dao.CreateWebhook(ctx, webhook, s.pool)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания подписки: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetWebhook получает подписку по ID
+func (s *WebhookService) GetWebhook(ctx context.Context, webhookID int64) (*model.Webhook, error) {
+	ctx, span := s.tracer.Start(ctx, "GetWebhook")
+	defer span.End()
+
+	webhook, err := dao.GetWebhookByID(ctx, webhookID, s.pool)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения подписки с ID %d: %w", webhookID, err)
+	}
+
+	return webhook, nil
+}
+
+// GetAllWebhooks получает все подписки
+func (s *WebhookService) GetAllWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	ctx, span := s.tracer.Start(ctx, "GetAllWebhooks")
+	defer span.End()
+
+	webhooks, err := dao.GetAllWebhooks(ctx, s.pool)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения подписок: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook обновляет подписку
+func (s *WebhookService) UpdateWebhook(ctx context.Context, webhook model.Webhook) (*model.Webhook, error) {
+	ctx, span := s.tracer.Start(ctx, "UpdateWebhook")
+	defer span.End()
+
+	updated, err := dao.UpdateWebhook(ctx, webhook, s.pool)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обновления подписки с ID %d: %w", webhook.ID, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteWebhook удаляет подписку
+func (s *WebhookService) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	ctx, span := s.tracer.Start(ctx, "DeleteWebhook")
+	defer span.End()
+
+	if err := dao.DeleteWebhook(ctx, webhookID, s.pool); err != nil {
+		return fmt.Errorf("ошибка удаления подписки с ID %d: %w", webhookID, err)
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries получает журнал доставок подписки
+func (s *WebhookService) GetWebhookDeliveries(ctx context.Context, webhookID int64, limit, offset int) ([]model.WebhookDelivery, error) {
+	ctx, span := s.tracer.Start(ctx, "GetWebhookDeliveries")
+	defer span.End()
+
+	if _, err := dao.GetWebhookByID(ctx, webhookID, s.pool); err != nil {
+		return nil, fmt.Errorf("ошибка получения подписки с ID %d: %w", webhookID, err)
+	}
+
+	if limit <= 0 {
+		limit = defaultDeliveriesLimit
+	}
+
+	deliveries, err := dao.GetWebhookDeliveries(ctx, webhookID, limit, offset, s.pool)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения доставок подписки с ID %d: %w", webhookID, err)
+	}
+
+	return deliveries, nil
+}
+
+// generateSecret создает случайный секрет подписи
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("ошибка генерации секрета подписки: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
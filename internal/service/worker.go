@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"TODO/internal/dao"
+	"TODO/internal/pool"
+)
+
+// submit выполняет fn в общем worker pool. Внутри единицы работы fn выполняется сразу в текущей горутине:
+// транзакция удерживает соединение с базой, и ожидание воркера, занятого запросом, который ждет
+// это же соединение (у SQLite оно единственное), привело бы к взаимной блокировке.
+func submit(ctx context.Context, wp *pool.WorkerPool, fn func()) {
+	if dao.InTx(ctx) {
+		fn()
+		return
+	}
+	wp.SubmitTask(fn)
+}
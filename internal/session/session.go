@@ -0,0 +1,21 @@
+// Package session определяет сеанс вызывающей стороны, к которому DAO привязывает
+// чтение собственных записей: после записи чтения сеанса какое-то время идут в основную базу.
+package session
+
+import "context"
+
+type sessionKey struct{}
+
+// NewContext сохраняет ключ сеанса в контексте
+func NewContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, key)
+}
+
+// FromContext возвращает ключ сеанса из контекста или пустую строку
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(sessionKey{}).(string)
+	return key
+}
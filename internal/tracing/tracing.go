@@ -2,10 +2,12 @@ package tracing
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"os"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/semconv/v1.4.0"
@@ -18,7 +20,8 @@ var tracer trace.Tracer
 func InitTracer(serviceName, endpoint string) func(context.Context) error {
 	exp, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpoint(endpoint), otlptracehttp.WithInsecure())
 	if err != nil {
-		log.Fatalf("Ошибка при создании OTLP экспортера: %v", err)
+		slog.Error("Ошибка при создании OTLP экспортера", "endpoint", endpoint, "error", err)
+		os.Exit(1)
 	}
 
 	tp := sdktrace.NewTracerProvider(
@@ -27,12 +30,17 @@ func InitTracer(serviceName, endpoint string) func(context.Context) error {
 	)
 
 	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 	tracer = otel.Tracer(serviceName)
 
 	return tp.Shutdown
 }
 
-// GetTracer возвращает Tracer для использования в других частях приложения
+// GetTracer возвращает Tracer для использования в других частях приложения.
+// До InitTracer возвращается трейсер глобального провайдера, который не записывает спаны.
 func GetTracer() trace.Tracer {
+	if tracer == nil {
+		return otel.Tracer("")
+	}
 	return tracer
 }
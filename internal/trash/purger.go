@@ -0,0 +1,59 @@
+// Package trash окончательно удаляет пользователей и задачи, пролежавшие в корзине дольше срока хранения.
+package trash
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"TODO/internal/service"
+)
+
+// Purger периодически очищает корзину
+type Purger struct {
+	tasks     *service.TaskService
+	users     *service.UserService
+	retention time.Duration
+	log       *slog.Logger
+}
+
+// NewPurger создает очистку корзины, которая удаляет записи старше retention
+func NewPurger(tasks *service.TaskService, users *service.UserService, retention time.Duration, log *slog.Logger) *Purger {
+	return &Purger{tasks: tasks, users: users, retention: retention, log: log}
+}
+
+// Run очищает корзину сразу и затем каждые interval до отмены ctx
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	p.Purge(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Purge(ctx)
+		}
+	}
+}
+
+// Purge выполняет одну очистку корзины. Сначала удаляются задачи, затем пользователи
+// вместе с оставшимися задачами. Ошибки записываются в журнал, очистка повторится при следующем запуске.
+func (p *Purger) Purge(ctx context.Context) {
+	tasks, err := p.tasks.PurgeTasks(ctx, p.retention)
+	if err != nil {
+		p.log.ErrorContext(ctx, "Ошибка очистки корзины задач", "error", err)
+		return
+	}
+
+	users, err := p.users.PurgeUsers(ctx, p.retention)
+	if err != nil {
+		p.log.ErrorContext(ctx, "Ошибка очистки корзины пользователей", "error", err)
+		return
+	}
+
+	if tasks > 0 || users > 0 {
+		p.log.InfoContext(ctx, "Корзина очищена", "tasks", tasks, "users", users, "retention", p.retention)
+	}
+}
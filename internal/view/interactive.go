@@ -77,14 +77,18 @@ func handleSetWorkersCommand(args []string, workerPool *pool.WorkerPool) {
 // handleOtherCommands обрабатывает команды для пользователей и задач
 func handleOtherCommands(ctx context.Context, args []string, grpcWrapper *client.APIServiceClientWrapper, workerPool *pool.WorkerPool) {
 	switch args[0] {
-	case "create-user", "get-user", "get-users", "update-user", "delete-user":
+	case "create-user", "get-user", "get-users", "update-user", "delete-user", "restore-user":
 		workerPool.SubmitTask(func() {
 			handleUserCommands(ctx, args, grpcWrapper, workerPool)
 		})
-	case "create-task", "get-task", "get-tasks", "update-task", "delete-task":
+	case "create-task", "get-task", "get-tasks", "update-task", "delete-task", "search-tasks", "restore-task", "task-history":
 		workerPool.SubmitTask(func() {
 			handleTaskCommands(ctx, args, grpcWrapper, workerPool)
 		})
+	case "get-trash":
+		workerPool.SubmitTask(func() {
+			handleTrashCommand(ctx, args, grpcWrapper)
+		})
 	default:
 		fmt.Println("Неизвестная команда. Введите 'help' для получения справки.")
 	}
@@ -99,12 +103,18 @@ func printHelp() {
 	fmt.Println("  get-users - Получить список всех пользователей")
 	fmt.Println("  update-user [userID] [username] - Обновить данные пользователя")
 	fmt.Println("  delete-user [userID] - Удалить пользователя")
+	fmt.Println("  restore-user [userID] - Восстановить пользователя из корзины")
 	fmt.Println("Задачи:")
 	fmt.Println("  create-task [userID] [title] [note] - Создать новую задачу")
 	fmt.Println("  get-task [taskID] - Получить задачу по ID")
 	fmt.Println("  get-tasks - Получить список всех задач")
 	fmt.Println("  update-task [taskID] [title] [note] [done] - Обновить задачу")
 	fmt.Println("  delete-task [taskID] - Удалить задачу")
+	fmt.Println("  search-tasks [query] [userID?] [limit?] [offset?] - Найти задачи по тексту")
+	fmt.Println("  restore-task [taskID] - Восстановить задачу из корзины")
+	fmt.Println("  task-history [taskID] - Показать историю изменений задачи")
+	fmt.Println("Корзина:")
+	fmt.Println("  get-trash [userID?] - Показать удаленных пользователей и задачи")
 	fmt.Println("Системные команды:")
 	fmt.Println("  set-workers [количество] - Изменить количество воркеров")
 	fmt.Println("  exit - Выйти из программы")
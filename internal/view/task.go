@@ -6,6 +6,7 @@ import (
 	"TODO/internal/pool"
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -28,13 +29,19 @@ func handleTaskCommands(ctx context.Context, args []string, grpcWrapper *client.
 		handleUpdateTaskCommand(ctx, args, grpcWrapper, workerPool)
 	case "delete-task":
 		handleDeleteTaskCommand(ctx, args, grpcWrapper, workerPool)
+	case "search-tasks":
+		handleSearchTasksCommand(ctx, args, grpcWrapper, workerPool)
+	case "restore-task":
+		handleRestoreTaskCommand(ctx, args, grpcWrapper, workerPool)
+	case "task-history":
+		handleTaskHistoryCommand(ctx, args, grpcWrapper, workerPool)
 	default:
 		fmt.Printf("Неизвестная команда для задач: %s\n", args[0])
 	}
 }
 
 func printTaskUsage() {
-	fmt.Println("Недостаточно аргументов. Доступные команды: create-task, get-task, get-tasks, update-task, delete-task")
+	fmt.Println("Недостаточно аргументов. Доступные команды: create-task, get-task, get-tasks, update-task, delete-task, search-tasks, restore-task, task-history")
 }
 
 func handleCreateTaskCommand(ctx context.Context, args []string, grpcWrapper *client.APIServiceClientWrapper, workerPool *pool.WorkerPool) {
@@ -87,6 +94,36 @@ func handleDeleteTaskCommand(ctx context.Context, args []string, grpcWrapper *cl
 	})
 }
 
+func handleSearchTasksCommand(ctx context.Context, args []string, grpcWrapper *client.APIServiceClientWrapper, workerPool *pool.WorkerPool) {
+	if len(args) < 2 || len(args) > 5 {
+		fmt.Println("Использование: search-tasks [query] [userID?] [limit?] [offset?]")
+		return
+	}
+	workerPool.SubmitTask(func() {
+		handleSearchTasks(ctx, args[1], args[2:], grpcWrapper)
+	})
+}
+
+func handleRestoreTaskCommand(ctx context.Context, args []string, grpcWrapper *client.APIServiceClientWrapper, workerPool *pool.WorkerPool) {
+	if len(args) != 2 {
+		fmt.Println("Использование: restore-task [taskID]")
+		return
+	}
+	workerPool.SubmitTask(func() {
+		handleRestoreTask(ctx, args[1], grpcWrapper)
+	})
+}
+
+func handleTaskHistoryCommand(ctx context.Context, args []string, grpcWrapper *client.APIServiceClientWrapper, workerPool *pool.WorkerPool) {
+	if len(args) != 2 {
+		fmt.Println("Использование: task-history [taskID]")
+		return
+	}
+	workerPool.SubmitTask(func() {
+		handleTaskHistory(ctx, args[1], grpcWrapper)
+	})
+}
+
 // handleCreateTask создает новую задачу
 func handleCreateTask(ctx context.Context, userIDStr, title, note string, grpcWrapper *client.APIServiceClientWrapper) {
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
@@ -122,8 +159,8 @@ func handleGetTask(ctx context.Context, taskIDStr string, grpcWrapper *client.AP
 		fmt.Printf("Ошибка получения задачи: %v\n", err)
 		return
 	}
-	fmt.Printf("Задача: ID=%d, UserID=%d, Title=%s, Note=%s, Done=%t, CreatedAt=%s\n",
-		resp.TaskId, resp.UserId, resp.Title, resp.Note, resp.Done, resp.CreatedAt)
+	fmt.Printf("Задача: ID=%d, UserID=%d, Title=%s, Note=%s, Done=%t, CreatedAt=%s, Version=%d\n",
+		resp.TaskId, resp.UserId, resp.Title, resp.Note, resp.Done, resp.CreatedAt, resp.Version)
 }
 
 // handleGetAllTasks получает список всех задач
@@ -188,3 +225,81 @@ func handleDeleteTask(ctx context.Context, taskIDStr string, grpcWrapper *client
 	}
 	fmt.Println("Задача успешно удалена.")
 }
+
+// handleSearchTasks ищет задачи по тексту. Необязательные аргументы: ID пользователя, лимит и смещение
+func handleSearchTasks(ctx context.Context, query string, optional []string, grpcWrapper *client.APIServiceClientWrapper) {
+	var numbers [3]int64
+	names := [3]string{"ID пользователя", "лимита", "смещения"}
+	for i, arg := range optional {
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			fmt.Printf("Ошибка преобразования %s: %v\n", names[i], err)
+			return
+		}
+		numbers[i] = n
+	}
+
+	req := &v1.SearchTasksRequest{
+		Query:  query,
+		UserId: numbers[0],
+		Limit:  int32(numbers[1]),
+		Offset: int32(numbers[2]),
+	}
+	resp, err := grpcWrapper.SearchTasks(ctx, req)
+	if err != nil {
+		fmt.Printf("Ошибка поиска задач: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Найдено задач: %d\n", resp.Total)
+	fmt.Printf("%-10s %-10s %-8s %-30s %-40s\n", "TaskID", "UserID", "Rank", "Title", "Note")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, result := range resp.Results {
+		fmt.Printf("%-10d %-10d %-8.4f %-30s %-40s\n",
+			result.Task.TaskId, result.Task.UserId, result.Rank, result.TitleHighlight, result.NoteHighlight)
+	}
+}
+
+// handleRestoreTask восстанавливает задачу из корзины
+func handleRestoreTask(ctx context.Context, taskIDStr string, grpcWrapper *client.APIServiceClientWrapper) {
+	taskID, err := strconv.ParseInt(taskIDStr, 10, 64)
+	if err != nil {
+		fmt.Printf("Ошибка преобразования ID задачи: %v\n", err)
+		return
+	}
+
+	resp, err := grpcWrapper.RestoreTask(ctx, &v1.RestoreTaskRequest{TaskId: taskID})
+	if err != nil {
+		fmt.Printf("Ошибка восстановления задачи: %v\n", err)
+		return
+	}
+	fmt.Printf("Задача восстановлена: ID=%d, UserID=%d, Title=%s, Version=%d\n",
+		resp.Task.TaskId, resp.Task.UserId, resp.Task.Title, resp.Task.Version)
+}
+
+// handleTaskHistory выводит историю изменений задачи
+func handleTaskHistory(ctx context.Context, taskIDStr string, grpcWrapper *client.APIServiceClientWrapper) {
+	taskID, err := strconv.ParseInt(taskIDStr, 10, 64)
+	if err != nil {
+		fmt.Printf("Ошибка преобразования ID задачи: %v\n", err)
+		return
+	}
+
+	resp, err := grpcWrapper.GetTaskHistory(ctx, &v1.GetTaskHistoryRequest{TaskId: taskID})
+	if err != nil {
+		fmt.Printf("Ошибка получения истории задачи: %v\n", err)
+		return
+	}
+
+	fmt.Printf("%-25s %-10s %-15s %-40s\n", "Time", "Operation", "Actor", "Changes")
+	fmt.Println(strings.Repeat("-", 90))
+	for _, event := range resp.Events {
+		changes := make([]string, 0, len(event.Diff.GetFields()))
+		for field, change := range event.Diff.GetFields() {
+			values := change.GetStructValue().GetFields()
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", field, values["old"].AsInterface(), values["new"].AsInterface()))
+		}
+		sort.Strings(changes)
+		fmt.Printf("%-25s %-10s %-15s %-40s\n", event.CreatedAt, event.Operation, event.Actor, strings.Join(changes, "; "))
+	}
+}
@@ -0,0 +1,48 @@
+package view
+
+import (
+	v1 "TODO/internal/api/v1"
+	"TODO/internal/client"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// handleTrashCommand показывает содержимое корзины, целиком или для одного пользователя
+func handleTrashCommand(ctx context.Context, args []string, grpcWrapper *client.APIServiceClientWrapper) {
+	if len(args) > 2 {
+		fmt.Println("Использование: get-trash [userID?]")
+		return
+	}
+
+	req := &v1.ListTrashRequest{}
+	if len(args) == 2 {
+		userID, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			fmt.Printf("Ошибка преобразования ID пользователя: %v\n", err)
+			return
+		}
+		req.UserId = userID
+	}
+
+	resp, err := grpcWrapper.ListTrash(ctx, req)
+	if err != nil {
+		fmt.Printf("Ошибка получения корзины: %v\n", err)
+		return
+	}
+
+	fmt.Println("Пользователи:")
+	fmt.Printf("%-10s %-20s %-25s\n", "UserID", "Username", "DeletedAt")
+	fmt.Println(strings.Repeat("-", 55))
+	for _, user := range resp.Users {
+		fmt.Printf("%-10d %-20s %-25s\n", user.UserId, user.Username, user.DeletedAt)
+	}
+
+	fmt.Println("Задачи:")
+	fmt.Printf("%-10s %-10s %-20s %-30s %-25s\n", "TaskID", "UserID", "Title", "Note", "DeletedAt")
+	fmt.Println(strings.Repeat("-", 95))
+	for _, task := range resp.Tasks {
+		fmt.Printf("%-10d %-10d %-20s %-30s %-25s\n", task.TaskId, task.UserId, task.Title, task.Note, task.DeletedAt)
+	}
+}
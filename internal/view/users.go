@@ -48,6 +48,14 @@ func handleUserCommands(ctx context.Context, args []string, grpcWrapper *client.
 		workerPool.SubmitTask(func() {
 			handleDeleteUser(ctx, args[1], grpcWrapper)
 		})
+	case "restore-user":
+		if len(args) != 2 {
+			fmt.Println("Использование: restore-user [userID]")
+			return
+		}
+		workerPool.SubmitTask(func() {
+			handleRestoreUser(ctx, args[1], grpcWrapper)
+		})
 	default:
 		fmt.Println("Неизвестная команда для пользователя.")
 	}
@@ -123,3 +131,19 @@ func handleDeleteUser(ctx context.Context, userIDStr string, grpcWrapper *client
 		fmt.Println("Пользователь успешно удален.")
 	}
 }
+
+// handleRestoreUser восстанавливает пользователя из корзины
+func handleRestoreUser(ctx context.Context, userIDStr string, grpcWrapper *client.APIServiceClientWrapper) {
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		fmt.Printf("Ошибка преобразования ID пользователя: %v\n", err)
+		return
+	}
+
+	resp, err := grpcWrapper.RestoreUser(ctx, &v1.RestoreUserRequest{UserId: userID})
+	if err != nil {
+		fmt.Printf("Ошибка восстановления пользователя: %v\n", err)
+		return
+	}
+	fmt.Printf("Пользователь восстановлен: ID=%d, Username=%s, Version=%d\n", resp.User.UserId, resp.User.Username, resp.User.Version)
+}
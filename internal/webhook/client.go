@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// sharedAddressSpace адреса CGNAT (RFC 6598), не входящие в netip.Addr.IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// newHTTPClient создает клиент доставки уведомлений. Адрес подписки задает пользователь API,
+// поэтому клиент не должен давать доступ к внутренним сервисам (SSRF): он подключается только
+// к публичным адресам, проверяя адрес после разрешения имени, не использует прокси из окружения
+// и не следует перенаправлениям — ответ 3xx считается неудачной доставкой.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: publicAddressOnly}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicAddressOnly запрещает подключения к loopback, частным сетям, link-local адресам
+// (в том числе 169.254.169.254 метаданных облака), multicast и неуказанным адресам
+func publicAddressOnly(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("некорректный адрес подписчика %q: %w", address, err)
+	}
+
+	ip := addrPort.Addr().Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("доставка на внутренний адрес %s запрещена", ip)
+	}
+	return nil
+}
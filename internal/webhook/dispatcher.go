@@ -0,0 +1,288 @@
+// Package webhook доставляет события задач внешним подписчикам.
+//
+// Каждое уведомление — POST запрос с JSON телом и заголовками:
+//
+//	X-Webhook-Id         идентификатор события, общий для всех попыток доставки
+//	X-Webhook-Event      тип события (task.created, task.updated, task.deleted, task.restored)
+//	X-Webhook-Attempt    номер попытки, начиная с 1
+//	X-Webhook-Signature  t=<unix время>,v1=<hex HMAC-SHA256 секрета от "<unix время>.<тело>">
+//
+// Получатель проверяет подпись и отклоняет запросы со слишком старым временем.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"TODO/internal/dao"
+	"TODO/internal/events"
+	"TODO/internal/model"
+)
+
+// eventBuffer размер буфера подписки диспетчера на шину событий
+const eventBuffer = 1024
+
+// Config содержит параметры доставки уведомлений
+type Config struct {
+	Workers        int           // Количество параллельных доставок
+	MaxAttempts    int           // Максимальное число попыток доставки одного события
+	InitialBackoff time.Duration // Пауза перед второй попыткой, далее удваивается
+	MaxBackoff     time.Duration // Максимальная пауза между попытками
+	Timeout        time.Duration // Таймаут одного HTTP запроса
+	MaxFailures    int           // Число неудачно доставленных событий подряд, после которого подписка отключается
+}
+
+// Payload тело уведомления
+type Payload struct {
+	ID        string            `json:"id"`        // Идентификатор события
+	Type      string            `json:"type"`      // Тип события
+	Timestamp time.Time         `json:"timestamp"` // Время изменения задачи
+	Data      *events.TaskEvent `json:"data"`      // Событие задачи
+}
+
+// delivery задание на доставку события одному подписчику
+type delivery struct {
+	webhook model.Webhook
+	payload Payload
+	body    []byte
+}
+
+// store хранит подписки, журнал доставок и счетчики неудач
+type store interface {
+	GetActiveWebhooksForEvent(ctx context.Context, eventType string, userID int64) ([]model.Webhook, error)
+	CreateWebhookDelivery(ctx context.Context, record model.WebhookDelivery) error
+	RecordWebhookResult(ctx context.Context, webhookID int64, success bool, maxFailures int) (bool, error)
+}
+
+// pgStore хранилище подписок в PostgreSQL
+type pgStore struct {
+	pool *pgxpool.Pool
+}
+
+func (s pgStore) GetActiveWebhooksForEvent(ctx context.Context, eventType string, userID int64) ([]model.Webhook, error) {
+	return dao.GetActiveWebhooksForEvent(ctx, eventType, userID, s.pool)
+}
+
+func (s pgStore) CreateWebhookDelivery(ctx context.Context, record model.WebhookDelivery) error {
+	return dao.CreateWebhookDelivery(ctx, record, s.pool)
+}
+
+func (s pgStore) RecordWebhookResult(ctx context.Context, webhookID int64, success bool, maxFailures int) (bool, error) {
+	return dao.RecordWebhookResult(ctx, webhookID, success, maxFailures, s.pool)
+}
+
+// Dispatcher получает события из шины и рассылает их подписчикам
+type Dispatcher struct {
+	store  store
+	bus    *events.Bus
+	cfg    Config
+	client *http.Client
+	jobs   chan delivery
+	after  func(time.Duration) <-chan time.Time // Ожидание перед повторной попыткой, подменяется в тестах
+	log    *slog.Logger
+}
+
+// NewDispatcher создает диспетчер уведомлений
+func NewDispatcher(dbPool *pgxpool.Pool, bus *events.Bus, cfg Config, log *slog.Logger) *Dispatcher {
+	return newDispatcher(pgStore{pool: dbPool}, bus, cfg, log)
+}
+
+// newDispatcher создает диспетчер уведомлений с хранилищем подписок store
+func newDispatcher(store store, bus *events.Bus, cfg Config, log *slog.Logger) *Dispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	return &Dispatcher{
+		store:  store,
+		bus:    bus,
+		cfg:    cfg,
+		client: newHTTPClient(cfg.Timeout),
+		jobs:   make(chan delivery, cfg.Workers*16),
+		after:  time.After,
+		log:    log,
+	}
+}
+
+// Run обрабатывает события до отмены контекста
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range d.jobs {
+				d.deliver(ctx, job)
+			}
+		}()
+	}
+
+	d.consume(ctx)
+	close(d.jobs)
+	wg.Wait()
+}
+
+// consume читает события из шины. Если диспетчер не успел прочитать события
+// и шина отключила подписку, он подписывается заново.
+func (d *Dispatcher) consume(ctx context.Context) {
+	for {
+		sub := d.bus.Subscribe(eventBuffer, nil)
+		for {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+				return
+			case event, ok := <-sub.Events():
+				if !ok {
+					if sub.Overflowed() {
+						d.log.Warn("Диспетчер webhooks не успевает обрабатывать события, часть уведомлений потеряна")
+					}
+					break
+				}
+				d.dispatch(ctx, event)
+				continue
+			}
+			break
+		}
+	}
+}
+
+// dispatch ставит в очередь доставку события всем подходящим подписчикам
+func (d *Dispatcher) dispatch(ctx context.Context, event events.TaskEvent) {
+	webhooks, err := d.store.GetActiveWebhooksForEvent(ctx, event.Type(), event.UserID)
+	if err != nil {
+		d.log.ErrorContext(ctx, "Ошибка получения подписок для события", "event_type", event.Type(), "error", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	eventID, err := newEventID()
+	if err != nil {
+		d.log.ErrorContext(ctx, "Ошибка генерации ID события", "error", err)
+		return
+	}
+	payload := Payload{ID: eventID, Type: event.Type(), Timestamp: event.Timestamp, Data: &event}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.log.ErrorContext(ctx, "Ошибка сериализации события", "event_id", eventID, "error", err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		select {
+		case d.jobs <- delivery{webhook: webhook, payload: payload, body: body}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver отправляет уведомление с повторами и экспоненциальной паузой,
+// записывает каждую попытку в журнал и обновляет счетчик неудач подписки
+func (d *Dispatcher) deliver(ctx context.Context, job delivery) {
+	backoff := d.cfg.InitialBackoff
+	success := false
+
+	for attempt := 1; attempt <= d.cfg.MaxAttempts && !success; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-d.after(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff = min(backoff*2, d.cfg.MaxBackoff)
+		}
+
+		record := d.send(ctx, job, attempt)
+		success = record.Success
+		if err := d.store.CreateWebhookDelivery(ctx, record); err != nil {
+			d.log.ErrorContext(ctx, "Ошибка записи доставки", "webhook_id", job.webhook.ID, "error", err)
+		}
+	}
+
+	disabled, err := d.store.RecordWebhookResult(ctx, job.webhook.ID, success, d.cfg.MaxFailures)
+	if err != nil {
+		d.log.ErrorContext(ctx, "Ошибка обновления состояния подписки", "webhook_id", job.webhook.ID, "error", err)
+		return
+	}
+	if disabled {
+		d.log.WarnContext(ctx, "Подписка отключена после неудачных доставок подряд", "webhook_id", job.webhook.ID, "failures", d.cfg.MaxFailures)
+	}
+}
+
+// send выполняет одну попытку доставки
+func (d *Dispatcher) send(ctx context.Context, job delivery, attempt int) model.WebhookDelivery {
+	record := model.WebhookDelivery{
+		WebhookID: job.webhook.ID,
+		EventID:   job.payload.ID,
+		EventType: job.payload.Type,
+		Attempt:   attempt,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.webhook.URL, bytes.NewReader(job.body))
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TODO-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", job.payload.ID)
+	req.Header.Set("X-Webhook-Event", job.payload.Type)
+	req.Header.Set("X-Webhook-Attempt", strconv.Itoa(attempt))
+	req.Header.Set("X-Webhook-Signature", fmt.Sprintf("t=%s,v1=%s", timestamp, Sign(job.webhook.Secret, timestamp, job.body)))
+
+	started := time.Now()
+	resp, err := d.client.Do(req)
+	record.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	record.StatusCode = resp.StatusCode
+	record.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !record.Success {
+		record.Error = resp.Status
+	}
+	return record
+}
+
+// Sign вычисляет подпись тела уведомления: hex HMAC-SHA256 от "<timestamp>.<body>"
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newEventID создает случайный идентификатор события
+func newEventID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,364 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"TODO/internal/events"
+	"TODO/internal/model"
+)
+
+// memoryStore хранилище подписок в памяти. Счетчик неудач ведется так же, как в dao.RecordWebhookResult.
+type memoryStore struct {
+	mu         sync.Mutex
+	webhooks   map[int64]*model.Webhook
+	deliveries []model.WebhookDelivery
+	results    []bool
+}
+
+func newMemoryStore(webhooks ...model.Webhook) *memoryStore {
+	s := &memoryStore{webhooks: make(map[int64]*model.Webhook)}
+	for _, webhook := range webhooks {
+		webhook := webhook
+		s.webhooks[webhook.ID] = &webhook
+	}
+	return s
+}
+
+func (s *memoryStore) GetActiveWebhooksForEvent(_ context.Context, _ string, _ int64) ([]model.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var webhooks []model.Webhook
+	for _, webhook := range s.webhooks {
+		if webhook.Active {
+			webhooks = append(webhooks, *webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (s *memoryStore) CreateWebhookDelivery(_ context.Context, record model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries = append(s.deliveries, record)
+	return nil
+}
+
+func (s *memoryStore) RecordWebhookResult(_ context.Context, webhookID int64, success bool, maxFailures int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.results = append(s.results, success)
+	webhook := s.webhooks[webhookID]
+	if success {
+		webhook.FailureCount = 0
+		return false, nil
+	}
+	webhook.FailureCount++
+	if maxFailures > 0 && webhook.FailureCount >= maxFailures && webhook.Active {
+		webhook.Active = false
+		return true, nil
+	}
+	return false, nil
+}
+
+// receivedRequest запрос, полученный тестовым подписчиком
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver запускает тестового подписчика, который отвечает кодами statuses по очереди,
+// а после их окончания — последним кодом
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, func() []receivedRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []receivedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		requests = append(requests, receivedRequest{header: r.Header.Clone(), body: body})
+		status := statuses[min(len(requests), len(statuses))-1]
+		mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedRequest(nil), requests...)
+	}
+}
+
+// newTestDispatcher создает диспетчер, который не ждет между попытками, а записывает паузы
+func newTestDispatcher(store store, cfg Config) (*Dispatcher, func() []time.Duration) {
+	d := newDispatcher(store, events.NewBus(), cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	// Тестовые подписчики слушают loopback, поэтому проверка адреса подключения отключается
+	d.client.Transport.(*http.Transport).DialContext = (&net.Dialer{Timeout: cfg.Timeout}).DialContext
+
+	var mu sync.Mutex
+	var backoffs []time.Duration
+	d.after = func(backoff time.Duration) <-chan time.Time {
+		mu.Lock()
+		backoffs = append(backoffs, backoff)
+		mu.Unlock()
+
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+	return d, func() []time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]time.Duration(nil), backoffs...)
+	}
+}
+
+func testDelivery(webhook model.Webhook) delivery {
+	event := events.TaskEvent{Operation: events.OperationCreateTask, TaskID: 7, UserID: 3, Timestamp: time.Now()}
+	return delivery{
+		webhook: webhook,
+		payload: Payload{ID: "event-1", Type: event.Type(), Timestamp: event.Timestamp, Data: &event},
+		body:    []byte(`{"id":"event-1"}`),
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("secret", "1700000000", []byte(`{"id":"1"}`))
+	want := "086f6aff7bd084c98679825129c5a64dbad88c760016d6d2c0fb123f27951d54"
+	if got != want {
+		t.Errorf("Sign = %s, ожидалось %s", got, want)
+	}
+}
+
+func TestDeliverHeadersAndSignature(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusOK)
+	webhook := model.Webhook{ID: 1, URL: server.URL, Secret: "s3cr3t", Active: true}
+	store := newMemoryStore(webhook)
+	d, _ := newTestDispatcher(store, Config{MaxAttempts: 3, Timeout: time.Second})
+
+	started := time.Now().Unix()
+	job := testDelivery(webhook)
+	d.deliver(context.Background(), job)
+
+	received := requests()
+	if len(received) != 1 {
+		t.Fatalf("получено %d запросов, ожидался 1", len(received))
+	}
+	header := received[0].header
+
+	for name, want := range map[string]string{
+		"Content-Type":      "application/json",
+		"X-Webhook-Id":      "event-1",
+		"X-Webhook-Event":   events.TypeTaskCreated,
+		"X-Webhook-Attempt": "1",
+	} {
+		if got := header.Get(name); got != want {
+			t.Errorf("заголовок %s = %q, ожидалось %q", name, got, want)
+		}
+	}
+	if string(received[0].body) != string(job.body) {
+		t.Errorf("тело запроса %s, ожидалось %s", received[0].body, job.body)
+	}
+
+	// Проверка подписи так, как ее выполняет получатель
+	timestamp, signature, ok := strings.Cut(header.Get("X-Webhook-Signature"), ",")
+	if !ok || !strings.HasPrefix(timestamp, "t=") || !strings.HasPrefix(signature, "v1=") {
+		t.Fatalf("X-Webhook-Signature = %q, ожидался формат t=<время>,v1=<подпись>", header.Get("X-Webhook-Signature"))
+	}
+	timestamp = strings.TrimPrefix(timestamp, "t=")
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || unix < started || unix > time.Now().Unix() {
+		t.Errorf("время подписи %q вне интервала запроса", timestamp)
+	}
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(received[0].body)
+	if want := hex.EncodeToString(mac.Sum(nil)); strings.TrimPrefix(signature, "v1=") != want {
+		t.Errorf("подпись %s, ожидалась v1=%s", signature, want)
+	}
+
+	if len(store.deliveries) != 1 || !store.deliveries[0].Success || store.deliveries[0].StatusCode != http.StatusOK {
+		t.Errorf("журнал доставок %+v, ожидалась одна успешная доставка", store.deliveries)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusNoContent)
+	webhook := model.Webhook{ID: 1, URL: server.URL, Secret: "secret", Active: true}
+	store := newMemoryStore(webhook)
+	d, backoffs := newTestDispatcher(store, Config{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Minute, Timeout: time.Second})
+
+	d.deliver(context.Background(), testDelivery(webhook))
+
+	received := requests()
+	if len(received) != 3 {
+		t.Fatalf("получено %d запросов, ожидалось 3", len(received))
+	}
+	for i, request := range received {
+		if got, want := request.header.Get("X-Webhook-Attempt"), strconv.Itoa(i+1); got != want {
+			t.Errorf("попытка %d: X-Webhook-Attempt = %s", i+1, got)
+		}
+		if got := request.header.Get("X-Webhook-Id"); got != "event-1" {
+			t.Errorf("попытка %d: X-Webhook-Id = %s, ожидался общий для всех попыток event-1", i+1, got)
+		}
+	}
+
+	wantStatuses := []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusNoContent}
+	if len(store.deliveries) != len(wantStatuses) {
+		t.Fatalf("в журнале %d доставок, ожидалось %d", len(store.deliveries), len(wantStatuses))
+	}
+	for i, record := range store.deliveries {
+		if record.Attempt != i+1 || record.StatusCode != wantStatuses[i] || record.Success != (i == 2) {
+			t.Errorf("доставка %d: %+v", i+1, record)
+		}
+	}
+
+	if got, want := backoffs(), []time.Duration{time.Second, 2 * time.Second}; !slices.Equal(got, want) {
+		t.Errorf("паузы %v, ожидалось %v", got, want)
+	}
+	if len(store.results) != 1 || !store.results[0] {
+		t.Errorf("результаты доставки %v, ожидался один успешный", store.results)
+	}
+}
+
+func TestDeliverBackoffCap(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusInternalServerError)
+	webhook := model.Webhook{ID: 1, URL: server.URL, Secret: "secret", Active: true}
+	store := newMemoryStore(webhook)
+	d, backoffs := newTestDispatcher(store, Config{MaxAttempts: 6, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Timeout: time.Second})
+
+	d.deliver(context.Background(), testDelivery(webhook))
+
+	if got := len(requests()); got != 6 {
+		t.Fatalf("получено %d запросов, ожидалось MaxAttempts = 6", got)
+	}
+	if got, want := backoffs(), []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second, 3 * time.Second}; !slices.Equal(got, want) {
+		t.Errorf("паузы %v, ожидалось %v", got, want)
+	}
+	if len(store.results) != 1 || store.results[0] {
+		t.Errorf("результаты доставки %v, ожидался один неуспешный", store.results)
+	}
+}
+
+func TestDeliverDisablesAfterMaxFailures(t *testing.T) {
+	status := http.StatusInternalServerError
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	setStatus := func(code int) {
+		mu.Lock()
+		status = code
+		mu.Unlock()
+	}
+
+	webhook := model.Webhook{ID: 1, URL: server.URL, Secret: "secret", Active: true}
+	store := newMemoryStore(webhook)
+	d, _ := newTestDispatcher(store, Config{MaxAttempts: 2, MaxFailures: 2, Timeout: time.Second})
+	ctx := context.Background()
+
+	// Успешная доставка сбрасывает счетчик неудач подряд
+	d.deliver(ctx, testDelivery(webhook))
+	setStatus(http.StatusOK)
+	d.deliver(ctx, testDelivery(webhook))
+	setStatus(http.StatusInternalServerError)
+	d.deliver(ctx, testDelivery(webhook))
+	if !store.webhooks[1].Active {
+		t.Fatal("подписка отключена, хотя неудачи не шли подряд")
+	}
+
+	d.deliver(ctx, testDelivery(webhook))
+	if store.webhooks[1].Active {
+		t.Fatalf("подписка активна после %d неудачных доставок подряд", 2)
+	}
+	if want := []bool{false, true, false, false}; !slices.Equal(store.results, want) {
+		t.Errorf("результаты доставок %v, ожидалось %v", store.results, want)
+	}
+
+	// Отключенная подписка больше не получает события
+	d.dispatch(ctx, events.TaskEvent{Operation: events.OperationUpdateTask, TaskID: 7, UserID: 3, Timestamp: time.Now()})
+	if queued := len(d.jobs); queued != 0 {
+		t.Errorf("в очереди %d доставок для отключенной подписки", queued)
+	}
+}
+
+func TestDeliverRejectsInternalAddresses(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusOK)
+	webhook := model.Webhook{ID: 1, URL: server.URL, Secret: "secret", Active: true}
+	store := newMemoryStore(webhook)
+	d := newDispatcher(store, events.NewBus(), Config{MaxAttempts: 1, Timeout: time.Second}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	d.deliver(context.Background(), testDelivery(webhook))
+
+	if got := len(requests()); got != 0 {
+		t.Fatalf("подписчик на loopback получил %d запросов", got)
+	}
+	if len(store.deliveries) != 1 || store.deliveries[0].Success || !strings.Contains(store.deliveries[0].Error, "внутренний адрес") {
+		t.Errorf("журнал доставок %+v, ожидалась неудачная доставка с ошибкой внутреннего адреса", store.deliveries)
+	}
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	target, targetRequests := newReceiver(t, http.StatusOK)
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirect.Close)
+
+	webhook := model.Webhook{ID: 1, URL: redirect.URL, Secret: "secret", Active: true}
+	store := newMemoryStore(webhook)
+	d, _ := newTestDispatcher(store, Config{MaxAttempts: 1, Timeout: time.Second})
+
+	d.deliver(context.Background(), testDelivery(webhook))
+
+	if got := len(targetRequests()); got != 0 {
+		t.Fatalf("адрес перенаправления получил %d запросов", got)
+	}
+	if len(store.deliveries) != 1 || store.deliveries[0].Success || store.deliveries[0].StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("журнал доставок %+v, ожидалась неудачная доставка с кодом 307", store.deliveries)
+	}
+}
+
+func TestPublicAddressOnly(t *testing.T) {
+	for address, allowed := range map[string]bool{
+		"93.184.216.34:443":          true,
+		"[2606:2800:220:1::1]:443":   true,
+		"127.0.0.1:80":               false,
+		"[::1]:80":                   false,
+		"10.1.2.3:80":                false,
+		"172.16.0.1:80":              false,
+		"192.168.1.1:80":             false,
+		"169.254.169.254:80":         false,
+		"[fe80::1]:80":               false,
+		"[fd00::1]:80":               false,
+		"100.64.0.1:80":              false,
+		"0.0.0.0:80":                 false,
+		"[::ffff:127.0.0.1]:80":      false,
+		"[::ffff:93.184.216.34]:443": true,
+	} {
+		if err := publicAddressOnly("tcp", address, nil); (err == nil) != allowed {
+			t.Errorf("publicAddressOnly(%s) = %v, разрешение ожидалось %v", address, err, allowed)
+		}
+	}
+}